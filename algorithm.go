@@ -0,0 +1,116 @@
+package yinfft
+
+import (
+	"fmt"
+
+	"github.com/FreibergVlad/go-yinfft/mpm"
+	"github.com/FreibergVlad/go-yinfft/yin"
+)
+
+// Algorithm names a pitch detection algorithm available through NewDetector.
+type Algorithm string
+
+const (
+	AlgorithmYinFFT Algorithm = "yinfft" // The YinFFT algorithm implemented by PitchDetector.
+	AlgorithmYin    Algorithm = "yin"    // The classic time-domain YIN algorithm of the yin package.
+	AlgorithmMPM    Algorithm = "mpm"    // The McLeod Pitch Method of the mpm package.
+)
+
+// PitchAlgorithm is the interface shared by all pitch detection algorithms, allowing applications to select the
+// algorithm at runtime, see NewDetector. Implementations may modify the frame in place.
+type PitchAlgorithm interface {
+	Detect(frame []float64) (Result, error)
+}
+
+var (
+	_ PitchAlgorithm = (*PitchDetector)(nil)
+	_ PitchAlgorithm = yinAlgorithm{}
+	_ PitchAlgorithm = mpmAlgorithm{}
+)
+
+type (
+	// yinAlgorithm adapts the yin package to PitchAlgorithm.
+	yinAlgorithm struct {
+		detector *yin.PitchDetector
+	}
+	// mpmAlgorithm adapts the mpm package to PitchAlgorithm.
+	mpmAlgorithm struct {
+		detector *mpm.PitchDetector
+	}
+)
+
+// NewDetector creates a detector running the named algorithm with the given params. Algorithms use the params they
+// support: YIN and MPM use FrameSize, SampleRate, the frequency range and ShouldInterpolate, and YIN uses Tolerance as
+// its absolute threshold if it's below 1.
+func NewDetector(algorithm Algorithm, params Params) (PitchAlgorithm, error) {
+	switch algorithm {
+	case AlgorithmYinFFT:
+		return New(params)
+	case AlgorithmYin:
+		yinParams := yin.Params{
+			FrameSize:         params.FrameSize,
+			SampleRate:        params.SampleRate,
+			Threshold:         yin.DefaultParams.Threshold,
+			MinFrequency:      params.MinFrequency,
+			MaxFrequency:      params.MaxFrequency,
+			ShouldInterpolate: params.ShouldInterpolate,
+		}
+		if params.Tolerance > 0 && params.Tolerance < 1 {
+			yinParams.Threshold = params.Tolerance
+		}
+		detector, err := yin.New(yinParams)
+		if err != nil {
+			return nil, err
+		}
+		return yinAlgorithm{detector: detector}, nil
+	case AlgorithmMPM:
+		detector, err := mpm.New(mpm.Params{
+			FrameSize:         params.FrameSize,
+			SampleRate:        params.SampleRate,
+			Cutoff:            mpm.DefaultParams.Cutoff,
+			MinFrequency:      params.MinFrequency,
+			MaxFrequency:      params.MaxFrequency,
+			ShouldInterpolate: params.ShouldInterpolate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mpmAlgorithm{detector: detector}, nil
+	default:
+		return nil, fmt.Errorf(
+			"invalid algorithm: %s, must be one of [%s, %s, %s]", algorithm, AlgorithmYinFFT, AlgorithmYin, AlgorithmMPM,
+		)
+	}
+}
+
+// Detect detects the fundamental frequency of the frame like DetectFromFrame, also reporting its RMS level.
+func (pd *PitchDetector) Detect(frame []float64) (Result, error) {
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	return pd.detectResult(frame, scratch)
+}
+
+func (a yinAlgorithm) Detect(frame []float64) (Result, error) {
+	frequency, confidence, err := a.detector.DetectFromFrame(frame)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(frequency, confidence, a.detector.Params().SampleRate), nil
+}
+
+func (a mpmAlgorithm) Detect(frame []float64) (Result, error) {
+	frequency, confidence, err := a.detector.DetectFromFrame(frame)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(frequency, confidence, a.detector.Params().SampleRate), nil
+}
+
+// newResult creates the result of a single frame detection, deriving the period and voicing from the frequency.
+func newResult(frequency, confidence, sampleRate float64) Result {
+	result := Result{Frequency: frequency, Confidence: confidence, Voiced: frequency > 0}
+	if result.Voiced {
+		result.Tau = sampleRate / frequency
+	}
+	return result
+}
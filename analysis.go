@@ -0,0 +1,47 @@
+package yinfft
+
+import (
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// Analysis holds the intermediate buffers of a single detection, for comparing the algorithm against reference
+// implementations.
+type Analysis struct {
+	WindowedFrame    []float64 // Frame after sanitization, decimation and windowing, FrameSize/Decimation samples.
+	Spectrum         []float64 // Magnitude spectrum, FFTSize()/2+1 bins.
+	WeightedSpectrum []float64 // Weighted squared magnitude spectrum, FFTSize()/2+1 bins.
+	Yin              []float64 // Cumulative mean normalized difference function, FFTSize()/2+1 lags.
+	Frequency        float64   // Detected frequency in Hz.
+	Confidence       float64   // Detection confidence.
+}
+
+// Analyze detects the fundamental frequency of the frame like DetectFromFrame, additionally returning copies of all
+// intermediate buffers. The frame is modified in place.
+func (pd *PitchDetector) Analyze(frame []float64) (*Analysis, error) {
+	if err := pd.checkFrame(frame); err != nil {
+		return nil, err
+	}
+	frame = pd.decimate(frame)
+	spectrum := internal.PrepareSpectrum(frame, pd.window, pd.params.FFTSize(), pd.params.DenormalThreshold)
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	clear(scratch.sqrMag)
+	clear(scratch.yin)
+
+	frequency, confidence, err := pd.detect(spectrum, scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Analysis{
+		WindowedFrame:    slices.Clone(frame),
+		Spectrum:         spectrum,
+		WeightedSpectrum: slices.Clone(scratch.sqrMag[:len(spectrum)]),
+		Yin:              slices.Clone(scratch.yin),
+		Frequency:        frequency,
+		Confidence:       confidence,
+	}, nil
+}
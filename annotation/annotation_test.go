@@ -0,0 +1,230 @@
+package annotation_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/annotation"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+func TestMIREX(t *testing.T) {
+	t.Parallel()
+
+	input := "0.00\t0\n0.01\t-220.5\n0.02,440\n\n0.03 441.25\n"
+	want := []annotation.Frame{
+		{Time: 0, Frequency: 0, Voiced: false},
+		{Time: 0.01, Frequency: 220.5, Voiced: false},
+		{Time: 0.02, Frequency: 440, Voiced: true},
+		{Time: 0.03, Frequency: 441.25, Voiced: true},
+	}
+
+	frames, err := annotation.ReadMIREX(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("error reading annotation: %v", err)
+	}
+	if !slices.Equal(frames, want) {
+		t.Fatalf("incorrect frames, got %v, want %v", frames, want)
+	}
+
+	var buffer bytes.Buffer
+	if err := annotation.WriteMIREX(&buffer, frames); err != nil {
+		t.Fatalf("error writing annotation: %v", err)
+	}
+	roundTrip, err := annotation.ReadMIREX(&buffer)
+	if err != nil {
+		t.Fatalf("error reading written annotation: %v", err)
+	}
+	if !slices.Equal(roundTrip, want) {
+		t.Errorf("incorrect round trip frames, got %v, want %v", roundTrip, want)
+	}
+
+	if _, err := annotation.ReadMIREX(strings.NewReader("0.01\tabc\n")); err == nil {
+		t.Errorf("expected error for invalid frequency")
+	}
+}
+
+// contour is a short pitch track of 10 ms frames.
+var contour = []yinfft.Result{
+	{Time: 0, Frame: 0, Level: math.Inf(-1)},
+	{Time: 0.01, Frame: 1, Frequency: 220, Confidence: 0.95, Voiced: true, Level: -12.5},
+	{Time: 0.02, Frame: 2, Frequency: 221.5, Confidence: 0.9, Voiced: true, Level: -12},
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	if err := annotation.WriteCSV(&buffer, contour); err != nil {
+		t.Fatalf("error writing CSV: %v", err)
+	}
+	want := "time,frame,frequency,confidence,voiced,level\n" +
+		"0,0,0,0,false,-Inf\n" +
+		"0.01,1,220,0.95,true,-12.5\n" +
+		"0.02,2,221.5,0.9,true,-12\n"
+	if buffer.String() != want {
+		t.Errorf("incorrect CSV, got:\n%s\nwant:\n%s", buffer.String(), want)
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	if err := annotation.WriteJSONL(&buffer, contour); err != nil {
+		t.Fatalf("error writing JSON Lines: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	want := []string{
+		`{"time":0,"frame":0,"frequency":0,"confidence":0,"voiced":false,"level":null}`,
+		`{"time":0.01,"frame":1,"frequency":220,"confidence":0.95,"voiced":true,"level":-12.5}`,
+		`{"time":0.02,"frame":2,"frequency":221.5,"confidence":0.9,"voiced":true,"level":-12}`,
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("incorrect JSON Lines, got %q, want %q", lines, want)
+	}
+}
+
+func TestWritePraat(t *testing.T) {
+	t.Parallel()
+
+	opts := annotation.PraatOptions{FrameDuration: 0.04}
+	var pitchTier bytes.Buffer
+	if err := annotation.WritePitchTier(&pitchTier, contour, opts); err != nil {
+		t.Fatalf("error writing PitchTier: %v", err)
+	}
+	wantPitchTier := "File type = \"ooTextFile\"\nObject class = \"PitchTier\"\n\nxmin = 0 \nxmax = 0.06 \n" +
+		"points: size = 2 \npoints [1]:\n    number = 0.03 \n    value = 220 \n" +
+		"points [2]:\n    number = 0.04 \n    value = 221.5 \n"
+	if pitchTier.String() != wantPitchTier {
+		t.Errorf("incorrect PitchTier, got:\n%s\nwant:\n%s", pitchTier.String(), wantPitchTier)
+	}
+
+	var pitch bytes.Buffer
+	if err := annotation.WritePraatPitch(&pitch, contour, opts); err != nil {
+		t.Fatalf("error writing Pitch: %v", err)
+	}
+	for _, want := range []string{
+		"Object class = \"Pitch 1\"", "nx = 3 \ndx = 0.01 \nx1 = 0.02 \nceiling = 600 \n",
+		"frames [1]:\n        intensity = 0 \n        nCandidates = 1 \n",
+		"frames [3]:", "frequency = 221.5 \n                strength = 0.9 \n",
+	} {
+		if !strings.Contains(pitch.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, pitch.String())
+		}
+	}
+
+	if err := annotation.WritePitchTier(&bytes.Buffer{}, contour, annotation.PraatOptions{Duration: -1}); err == nil {
+		t.Error("expected an error for a negative duration")
+	}
+}
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+
+	events := []music.NoteEvent{
+		{Onset: 0.5, Duration: 0.25, MIDI: 69, Cents: 12.4},
+		{Onset: 1, Duration: 0.5, MIDI: 61, Cents: -3},
+	}
+
+	tests := []struct {
+		name  string
+		write func(io.Writer) error
+		want  string
+	}{
+		{
+			name:  "Audacity notes",
+			write: func(w io.Writer) error { return annotation.WriteAudacityNotes(w, events) },
+			want:  "0.5\t0.75\tA4 +12c\n1\t1.5\tC#4 -3c\n",
+		},
+		{
+			name:  "Audacity track",
+			write: func(w io.Writer) error { return annotation.WriteAudacityTrack(w, contour, 0) },
+			want:  "0.01\t0.01\tA3 +0c\n0.02\t0.02\tA3 +12c\n",
+		},
+		{
+			name: "Sonic Visualiser notes",
+			write: func(w io.Writer) error {
+				return annotation.WriteSonicVisualiserNotes(w, []music.NoteEvent{{Onset: 2, Duration: 1, MIDI: 57}}, 0)
+			},
+			want: "2\t220\t1\tA3 +0c\n",
+		},
+		{
+			name:  "Sonic Visualiser track",
+			write: func(w io.Writer) error { return annotation.WriteSonicVisualiserTrack(w, contour, 442) },
+			want:  "0.01\t220\tA3 -8c\n0.02\t221.5\tA3 +4c\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+			if err := test.write(&buffer); err != nil {
+				t.Fatalf("error writing labels: %v", err)
+			}
+			if buffer.String() != test.want {
+				t.Errorf("incorrect labels, got %q, want %q", buffer.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestJAMS(t *testing.T) {
+	t.Parallel()
+
+	want := annotation.JAMS{
+		Title:    "Melody",
+		Duration: 2,
+		Contour: []annotation.Frame{
+			{Time: 0, Frequency: 0, Voiced: false},
+			{Time: 0.01, Frequency: 220, Voiced: true},
+			{Time: 0.02, Frequency: 221.5, Voiced: false},
+		},
+		Notes: []music.NoteEvent{{Onset: 0.5, Duration: 0.25, MIDI: 69, Cents: 12.5}},
+	}
+	var buffer bytes.Buffer
+	if err := annotation.WriteJAMS(&buffer, want); err != nil {
+		t.Fatalf("error writing JAMS: %v", err)
+	}
+	for _, namespace := range []string{`"namespace": "pitch_contour"`, `"namespace": "note_midi"`, `"jams_version"`} {
+		if !strings.Contains(buffer.String(), namespace) {
+			t.Errorf("missing %s in output:\n%s", namespace, buffer.String())
+		}
+	}
+	got, err := annotation.ReadJAMS(&buffer)
+	if err != nil {
+		t.Fatalf("error reading JAMS: %v", err)
+	}
+	if got.Title != want.Title || got.Duration != want.Duration || !slices.Equal(got.Contour, want.Contour) ||
+		!slices.Equal(got.Notes, want.Notes) {
+		t.Errorf("incorrect round trip, got %+v, want %+v", got, want)
+	}
+
+	input := `{"file_metadata": {"duration": 1}, "annotations": [
+		{"namespace": "beat", "data": [{"time": 0, "duration": 0, "value": 1, "confidence": null}]},
+		{"namespace": "note_hz", "data": [{"time": 0.1, "duration": 0.5, "value": 440, "confidence": 1}]},
+		{"namespace": "pitch_contour", "data": [{"time": 0, "duration": 0, "value": {"index": 0, "frequency": -110}}]}
+	]}`
+	got, err = annotation.ReadJAMS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("error reading JAMS: %v", err)
+	}
+	wantNotes := []music.NoteEvent{{Onset: 0.1, Duration: 0.5, MIDI: 69}}
+	wantContour := []annotation.Frame{{Time: 0, Frequency: 110, Voiced: false}}
+	if !slices.Equal(got.Notes, wantNotes) || !slices.Equal(got.Contour, wantContour) {
+		t.Errorf("incorrect annotations, got %+v and %+v, want %+v and %+v",
+			got.Notes, got.Contour, wantNotes, wantContour)
+	}
+
+	input = `{"annotations": [{"namespace": "note_midi", "data": [{"value": "A4"}]}]}`
+	if _, err := annotation.ReadJAMS(strings.NewReader(input)); err == nil {
+		t.Error("expected an error for a non-numeric note")
+	}
+}
@@ -0,0 +1,168 @@
+package annotation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+type (
+	// PraatOptions configure WritePitchTier and WritePraatPitch.
+	PraatOptions struct {
+		// Length of the analysis frames in seconds. Results are timed at the start of their frames while Praat times
+		// them at the center, so they are shifted by half of it.
+		FrameDuration float64
+		Duration      float64 // Duration of the sound in seconds, the end of the last frame is used if zero.
+		Ceiling       float64 // Pitch ceiling of a Pitch object in Hz, 600 Hz or the highest frequency if zero.
+	}
+	// contourPoint is a result as written by WriteJSONL.
+	contourPoint struct {
+		Time       float64  `json:"time"`
+		Frame      int      `json:"frame"`
+		Frequency  float64  `json:"frequency"`
+		Confidence float64  `json:"confidence"`
+		Voiced     bool     `json:"voiced"`
+		Level      *float64 `json:"level"` // Null for digital silence, which JSON can't represent as a number.
+	}
+)
+
+// csvHeader are the columns written by WriteCSV.
+var csvHeader = []string{"time", "frame", "frequency", "confidence", "voiced", "level"}
+
+// WriteCSV writes the results as CSV with a header row, one row per result with the columns of csvHeader. The level
+// of digital silence is written as -Inf.
+func WriteCSV(w io.Writer, results []yinfft.Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, result := range results {
+		err := writer.Write([]string{
+			formatFloat(result.Time),
+			strconv.Itoa(result.Frame),
+			formatFloat(result.Frequency),
+			formatFloat(result.Confidence),
+			strconv.FormatBool(result.Voiced),
+			formatFloat(result.Level),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONL writes the results as JSON Lines, one object per result with the fields of WriteCSV. The level of
+// digital silence is written as null.
+func WriteJSONL(w io.Writer, results []yinfft.Result) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for _, result := range results {
+		point := contourPoint{
+			Time:       result.Time,
+			Frame:      result.Frame,
+			Frequency:  result.Frequency,
+			Confidence: result.Confidence,
+			Voiced:     result.Voiced,
+		}
+		if !math.IsInf(result.Level, 0) && !math.IsNaN(result.Level) {
+			point.Level = &result.Level
+		}
+		if err := encoder.Encode(point); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// WritePitchTier writes the voiced results as a Praat PitchTier in the text format, which Praat opens with
+// "Read from file..." and can use to resynthesize or compare contours.
+func WritePitchTier(w io.Writer, results []yinfft.Result, opts PraatOptions) error {
+	duration, err := opts.duration(results)
+	if err != nil {
+		return err
+	}
+
+	var voiced []yinfft.Result
+	for _, result := range results {
+		if result.Voiced && result.Frequency > 0 {
+			voiced = append(voiced, result)
+		}
+	}
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintf(buffered, "File type = \"ooTextFile\"\nObject class = \"PitchTier\"\n\n")
+	fmt.Fprintf(buffered, "xmin = 0 \nxmax = %s \npoints: size = %d \n", formatFloat(duration), len(voiced))
+	for i, result := range voiced {
+		fmt.Fprintf(buffered, "points [%d]:\n    number = %s \n    value = %s \n",
+			i+1, formatFloat(result.Time+opts.FrameDuration/2), formatFloat(result.Frequency))
+	}
+	return buffered.Flush()
+}
+
+// WritePraatPitch writes the results as a Praat Pitch object in the text format, with a single candidate per frame:
+// the detected frequency with the confidence as its strength, or an unvoiced candidate. The results must be evenly
+// spaced, as from yinfft.PitchDetector.DetectAll.
+func WritePraatPitch(w io.Writer, results []yinfft.Result, opts PraatOptions) error {
+	duration, err := opts.duration(results)
+	if err != nil {
+		return err
+	}
+	var step, first float64
+	if len(results) > 0 {
+		first = results[0].Time + opts.FrameDuration/2
+		step = opts.FrameDuration
+	}
+	if len(results) > 1 {
+		step = results[1].Time - results[0].Time
+	}
+	ceiling := opts.Ceiling
+	if ceiling == 0 {
+		ceiling = 600
+		for _, result := range results {
+			ceiling = max(ceiling, result.Frequency)
+		}
+	}
+
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintf(buffered, "File type = \"ooTextFile\"\nObject class = \"Pitch 1\"\n\n")
+	fmt.Fprintf(buffered, "xmin = 0 \nxmax = %s \nnx = %d \ndx = %s \nx1 = %s \nceiling = %s \nmaxnCandidates = 1 \n",
+		formatFloat(duration), len(results), formatFloat(step), formatFloat(first), formatFloat(ceiling))
+	buffered.WriteString("frames []: \n")
+	for i, result := range results {
+		frequency, strength := 0.0, 0.0
+		if result.Voiced && result.Frequency > 0 {
+			frequency, strength = result.Frequency, result.Confidence
+		}
+		fmt.Fprintf(buffered, "    frames [%d]:\n        intensity = 0 \n        nCandidates = 1 \n", i+1)
+		fmt.Fprintf(buffered, "        candidates []: \n            candidates [1]:\n")
+		fmt.Fprintf(buffered, "                frequency = %s \n                strength = %s \n",
+			formatFloat(frequency), formatFloat(strength))
+	}
+	return buffered.Flush()
+}
+
+// duration returns the duration of the sound, validating the options.
+func (o PraatOptions) duration(results []yinfft.Result) (float64, error) {
+	if !(o.FrameDuration >= 0) || !(o.Duration >= 0) || !(o.Ceiling >= 0) {
+		return 0, fmt.Errorf(
+			"invalid Praat options: frame duration %v, duration %v and ceiling %v must not be negative",
+			o.FrameDuration, o.Duration, o.Ceiling,
+		)
+	}
+	if o.Duration > 0 || len(results) == 0 {
+		return o.Duration, nil
+	}
+	return results[len(results)-1].Time + o.FrameDuration, nil
+}
+
+// formatFloat formats the value with the fewest digits representing it exactly.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
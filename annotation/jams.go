@@ -0,0 +1,172 @@
+package annotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// JAMSVersion is the JAMS version written into the file metadata.
+const JAMSVersion = "0.3.4"
+
+// Namespaces of the JAMS annotations read and written by ReadJAMS and WriteJAMS.
+const (
+	NamespacePitchContour = "pitch_contour"
+	NamespaceNoteMIDI     = "note_midi"
+	NamespaceNoteHz       = "note_hz"
+)
+
+type (
+	// JAMS is a JAMS (JSON Annotated Music Specification) file with a pitch contour and notes.
+	JAMS struct {
+		Title    string            // Title of the annotated recording.
+		Duration float64           // Duration of the annotated recording in seconds.
+		Contour  []Frame           // Frames of the pitch_contour annotation.
+		Notes    []music.NoteEvent // Notes of the note_midi or note_hz annotation.
+	}
+	// jamsFile is the JSON structure of a JAMS file.
+	jamsFile struct {
+		FileMetadata struct {
+			Title       string          `json:"title"`
+			Artist      string          `json:"artist"`
+			Release     string          `json:"release"`
+			Duration    float64         `json:"duration"`
+			Identifiers json.RawMessage `json:"identifiers"`
+			JAMSVersion string          `json:"jams_version"`
+		} `json:"file_metadata"`
+		Annotations []jamsAnnotation `json:"annotations"`
+		Sandbox     json.RawMessage  `json:"sandbox"`
+	}
+	// jamsAnnotation is an annotation of a JAMS file.
+	jamsAnnotation struct {
+		Namespace          string            `json:"namespace"`
+		Data               []jamsObservation `json:"data"`
+		AnnotationMetadata struct {
+			AnnotationTools string `json:"annotation_tools"`
+		} `json:"annotation_metadata"`
+		Sandbox  json.RawMessage `json:"sandbox"`
+		Time     float64         `json:"time"`
+		Duration float64         `json:"duration"`
+	}
+	// jamsObservation is an observation of a JAMS annotation, with a value depending on the namespace.
+	jamsObservation struct {
+		Time       float64         `json:"time"`
+		Duration   float64         `json:"duration"`
+		Value      json.RawMessage `json:"value"`
+		Confidence *float64        `json:"confidence"`
+	}
+	// jamsContourValue is the value of a pitch_contour observation.
+	jamsContourValue struct {
+		Index     int     `json:"index"`
+		Frequency float64 `json:"frequency"`
+		Voiced    *bool   `json:"voiced"`
+	}
+)
+
+// ReadJAMS reads the first pitch_contour annotation and the first note_midi or note_hz annotation of a JAMS file,
+// ignoring the others. Notes are rounded to the nearest MIDI note with the remainder in cents; note_hz values are
+// converted with the standard A4 of 440 Hz. Contour frames without a voicing flag are voiced if their frequency is
+// positive.
+func ReadJAMS(r io.Reader) (JAMS, error) {
+	var file jamsFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return JAMS{}, fmt.Errorf("failed to decode JAMS: %w", err)
+	}
+
+	jams := JAMS{Title: file.FileMetadata.Title, Duration: file.FileMetadata.Duration}
+	var hasContour, hasNotes bool
+	for _, annotation := range file.Annotations {
+		switch {
+		case annotation.Namespace == NamespacePitchContour && !hasContour:
+			hasContour = true
+			for i, observation := range annotation.Data {
+				var value jamsContourValue
+				if err := json.Unmarshal(observation.Value, &value); err != nil {
+					return JAMS{}, fmt.Errorf("invalid pitch_contour observation %d: %w", i, err)
+				}
+				voiced := value.Frequency > 0
+				if value.Voiced != nil {
+					voiced = *value.Voiced
+				}
+				jams.Contour = append(jams.Contour,
+					Frame{Time: observation.Time, Frequency: math.Abs(value.Frequency), Voiced: voiced})
+			}
+		case (annotation.Namespace == NamespaceNoteMIDI || annotation.Namespace == NamespaceNoteHz) && !hasNotes:
+			hasNotes = true
+			for i, observation := range annotation.Data {
+				var value float64
+				if err := json.Unmarshal(observation.Value, &value); err != nil {
+					return JAMS{}, fmt.Errorf("invalid %s observation %d: %w", annotation.Namespace, i, err)
+				}
+				if annotation.Namespace == NamespaceNoteHz {
+					if value <= 0 {
+						return JAMS{}, fmt.Errorf("invalid note_hz observation %d: frequency %g Hz", i, value)
+					}
+					value = music.FrequencyToMIDI(value, 0)
+				}
+				midi := int(math.Round(value))
+				jams.Notes = append(jams.Notes, music.NoteEvent{
+					Onset:    observation.Time,
+					Duration: observation.Duration,
+					MIDI:     midi,
+					Cents:    100 * (value - float64(midi)),
+				})
+			}
+		}
+	}
+	return jams, nil
+}
+
+// WriteJAMS writes a JAMS file with a pitch_contour annotation of the contour and a note_midi annotation of the notes,
+// each omitted if empty. Notes are written as fractional MIDI numbers including their cents.
+func WriteJAMS(w io.Writer, jams JAMS) error {
+	var file jamsFile
+	file.FileMetadata.Title = jams.Title
+	file.FileMetadata.Duration = jams.Duration
+	file.FileMetadata.Identifiers = json.RawMessage("{}")
+	file.FileMetadata.JAMSVersion = JAMSVersion
+	file.Annotations = []jamsAnnotation{}
+	file.Sandbox = json.RawMessage("{}")
+
+	if len(jams.Contour) > 0 {
+		data := make([]jamsObservation, len(jams.Contour))
+		for i, frame := range jams.Contour {
+			value, err := json.Marshal(jamsContourValue{Frequency: frame.Frequency, Voiced: &frame.Voiced})
+			if err != nil {
+				return err
+			}
+			data[i] = jamsObservation{Time: frame.Time, Value: value}
+		}
+		file.Annotations = append(file.Annotations, newJAMSAnnotation(NamespacePitchContour, data, jams.Duration))
+	}
+	if len(jams.Notes) > 0 {
+		data := make([]jamsObservation, len(jams.Notes))
+		for i, note := range jams.Notes {
+			value, err := json.Marshal(float64(note.MIDI) + note.Cents/100)
+			if err != nil {
+				return err
+			}
+			data[i] = jamsObservation{Time: note.Onset, Duration: note.Duration, Value: value}
+		}
+		file.Annotations = append(file.Annotations, newJAMSAnnotation(NamespaceNoteMIDI, data, jams.Duration))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// newJAMSAnnotation returns an annotation of the namespace spanning the recording.
+func newJAMSAnnotation(namespace string, data []jamsObservation, duration float64) jamsAnnotation {
+	annotation := jamsAnnotation{
+		Namespace: namespace,
+		Data:      data,
+		Sandbox:   json.RawMessage("{}"),
+		Duration:  duration,
+	}
+	annotation.AnnotationMetadata.AnnotationTools = "go-yinfft"
+	return annotation
+}
@@ -0,0 +1,70 @@
+package annotation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// WriteAudacityNotes writes the note events as an Audacity label track, one "start\tend\tlabel" region per note
+// labeled with its name and deviation, e.g. "A4 +12c". Audacity imports it with File > Import > Labels.
+func WriteAudacityNotes(w io.Writer, events []music.NoteEvent) error {
+	buffered := bufio.NewWriter(w)
+	for _, event := range events {
+		fmt.Fprintf(buffered, "%s\t%s\t%s\n", formatFloat(event.Onset), formatFloat(event.End()),
+			noteLabel(music.NoteName(event.MIDI), event.Cents))
+	}
+	return buffered.Flush()
+}
+
+// WriteAudacityTrack writes the voiced results as an Audacity label track of point labels, each labeled with the
+// nearest note and deviation for the reference frequency of A4, DefaultA4 if zero.
+func WriteAudacityTrack(w io.Writer, results []yinfft.Result, a4 float64) error {
+	buffered := bufio.NewWriter(w)
+	for _, result := range results {
+		if !result.Voiced || result.Frequency <= 0 {
+			continue
+		}
+		note := music.FrequencyToNote(result.Frequency, a4)
+		time := formatFloat(result.Time)
+		fmt.Fprintf(buffered, "%s\t%s\t%s\n", time, time, noteLabel(note.String(), note.Cents))
+	}
+	return buffered.Flush()
+}
+
+// WriteSonicVisualiserNotes writes the note events for a Sonic Visualiser note layer, one
+// "time\tvalue\tduration\tlabel" line per note with the frequency of the note, including its deviation, as the
+// value. The reference frequency of A4 is a4, DefaultA4 if zero. Sonic Visualiser imports it with File > Import
+// Annotation Layer.
+func WriteSonicVisualiserNotes(w io.Writer, events []music.NoteEvent, a4 float64) error {
+	buffered := bufio.NewWriter(w)
+	for _, event := range events {
+		frequency := music.MIDIToFrequency(float64(event.MIDI)+event.Cents/100, a4)
+		fmt.Fprintf(buffered, "%s\t%s\t%s\t%s\n", formatFloat(event.Onset), formatFloat(frequency),
+			formatFloat(event.Duration), noteLabel(music.NoteName(event.MIDI), event.Cents))
+	}
+	return buffered.Flush()
+}
+
+// WriteSonicVisualiserTrack writes the voiced results for a Sonic Visualiser time-value layer, one
+// "time\tvalue\tlabel" line per frame with the frequency as the value, labeled like WriteAudacityTrack.
+func WriteSonicVisualiserTrack(w io.Writer, results []yinfft.Result, a4 float64) error {
+	buffered := bufio.NewWriter(w)
+	for _, result := range results {
+		if !result.Voiced || result.Frequency <= 0 {
+			continue
+		}
+		note := music.FrequencyToNote(result.Frequency, a4)
+		fmt.Fprintf(buffered, "%s\t%s\t%s\n", formatFloat(result.Time), formatFloat(result.Frequency),
+			noteLabel(note.String(), note.Cents))
+	}
+	return buffered.Flush()
+}
+
+// noteLabel returns the label of a note with its deviation in whole cents, e.g. "A4 +12c".
+func noteLabel(name string, cents float64) string {
+	return fmt.Sprintf("%s %+.0fc", name, cents)
+}
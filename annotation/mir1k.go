@@ -0,0 +1,42 @@
+package annotation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// MIR1KHop is the time between the frames of MIR-1K pitch labels in seconds.
+const MIR1KHop = 0.02
+
+// ReadMIR1K reads MIR-1K pitch labels (.pv files): one MIDI note number per line and frame, zero for unvoiced
+// frames. The frames are 40 ms long and MIR1KHop apart, so the i-th frame is centered at (i+1)*MIR1KHop seconds.
+// Pitches are converted to frequencies with the standard A4 of 440 Hz.
+func ReadMIR1K(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		pitch, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pitch: %w", line, err)
+		}
+		frame := Frame{Time: float64(len(frames)+1) * MIR1KHop}
+		if pitch > 0 {
+			frame.Frequency, frame.Voiced = music.MIDIToFrequency(pitch, 0), true
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
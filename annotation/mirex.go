@@ -0,0 +1,81 @@
+// Package annotation reads and writes pitch annotation formats used by datasets and evaluation tools, and exports
+// pitch contours for plotting and comparison with tools such as Praat.
+package annotation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft/session"
+)
+
+// Frame is a single entry of a melody annotation.
+type Frame struct {
+	Time      float64 // Time in seconds.
+	Frequency float64 // Frequency in Hz, possibly a pitch estimate for unvoiced frames, or 0 if there's none.
+	Voiced    bool    // Whether the frame is voiced.
+}
+
+// FromTrack converts a pitch track into annotation frames, treating points with a confidence below minConfidence or
+// without a detected frequency as unvoiced.
+func FromTrack(track []session.Point, minConfidence float64) []Frame {
+	frames := make([]Frame, len(track))
+	for i, point := range track {
+		frames[i] = Frame{
+			Time:      point.Time,
+			Frequency: point.Frequency,
+			Voiced:    point.Frequency > 0 && point.Confidence >= minConfidence,
+		}
+	}
+	return frames
+}
+
+// ReadMIREX reads a MIREX melody extraction annotation: one "time frequency" pair per line, separated by whitespace
+// or a comma, where non-positive frequencies mark unvoiced frames and negative ones carry a pitch estimate.
+func ReadMIREX(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected time and frequency, got %q", line, text)
+		}
+		time, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid time: %w", line, err)
+		}
+		frequency, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid frequency: %w", line, err)
+		}
+		frames = append(frames, Frame{Time: time, Frequency: math.Abs(frequency), Voiced: frequency > 0})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+// WriteMIREX writes frames as a tab-separated MIREX melody extraction annotation, negating the frequency of unvoiced
+// frames.
+func WriteMIREX(w io.Writer, frames []Frame) error {
+	buffered := bufio.NewWriter(w)
+	for _, frame := range frames {
+		frequency := math.Abs(frame.Frequency)
+		if !frame.Voiced && frequency != 0 {
+			frequency = -frequency
+		}
+		fmt.Fprintf(buffered, "%s\t%s\n", strconv.FormatFloat(frame.Time, 'f', -1, 64),
+			strconv.FormatFloat(frequency, 'f', -1, 64))
+	}
+	return buffered.Flush()
+}
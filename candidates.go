@@ -0,0 +1,91 @@
+package yinfft
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Candidate is a local minimum of the yin function, a possible period of the frame.
+type Candidate struct {
+	Frequency float64 // Frequency of the period in Hz.
+	Tau       float64 // Period in samples.
+	Yin       float64 // Value of the yin function at the period, lower is more periodic.
+	// Probability that the candidate is the fundamental period, set by DetectProbabilistic.
+	Probability float64
+}
+
+// DetectCandidates returns up to n period candidates of the frame, the local minima of the yin function within the
+// frequency range ordered by their yin value, best first. Unlike DetectFromFrame, it exposes the alternatives to the
+// best period, e.g. for external Viterbi smoothing or octave error correction. Returns no candidates if the frame is
+// silent or no minimum is below the tolerance. The frame is modified in place.
+func (pd *PitchDetector) DetectCandidates(frame []float64, n int) ([]Candidate, error) {
+	spectrum, err := pd.PrepareSpectrum(frame)
+	if err != nil {
+		return nil, err
+	}
+	return pd.DetectCandidatesFromSpectrum(spectrum, n)
+}
+
+// DetectCandidatesFromSpectrum returns up to n period candidates of the magnitude spectrum, see DetectCandidates.
+func (pd *PitchDetector) DetectCandidatesFromSpectrum(spectrum []float64, n int) ([]Candidate, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of candidates: %d, must be positive", n)
+	}
+	if err := pd.checkSpectrum(spectrum); err != nil {
+		return nil, err
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	return slices.Clone(pd.bestCandidates(spectrum, n, scratch)), nil
+}
+
+// bestCandidates returns up to n period candidates of a spectrum of the correct size ordered by their yin value,
+// backed by the scratch, which holds the yin function of the spectrum afterwards.
+func (pd *PitchDetector) bestCandidates(spectrum []float64, n int, scratch *scratch) []Candidate {
+	candidates, globalMin := pd.yinMinima(spectrum, scratch)
+	if pd.params.Tolerance < 1.0 && globalMin >= pd.params.Tolerance {
+		return nil
+	}
+
+	slices.SortFunc(candidates, func(a, b Candidate) int {
+		return cmp.Compare(a.Yin, b.Yin)
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// yinMinima computes the yin function of a spectrum of the correct size into the scratch and returns its local minima
+// within the frequency range in period order, backed by the scratch, together with its global minimum. Returns no
+// minima if the spectrum is silent.
+func (pd *PitchDetector) yinMinima(spectrum []float64, scratch *scratch) ([]Candidate, float64) {
+	globalMin, ok := pd.yinFunction(spectrum, scratch)
+	if !ok {
+		return nil, 1
+	}
+
+	yinLen := len(scratch.yin)
+	yin := scratch.yin
+	minima := scratch.candidates[:0]
+	for i := max(pd.minPeriodSamples, 1); i <= pd.maxPeriodSamples && i+1 < yinLen; i++ {
+		if yin[i] > yin[i-1] || yin[i] >= yin[i+1] {
+			continue
+		}
+		tau, value := float64(i), yin[i]
+		if pd.params.ShouldInterpolate {
+			// Parabolic interpolation of the minimum through the neighbouring lags.
+			if curvature := yin[i-1] - 2*yin[i] + yin[i+1]; curvature > 0 {
+				offset := 0.5 * (yin[i-1] - yin[i+1]) / curvature
+				tau += offset
+				value -= 0.25 * (yin[i-1] - yin[i+1]) * offset
+			}
+		}
+		minima = append(minima, Candidate{
+			Frequency: pd.params.analysisSampleRate() / tau,
+			Tau:       tau * float64(pd.params.decimationFactor()),
+			Yin:       value,
+		})
+	}
+	scratch.candidates = minima
+	return minima, globalMin
+}
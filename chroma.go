@@ -0,0 +1,41 @@
+package yinfft
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	defaultReferenceA4 = 440.0  // Reference frequency of A4 in Hz if ReferenceA4 is zero.
+	chromaMinFrequency = 40.0   // Lowest bin frequency contributing to Chroma in Hz, as in Essentia's HPCP.
+	chromaMaxFrequency = 5000.0 // Highest bin frequency contributing to Chroma in Hz, as in Essentia's HPCP.
+)
+
+// Chroma returns the pitch class profile of the magnitude spectrum, e.g. from PrepareSpectrum, for key and chord
+// estimation: the weighted energy of the bins between 40 Hz and 5 kHz, summed by the pitch class nearest to their
+// frequency in equal temperament relative to ReferenceA4. Index 0 is C, and the vector is normalized to a maximum of
+// one. Bins beyond FFTSize()/2+1 are ignored, and a silent spectrum gives all zeros.
+func (pd *PitchDetector) Chroma(spectrum []float64) [12]float64 {
+	referenceA4 := pd.params.ReferenceA4
+	if referenceA4 == 0 {
+		referenceA4 = defaultReferenceA4
+	}
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	minBin := max(1, int(math.Ceil(chromaMinFrequency/binFrequency)))
+	maxBin := min(len(spectrum), len(pd.weights)) - 1
+	maxBin = min(maxBin, int(math.Floor(chromaMaxFrequency/binFrequency)))
+
+	var chroma [12]float64
+	for bin := minBin; bin <= maxBin; bin++ {
+		// Semitones from A4, shifted so that C is pitch class 0.
+		semitones := int(math.Round(12*math.Log2(float64(bin)*binFrequency/referenceA4))) + 9
+		chroma[((semitones%12)+12)%12] += spectrum[bin] * spectrum[bin] * pd.weights[bin]
+	}
+
+	if peak := slices.Max(chroma[:]); peak > 0 {
+		for i := range chroma {
+			chroma[i] /= peak
+		}
+	}
+	return chroma
+}
@@ -0,0 +1,61 @@
+// Package dsp exposes the windowing and spectrum utilities feeding the pitch detector, so applications that already
+// compute spectra, e.g. for visualization, can share the exact pipeline behind PitchDetector.DetectFromSpectrum.
+//
+// The spectrum a detector expects for a frame is
+//
+//	params := detector.Params()
+//	spectrum := dsp.PrepareSpectrum(frame, detector.Window(), params.FFTSize(), params.DenormalThreshold)
+//
+// which matches PitchDetector.PrepareSpectrum for detectors without Decimation.
+package dsp
+
+import (
+	"math/cmplx"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// Window returns the coefficients of the named window of the given size. Names are those of the yinfft.WindowType
+// constants except "kaiser", see KaiserWindow. The caller owns the returned slice.
+func Window(name string, size int) ([]float64, error) {
+	coefficients, err := internal.Window(name, size)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(coefficients), nil
+}
+
+// KaiserWindow returns the coefficients of the Kaiser window of the given size with shape parameter beta, which
+// trades main lobe width for sidelobe level as beta grows.
+func KaiserWindow(beta float64, size int) []float64 {
+	return internal.WindowCoefficients(internal.KaiserWindow(beta), size)
+}
+
+// ApplyWindow multiplies the frame by the window coefficients in place.
+func ApplyWindow(frame, window []float64) {
+	internal.ApplyWindow(frame, window)
+}
+
+// FFTReal returns the FFT of a real-valued signal, computed with the FFT backend the package was built with.
+func FFTReal(x []float64) []complex128 {
+	return internal.FFTReal(x)
+}
+
+// CartesianToPolar returns the magnitudes and phases in radians of the first len(spectrum)/2+1 bins of a spectrum
+// of a real-valued signal, the bins up to the Nyquist frequency.
+func CartesianToPolar(spectrum []complex128) (magnitude []float64, phase []float64) {
+	bins := len(spectrum)/2 + 1
+	magnitude, phase = make([]float64, bins), make([]float64, bins)
+	for i, bin := range spectrum[:bins] {
+		magnitude[i], phase[i] = cmplx.Polar(bin)
+	}
+	return magnitude, phase
+}
+
+// PrepareSpectrum windows the frame in place, zero-pads it to fftSize samples and returns its magnitude spectrum of
+// fftSize/2+1 bins. Windowed samples and bins with a magnitude below flushThreshold are flushed to zero, zero
+// disables flushing. This is the spectrum PitchDetector.DetectFromSpectrum expects.
+func PrepareSpectrum(frame, window []float64, fftSize int, flushThreshold float64) []float64 {
+	return internal.PrepareSpectrum(frame, window, fftSize, flushThreshold)
+}
@@ -0,0 +1,112 @@
+package dsp_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/dsp"
+)
+
+func TestPrepareSpectrum(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		options []yinfft.Option
+	}{
+		{name: "default"},
+		{name: "zero-padded blackman", options: []yinfft.Option{
+			yinfft.WithZeroPadFactor(2), yinfft.WithWindow(yinfft.WindowBlackman),
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize = 2048
+			for _, option := range tc.options {
+				option(&params)
+			}
+			detector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frame := make([]float64, params.FrameSize)
+			for i := range frame {
+				frame[i] = math.Sin(2 * math.Pi * 440 * float64(i) / params.SampleRate)
+			}
+
+			want, err := detector.PrepareSpectrum(slices.Clone(frame))
+			if err != nil {
+				t.Fatalf("error preparing spectrum: %v", err)
+			}
+			got := dsp.PrepareSpectrum(frame, detector.Window(), params.FFTSize(), params.DenormalThreshold)
+			if !slices.Equal(got, want) {
+				t.Errorf("spectrum differs from the detector's spectrum")
+			}
+
+			frequency, _, err := detector.DetectFromSpectrum(got)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a spectrum: %v", err)
+			}
+			if math.Abs(frequency-440) > 2 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want 440 Hz", frequency)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	window, err := dsp.Window(string(yinfft.WindowHann), 5)
+	if err != nil {
+		t.Fatalf("error computing window: %v", err)
+	}
+	if want := []float64{0, 0.5, 1, 0.5, 0}; !slices.EqualFunc(window, want, func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-12
+	}) {
+		t.Errorf("incorrect hann window, got %v, want %v", window, want)
+	}
+
+	window[2] = 0
+	if window, _ := dsp.Window(string(yinfft.WindowHann), 5); window[2] != 1 {
+		t.Errorf("modifying a returned window changed later windows")
+	}
+
+	if _, err := dsp.Window("unknown", 5); err == nil {
+		t.Errorf("expected error for an unknown window")
+	}
+
+	kaiser := dsp.KaiserWindow(yinfft.DefaultKaiserBeta, 5)
+	if kaiser[2] != 1 || kaiser[0] != kaiser[4] || kaiser[0] >= kaiser[1] {
+		t.Errorf("incorrect kaiser window, got %v", kaiser)
+	}
+}
+
+func TestCartesianToPolar(t *testing.T) {
+	t.Parallel()
+
+	// A cosine at bin 2 with a phase of pi/4, whose spectrum has magnitude n/2 there.
+	n := 16
+	signal := make([]float64, n)
+	for i := range signal {
+		signal[i] = math.Cos(2*math.Pi*2*float64(i)/float64(n) + math.Pi/4)
+	}
+
+	magnitude, phase := dsp.CartesianToPolar(dsp.FFTReal(signal))
+	if len(magnitude) != n/2+1 || len(phase) != n/2+1 {
+		t.Fatalf("incorrect number of bins, got %d and %d, want %d", len(magnitude), len(phase), n/2+1)
+	}
+	if math.Abs(magnitude[2]-float64(n)/2) > 1e-9 {
+		t.Errorf("incorrect magnitude, got %.4f, want %.4f", magnitude[2], float64(n)/2)
+	}
+	if math.Abs(phase[2]-math.Pi/4) > 1e-9 {
+		t.Errorf("incorrect phase, got %.4f, want %.4f", phase[2], math.Pi/4)
+	}
+}
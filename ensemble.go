@@ -0,0 +1,103 @@
+package yinfft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// DefaultEnsembleToleranceCents is the largest distance between agreeing votes of an EnsembleDetector if not
+// configured.
+const DefaultEnsembleToleranceCents = 50
+
+type (
+	// EnsembleParams configure an EnsembleDetector.
+	EnsembleParams struct {
+		ToleranceCents float64 // Largest distance of agreeing votes, DefaultEnsembleToleranceCents is used if zero.
+		MinVotes       int     // Votes needed for a voiced result, a majority of the algorithms is used if zero.
+	}
+	// EnsembleDetector runs several algorithms on the same frame and reports their consensus. Each voiced result is a
+	// vote, votes within ToleranceCents of each other agree, and the group of agreeing votes with the highest total
+	// confidence wins if it has at least MinVotes votes.
+	EnsembleDetector struct {
+		algorithms []PitchAlgorithm
+		params     EnsembleParams
+		frame      []float64
+	}
+)
+
+var _ PitchAlgorithm = (*EnsembleDetector)(nil)
+
+// NewEnsembleDetector creates an EnsembleDetector combining the algorithms, e.g. created with NewDetector.
+func NewEnsembleDetector(params EnsembleParams, algorithms ...PitchAlgorithm) (*EnsembleDetector, error) {
+	if len(algorithms) == 0 {
+		return nil, errors.New("no algorithms to combine")
+	}
+	if params.ToleranceCents == 0 {
+		params.ToleranceCents = DefaultEnsembleToleranceCents
+	}
+	if params.MinVotes == 0 {
+		params.MinVotes = len(algorithms)/2 + 1
+	}
+	if params.ToleranceCents < 0 {
+		return nil, fmt.Errorf("invalid 'toleranceCents': %g, must be positive", params.ToleranceCents)
+	}
+	if params.MinVotes < 1 || params.MinVotes > len(algorithms) {
+		return nil, fmt.Errorf("invalid 'minVotes': %d, must be in range [1, %d]", params.MinVotes, len(algorithms))
+	}
+	return &EnsembleDetector{algorithms: algorithms, params: params}, nil
+}
+
+// Detect runs all algorithms on copies of the frame and returns their consensus. The frequency is the
+// confidence-weighted geometric mean of the winning votes and the confidence their total confidence divided by the
+// number of algorithms. Algorithms finding no pitch abstain, whether they report it with a zero or NaN frequency or
+// with ErrNoPitch; any other error fails the detection. An EnsembleDetector reuses a frame buffer, so it is not safe for concurrent use.
+func (e *EnsembleDetector) Detect(frame []float64) (Result, error) {
+	votes := make([]Result, 0, len(e.algorithms))
+	for i, algorithm := range e.algorithms {
+		e.frame = append(e.frame[:0], frame...)
+		result, err := algorithm.Detect(e.frame)
+		if errors.Is(err, ErrNoPitch) {
+			continue
+		}
+		if err != nil {
+			return Result{}, fmt.Errorf("algorithm %d failed: %w", i, err)
+		}
+		if result.Frequency > 0 {
+			votes = append(votes, result)
+		}
+	}
+
+	var best Result
+	bestVotes, bestConfidence := 0, -1.0
+	for _, vote := range votes {
+		count, confidence, logSum := 0, 0.0, 0.0
+		for _, other := range votes {
+			if math.Abs(1200*math.Log2(other.Frequency/vote.Frequency)) <= e.params.ToleranceCents {
+				count++
+				confidence += other.Confidence
+				logSum += other.Confidence * math.Log2(other.Frequency)
+			}
+		}
+		if confidence > bestConfidence {
+			bestVotes, bestConfidence = count, confidence
+			best = Result{Frequency: vote.Frequency, Confidence: confidence / float64(len(e.algorithms))}
+			if confidence > 0 {
+				best.Frequency = math.Exp2(logSum / confidence)
+			}
+		}
+	}
+
+	if bestVotes < e.params.MinVotes {
+		return Result{}, nil
+	}
+	best.Voiced = true
+	for _, vote := range votes {
+		// The sample rate isn't known to the ensemble, but the period and frequency of any vote carry it.
+		if sampleRate := vote.Tau * vote.Frequency; sampleRate > 0 {
+			best.Tau = sampleRate / best.Frequency
+			break
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,138 @@
+package eval_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/annotation"
+	"github.com/FreibergVlad/go-yinfft/eval"
+	"github.com/FreibergVlad/go-yinfft/internal/wavio"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	reference := []annotation.Frame{
+		{Time: 0.00},
+		{Time: 0.01},
+		{Time: 0.02, Frequency: 220, Voiced: true},
+		{Time: 0.03, Frequency: 220, Voiced: true},
+		{Time: 0.04, Frequency: 220, Voiced: true},
+		{Time: 0.05, Frequency: 220, Voiced: true},
+	}
+	// Estimated at a 5 ms hop: a false alarm, a correct pitch, an octave error, a correct but unvoiced pitch, and a
+	// wrong pitch.
+	estimate := []annotation.Frame{
+		{Time: 0.000},
+		{Time: 0.005},
+		{Time: 0.010, Frequency: 300, Voiced: true},
+		{Time: 0.015},
+		{Time: 0.020, Frequency: 225, Voiced: true},
+		{Time: 0.025},
+		{Time: 0.030, Frequency: 440, Voiced: true},
+		{Time: 0.035},
+		{Time: 0.040, Frequency: 219},
+		{Time: 0.045},
+		{Time: 0.051, Frequency: 233, Voiced: true},
+	}
+
+	metrics, err := eval.Evaluate(reference, estimate, eval.Options{})
+	if err != nil {
+		t.Fatalf("error evaluating: %v", err)
+	}
+	want := eval.Metrics{
+		VoicingRecall:     0.75,
+		VoicingFalseAlarm: 0.5,
+		RawPitchAccuracy:  0.5,
+		RawChromaAccuracy: 0.75,
+		OverallAccuracy:   2.0 / 6,
+		Frames:            6,
+	}
+	if metrics != want {
+		t.Errorf("incorrect metrics, got %+v, want %+v", metrics, want)
+	}
+
+	metrics, err = eval.Evaluate(reference, estimate, eval.Options{CentTolerance: 100})
+	if err != nil {
+		t.Fatalf("error evaluating: %v", err)
+	}
+	if metrics.RawPitchAccuracy != 0.75 {
+		t.Errorf("incorrect raw pitch accuracy with a 100 cent tolerance, got %v, want 0.75", metrics.RawPitchAccuracy)
+	}
+
+	if _, err := eval.Evaluate(reference, nil, eval.Options{}); err == nil {
+		t.Error("expected an error for an empty estimate")
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	const sampleRate = 44100
+	writeTone := func(name string, frequency float64) {
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("error creating audio file: %v", err)
+		}
+		defer file.Close()
+		writer, err := wavio.NewWriter(file, sampleRate)
+		if err != nil {
+			t.Fatalf("error creating WAVE writer: %v", err)
+		}
+		samples := make([]float64, sampleRate)
+		for i := range samples {
+			samples[i] = 0.5 * math.Sin(2*math.Pi*frequency*float64(i)/sampleRate)
+		}
+		if err := writer.Write(samples); err != nil {
+			t.Fatalf("error writing audio: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("error closing audio: %v", err)
+		}
+	}
+	writeReference := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("error writing reference: %v", err)
+		}
+	}
+
+	// A 220 Hz tone annotated correctly in MedleyDB format, and A3 annotated an octave too high in MIR-1K format.
+	writeTone("a.wav", 220)
+	var medleyDB, mir1k strings.Builder
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&medleyDB, "%.3f,220\n", float64(i)*0.02)
+		mir1k.WriteString("69\n")
+	}
+	writeReference("a.csv", medleyDB.String())
+	writeTone("b.wav", 220)
+	writeReference("b.pv", mir1k.String())
+	writeTone("unannotated.wav", 220)
+
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+	params.HopSize = 441
+	params.MinFrequency = 50
+	report, err := eval.Run(dir, params, eval.Options{MinConfidence: 0.5})
+	if err != nil {
+		t.Fatalf("error running evaluation: %v", err)
+	}
+
+	if len(report.Files) != 2 || report.Files[0].Name != "a.wav" || report.Files[1].Name != "b.wav" {
+		t.Fatalf("incorrect evaluated files: %+v", report.Files)
+	}
+	if metrics := report.Files[0].Metrics; metrics.RawPitchAccuracy != 1 || metrics.VoicingRecall != 1 {
+		t.Errorf("incorrect metrics of the correct annotation: %+v", metrics)
+	}
+	if metrics := report.Files[1].Metrics; metrics.RawPitchAccuracy != 0 || metrics.RawChromaAccuracy != 1 {
+		t.Errorf("incorrect metrics of the octave annotation: %+v", metrics)
+	}
+	if report.Mean.RawPitchAccuracy != 0.5 || report.Mean.Frames != 80 {
+		t.Errorf("incorrect mean metrics: %+v", report.Mean)
+	}
+}
@@ -0,0 +1,118 @@
+// Package eval measures the accuracy of pitch tracks against reference annotations with the standard melody
+// extraction metrics of MIREX and mir_eval, to quantify the effect of parameter changes on a dataset.
+package eval
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/annotation"
+)
+
+// DefaultCentTolerance is the maximum deviation in cents of a correct pitch if not configured.
+const DefaultCentTolerance = 50.0
+
+type (
+	// Options configure the evaluation.
+	Options struct {
+		CentTolerance float64 // Maximum deviation of a correct pitch in cents, DefaultCentTolerance if zero.
+		MinConfidence float64 // Minimum confidence of voiced detection results, see Run.
+	}
+	// Metrics are the melody extraction metrics of an estimated pitch track, fractions in [0, 1].
+	Metrics struct {
+		VoicingRecall     float64 // Fraction of voiced reference frames estimated as voiced.
+		VoicingFalseAlarm float64 // Fraction of unvoiced reference frames estimated as voiced.
+		RawPitchAccuracy  float64 // Fraction of voiced reference frames with a correct pitch estimate, voiced or not.
+		RawChromaAccuracy float64 // RawPitchAccuracy ignoring octave errors.
+		OverallAccuracy   float64 // Fraction of frames with correct voicing and, if voiced, a correct pitch.
+		Frames            int     // Number of reference frames.
+	}
+)
+
+// Evaluate compares an estimated pitch track with a reference annotation. Each reference frame is compared with the
+// estimated frame nearest in time, so the tracks may have different hop sizes. As in mir_eval, the pitch of an
+// estimated frame counts towards the raw accuracies even if it's unvoiced, and a ratio with no frames to count is
+// zero.
+func Evaluate(reference, estimate []annotation.Frame, opts Options) (Metrics, error) {
+	tolerance := opts.CentTolerance
+	if tolerance == 0 {
+		tolerance = DefaultCentTolerance
+	}
+	if !(tolerance > 0) {
+		return Metrics{}, fmt.Errorf("invalid 'CentTolerance': expected positive value, got %v", tolerance)
+	}
+	if len(estimate) == 0 {
+		return Metrics{}, errors.New("empty estimated pitch track")
+	}
+	if !slices.IsSortedFunc(estimate, compareTime) {
+		return Metrics{}, errors.New("estimated pitch track isn't in time order")
+	}
+
+	var voiced, unvoiced, recalled, falseAlarms, rawPitch, rawChroma, overall int
+	for _, frame := range reference {
+		nearest := nearestFrame(estimate, frame.Time)
+		if !frame.Voiced {
+			unvoiced++
+			if nearest.Voiced {
+				falseAlarms++
+			} else {
+				overall++
+			}
+			continue
+		}
+
+		voiced++
+		pitchCorrect, chromaCorrect := false, false
+		if nearest.Frequency > 0 && frame.Frequency > 0 {
+			cents := 1200 * math.Log2(nearest.Frequency/frame.Frequency)
+			pitchCorrect = math.Abs(cents) <= tolerance
+			chromaCorrect = math.Abs(cents-1200*math.Round(cents/1200)) <= tolerance
+		}
+		if nearest.Voiced {
+			recalled++
+			if pitchCorrect {
+				overall++
+			}
+		}
+		if pitchCorrect {
+			rawPitch++
+		}
+		if chromaCorrect {
+			rawChroma++
+		}
+	}
+
+	return Metrics{
+		VoicingRecall:     ratio(recalled, voiced),
+		VoicingFalseAlarm: ratio(falseAlarms, unvoiced),
+		RawPitchAccuracy:  ratio(rawPitch, voiced),
+		RawChromaAccuracy: ratio(rawChroma, voiced),
+		OverallAccuracy:   ratio(overall, len(reference)),
+		Frames:            len(reference),
+	}, nil
+}
+
+// nearestFrame returns the frame of the track nearest in time, which must be non-empty and in time order.
+func nearestFrame(track []annotation.Frame, time float64) annotation.Frame {
+	i, _ := slices.BinarySearchFunc(track, annotation.Frame{Time: time}, compareTime)
+	if i == len(track) || i > 0 && time-track[i-1].Time <= track[i].Time-time {
+		return track[i-1]
+	}
+	return track[i]
+}
+
+// ratio returns the ratio of the counts, zero if the denominator is.
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// compareTime orders frames by time.
+func compareTime(a, b annotation.Frame) int {
+	return cmp.Compare(a.Time, b.Time)
+}
@@ -0,0 +1,146 @@
+package eval
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/annotation"
+	"github.com/FreibergVlad/go-yinfft/internal/wavio"
+)
+
+type (
+	// FileMetrics are the metrics of a single audio file.
+	FileMetrics struct {
+		Name string // Name of the audio file.
+		Metrics
+	}
+	// Report are the metrics of a dataset.
+	Report struct {
+		Files []FileMetrics // Metrics of each evaluated file, in name order.
+		Mean  Metrics       // Metrics averaged over the files, with the total number of frames.
+	}
+)
+
+// referenceFormats are the readers of reference annotations by file extension, in order of preference.
+var referenceFormats = []struct {
+	extension string
+	read      func(io.Reader) ([]annotation.Frame, error)
+}{
+	{".csv", annotation.ReadMIREX}, // MedleyDB melody annotations.
+	{".pv", annotation.ReadMIR1K},
+	{".txt", annotation.ReadMIREX},
+}
+
+// Run evaluates every WAVE file of the directory against the reference annotation with the same base name: a
+// MedleyDB style .csv, a MIR-1K .pv or a MIREX .txt file, in that order of preference. Audio files without a
+// reference are skipped. See EvaluateFile for how the pitch is detected.
+func Run(dir string, params yinfft.Params, opts Options) (Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".wav") {
+			continue
+		}
+		audioPath := filepath.Join(dir, entry.Name())
+		base := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+		for _, format := range referenceFormats {
+			if _, err := os.Stat(base + format.extension); errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			metrics, err := EvaluateFile(audioPath, base+format.extension, params, opts)
+			if err != nil {
+				return Report{}, fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+			report.Files = append(report.Files, FileMetrics{Name: entry.Name(), Metrics: metrics})
+			break
+		}
+	}
+
+	for _, file := range report.Files {
+		report.Mean.VoicingRecall += file.VoicingRecall
+		report.Mean.VoicingFalseAlarm += file.VoicingFalseAlarm
+		report.Mean.RawPitchAccuracy += file.RawPitchAccuracy
+		report.Mean.RawChromaAccuracy += file.RawChromaAccuracy
+		report.Mean.OverallAccuracy += file.OverallAccuracy
+		report.Mean.Frames += file.Frames
+	}
+	if count := float64(len(report.Files)); count > 0 {
+		report.Mean.VoicingRecall /= count
+		report.Mean.VoicingFalseAlarm /= count
+		report.Mean.RawPitchAccuracy /= count
+		report.Mean.RawChromaAccuracy /= count
+		report.Mean.OverallAccuracy /= count
+	}
+	return report, nil
+}
+
+// EvaluateFile detects the pitch of a WAVE file and evaluates it against a reference annotation in one of the
+// formats of Run. Multichannel audio is mixed down to mono and the sample rate of the params is replaced by that of
+// the file. The frames advance by the HopSize of the params, which should be about the hop of the reference, and are
+// timed at their centers like the reference frames. Results with a confidence below MinConfidence are unvoiced.
+func EvaluateFile(audioPath, referencePath string, params yinfft.Params, opts Options) (Metrics, error) {
+	read := annotation.ReadMIREX
+	for _, format := range referenceFormats {
+		if strings.EqualFold(filepath.Ext(referencePath), format.extension) {
+			read = format.read
+		}
+	}
+	reference, err := readFile(referencePath, read)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("failed to read reference: %w", err)
+	}
+	audio, err := readFile(audioPath, wavio.Read)
+	if err != nil {
+		return Metrics{}, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	samples := make([]float64, len(audio.Data)/audio.Channels)
+	for i := range samples {
+		for channel := range audio.Channels {
+			samples[i] += audio.Data[i*audio.Channels+channel]
+		}
+		samples[i] /= float64(audio.Channels)
+	}
+	params.SampleRate = float64(audio.SampleRate)
+	detector, err := yinfft.New(params)
+	if err != nil {
+		return Metrics{}, err
+	}
+	results, err := detector.DetectAll(samples)
+	if err != nil {
+		return Metrics{}, err
+	}
+
+	center := float64(params.FrameSize) / 2 / params.SampleRate
+	estimate := make([]annotation.Frame, len(results))
+	for i, result := range results {
+		estimate[i] = annotation.Frame{
+			Time:      result.Time + center,
+			Frequency: result.Frequency,
+			Voiced:    result.Voiced && result.Confidence >= opts.MinConfidence,
+		}
+	}
+	return Evaluate(reference, estimate, opts)
+}
+
+// readFile reads the file with the given decoder.
+func readFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer file.Close()
+	return read(bufio.NewReader(file))
+}
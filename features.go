@@ -0,0 +1,69 @@
+package yinfft
+
+import "math"
+
+const (
+	rolloffFraction  = 0.85      // Fraction of the spectral energy below the rolloff frequency, as in Essentia.
+	flatnessMinPower = minNormal // Floor of bin powers, so silent bins don't zero the geometric mean.
+)
+
+// Features holds basic timbre features of the magnitude spectrum of a frame.
+type Features struct {
+	Centroid float64 // Magnitude-weighted mean frequency in Hz, the brightness of the sound.
+	Flatness float64 // Geometric mean of the bin powers divided by their arithmetic mean, one for white noise.
+	Rolloff  float64 // Frequency in Hz below which 85% of the spectral energy lies.
+}
+
+// DetectFeatures detects the fundamental frequency of the frame like Detect, additionally returning the timbre
+// features of its spectrum, computed from the spectrum detection already has. Features are zero for frames without
+// energy or gated by SilenceThresholdDB. The frame is modified in place.
+func (pd *PitchDetector) DetectFeatures(frame []float64) (Result, Features, error) {
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	result, err := pd.detectResult(frame, scratch)
+	if err != nil || pd.silent(result.Level) {
+		return result, Features{}, err
+	}
+	return result, pd.features(scratch.bins), nil
+}
+
+// DetectFeaturesFromSpectrum is DetectFeatures on a magnitude spectrum, see DetectFromSpectrum.
+func (pd *PitchDetector) DetectFeaturesFromSpectrum(spectrum []float64) (Result, Features, error) {
+	frequency, confidence, err := pd.DetectFromSpectrum(spectrum)
+	if err != nil {
+		return Result{}, Features{}, err
+	}
+	return newResult(frequency, confidence, pd.params.SampleRate), pd.features(spectrum), nil
+}
+
+// features computes the timbre features of the magnitude spectrum. The DC bin is excluded, as for detection.
+func (pd *PitchDetector) features(spectrum []float64) Features {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	magnitudeSum, weightedSum, energy, logPowerSum := 0.0, 0.0, 0.0, 0.0
+	for i := 1; i < len(spectrum); i++ {
+		magnitude := spectrum[i]
+		power := magnitude * magnitude
+		magnitudeSum += magnitude
+		weightedSum += magnitude * float64(i) * binFrequency
+		energy += power
+		logPowerSum += math.Log(max(power, flatnessMinPower))
+	}
+	if energy == 0 {
+		return Features{}
+	}
+
+	bins := float64(len(spectrum) - 1)
+	features := Features{
+		Centroid: weightedSum / magnitudeSum,
+		Flatness: math.Exp(logPowerSum/bins) / (energy / bins),
+	}
+	cumulative := 0.0
+	for i := 1; i < len(spectrum); i++ {
+		cumulative += spectrum[i] * spectrum[i]
+		if cumulative >= rolloffFraction*energy {
+			features.Rolloff = float64(i) * binFrequency
+			break
+		}
+	}
+	return features
+}
@@ -2,10 +2,7 @@ module github.com/FreibergVlad/go-yinfft
 
 go 1.23.6
 
-require (
-	github.com/go-audio/wav v1.1.0
-	github.com/mjibson/go-dsp v0.0.0-20180508042940-11479a337f12
-)
+require github.com/go-audio/wav v1.1.0
 
 require (
 	github.com/go-audio/audio v1.0.0 // indirect
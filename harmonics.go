@@ -0,0 +1,63 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft/peaks"
+)
+
+// Harmonic is a partial of a harmonic tone, see Harmonics.
+type Harmonic struct {
+	Number    int     // Harmonic number, 1 for the fundamental.
+	Frequency float64 // Interpolated frequency in Hz, Number*f0 if the harmonic wasn't found.
+	Amplitude float64 // Interpolated magnitude, zero if the harmonic wasn't found.
+}
+
+// Harmonics returns the first n harmonics of the fundamental frequency f0 in the magnitude spectrum, e.g. from
+// PrepareSpectrum, for timbre analysis or to verify octave decisions. Each harmonic is the strongest spectral peak
+// within half the fundamental of its expected frequency, interpolated in the log-magnitude domain. Harmonics above
+// the Nyquist frequency are omitted.
+func (pd *PitchDetector) Harmonics(spectrum []float64, f0 float64, n int) ([]Harmonic, error) {
+	bins := pd.params.FFTSize()/2 + 1
+	if len(spectrum) != bins {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidSpectrumSize, bins, len(spectrum))
+	}
+	if !(f0 > 0) || math.IsInf(f0, 1) {
+		return nil, fmt.Errorf("invalid fundamental frequency: %g Hz, must be positive", f0)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of harmonics: %d, must be positive", n)
+	}
+
+	nyquist := pd.params.analysisSampleRate() / 2
+	buffer := peaks.NewBuffer(bins, 1)
+	harmonics := make([]Harmonic, 0, n)
+	for number := 1; number <= n && float64(number)*f0 <= nyquist; number++ {
+		expected := float64(number) * f0
+		detector, err := peaks.New(peaks.Params{
+			Range:             nyquist,
+			MaxPeaks:          1,
+			MinPosition:       expected - f0/2,
+			MaxPosition:       min(expected+f0/2, nyquist),
+			Threshold:         0,
+			OrderBy:           peaks.OrderByAmplitude,
+			ShouldInterpolate: true,
+			Interpolation:     peaks.InterpolationGaussian,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize peak detection algorithm: %w", err)
+		}
+
+		positions, amplitudes, err := detector.DetectInto(spectrum, &buffer)
+		if err != nil {
+			return nil, fmt.Errorf("peak detection error: %w", err)
+		}
+		harmonic := Harmonic{Number: number, Frequency: expected}
+		if len(positions) > 0 {
+			harmonic.Frequency, harmonic.Amplitude = positions[0], amplitudes[0]
+		}
+		harmonics = append(harmonics, harmonic)
+	}
+	return harmonics, nil
+}
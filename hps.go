@@ -0,0 +1,73 @@
+package yinfft
+
+import (
+	"math"
+	"slices"
+)
+
+// HPSCheck defines how the harmonic product spectrum cross-checks the octave of detected pitches.
+type HPSCheck string
+
+const (
+	HPSCheckNone    HPSCheck = ""        // No cross-check.
+	HPSCheckFlag    HPSCheck = "flag"    // The confidence of pitches an octave off the HPS estimate is halved.
+	HPSCheckCorrect HPSCheck = "correct" // Pitches an octave off are moved to the HPS octave if in range, else flagged.
+)
+
+const (
+	hpsHarmonics       = 5         // Number of spectrum copies multiplied by the harmonic product spectrum.
+	hpsOctaveTolerance = 1.0 / 6   // Maximum deviation from a whole number of octaves in octaves, two semitones.
+	hpsMinMagnitude    = minNormal // Floor of bin magnitudes, so silent bins don't zero the product.
+	hpsFlagPenalty     = 0.5       // Factor of the confidence of flagged pitches.
+	hpsMaxOctaves      = 3         // Maximum octave difference that is flagged or corrected.
+	hpsMinSupport      = 0.05      // Minimum geometric mean of the harmonic magnitudes relative to the spectrum maximum.
+)
+
+// checkHPS cross-checks the period tau with the harmonic product spectrum, the product of the spectrum downsampled by
+// 1 to hpsHarmonics, whose maximum is at the fundamental as all harmonics contribute to it. Each harmonic takes the
+// largest magnitude within half a bin of the fundamental around it, so harmonics of fundamentals between bins aren't
+// missed. If the two estimates differ by whole octaves and the harmonics of the HPS estimate are clearly present, the
+// pitch is flagged or corrected as configured by HPSCheck. Pitches whose harmonics exceed the spectrum aren't checked.
+func (pd *PitchDetector) checkHPS(spectrum, yin []float64, tau, yinMin float64) (float64, float64) {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	minBin := max(1, int(math.Floor(pd.params.MinFrequency/binFrequency)))
+	maxBin := min(int(math.Ceil(pd.params.MaxFrequency/binFrequency)), (len(spectrum)-1)/hpsHarmonics)
+	frequency := pd.params.analysisSampleRate() / tau
+	if minBin > maxBin || frequency/binFrequency > float64(maxBin) {
+		return tau, yinMin
+	}
+
+	// The product is accumulated as a sum of logarithms to avoid underflow.
+	bestBin, bestLogProduct := 0, math.Inf(-1)
+	for bin := minBin; bin <= maxBin; bin++ {
+		logProduct := 0.0
+		for harmonic := 1; harmonic <= hpsHarmonics; harmonic++ {
+			low := max(1, int(math.Round((float64(bin)-0.5)*float64(harmonic))))
+			high := min(len(spectrum)-1, int(math.Round((float64(bin)+0.5)*float64(harmonic))))
+			logProduct += math.Log(max(slices.Max(spectrum[low:high+1]), hpsMinMagnitude))
+		}
+		if logProduct > bestLogProduct {
+			bestBin, bestLogProduct = bin, logProduct
+		}
+	}
+	if math.Exp(bestLogProduct/hpsHarmonics) < hpsMinSupport*slices.Max(spectrum) {
+		return tau, yinMin
+	}
+
+	octaves := math.Log2(frequency / (float64(bestBin) * binFrequency))
+	shift := math.Round(octaves)
+	if shift == 0 || math.Abs(shift) > hpsMaxOctaves || math.Abs(octaves-shift) > hpsOctaveTolerance {
+		return tau, yinMin
+	}
+	if pd.params.Logger != nil {
+		pd.params.Logger.Debug("octave disagreement with the harmonic product spectrum", "octaves", shift)
+	}
+
+	if pd.params.HPSCheck == HPSCheckCorrect {
+		correctedTau := tau * math.Exp2(shift)
+		if index := int(math.Round(correctedTau)); index >= pd.minPeriodSamples && index <= pd.maxPeriodSamples {
+			return correctedTau, yin[index]
+		}
+	}
+	return tau, 1 - (1-yinMin)*hpsFlagPenalty
+}
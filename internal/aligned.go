@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"unsafe"
+)
+
+// CacheLineSize is the assumed size of a CPU cache line in bytes.
+const CacheLineSize = 64
+
+const floatsPerCacheLine = CacheLineSize / 8
+
+// AlignedFloats allocates a zeroed slice of n float64 values starting at a cache line boundary.
+func AlignedFloats(n int) []float64 {
+	buffer := make([]float64, n+floatsPerCacheLine-1)
+	offset := 0
+	if address := uintptr(unsafe.Pointer(unsafe.SliceData(buffer))); address%CacheLineSize != 0 {
+		offset = int(CacheLineSize-address%CacheLineSize) / 8
+	}
+	return buffer[offset : offset+n : offset+n]
+}
+
+// AlignedLength rounds n up to a whole number of cache lines worth of float64 values.
+func AlignedLength(n int) int {
+	return (n + floatsPerCacheLine - 1) / floatsPerCacheLine * floatsPerCacheLine
+}
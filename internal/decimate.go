@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"math"
+)
+
+const (
+	// DecimationCutoff is the passband edge of the decimation low-pass filter relative to the decimated sample rate.
+	DecimationCutoff = 0.4
+	// decimationTapsPerFactor is the length of the decimation filter per unit of the decimation factor.
+	decimationTapsPerFactor = 16
+)
+
+// Decimator low-pass filters and downsamples frames by an integer factor, so a frame covers the same time span
+// with fewer samples.
+type Decimator struct {
+	factor int
+	taps   []float64
+}
+
+// NewDecimator creates a Decimator for the factor, with a Blackman windowed-sinc anti-aliasing filter cutting off
+// at DecimationCutoff of the decimated sample rate.
+func NewDecimator(factor int) *Decimator {
+	n := decimationTapsPerFactor*factor + 1
+	cutoff := DecimationCutoff / float64(factor) // Relative to the input sample rate.
+	taps := make([]float64, n)
+	sum := 0.0
+	for i := range taps {
+		x := float64(i - n/2)
+		sinc := 2 * cutoff
+		if x != 0 {
+			sinc = math.Sin(2*math.Pi*cutoff*x) / (math.Pi * x)
+		}
+		phase := 2 * math.Pi * float64(i) / float64(n-1)
+		taps[i] = sinc * (0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase))
+		sum += taps[i]
+	}
+	for i := range taps {
+		taps[i] /= sum // Unity gain at DC.
+	}
+	return &Decimator{factor: factor, taps: taps}
+}
+
+// Decimate filters the frame and returns every factor-th filtered sample, len(frame)/factor samples. Samples
+// outside the frame are treated as zero.
+func (d *Decimator) Decimate(frame []float64) []float64 {
+	return d.DecimateInto(make([]float64, len(frame)/d.factor), frame)
+}
+
+// DecimateInto is Decimate writing the len(frame)/factor samples into decimated, which is returned.
+func (d *Decimator) DecimateInto(decimated, frame []float64) []float64 {
+	half := len(d.taps) / 2
+	for j := range decimated {
+		center := j * d.factor
+		sum := 0.0
+		for k := max(0, half-center); k < len(d.taps) && center+k-half < len(frame); k++ {
+			sum += d.taps[k] * frame[center+k-half]
+		}
+		decimated[j] = sum
+	}
+	return decimated
+}
@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"math"
+	"math/cmplx"
+	"runtime"
+	"sync"
+)
+
+// ParallelFFTThreshold is the transform size from which FFTReal splits the work across goroutines. Frames of this
+// size are needed for sub-30 Hz detection and a single transform would otherwise take milliseconds on one core.
+const ParallelFFTThreshold = 16384
+
+// fftBackend computes forward FFTs. The backend is selected at build time: the pure Go implementation by default,
+// FFTW with the fftw build tag.
+type fftBackend interface {
+	// Name returns a human-readable name of the backend.
+	Name() string
+	// Plan prepares transforms of length n, e.g. by computing twiddle factors, so later transforms don't pay for it.
+	Plan(n int)
+	// FFT returns the forward FFT of x without modifying it.
+	FFT(x []complex128) []complex128
+	// FFTInPlace replaces x with its forward FFT.
+	FFTInPlace(x []complex128)
+}
+
+type (
+	// parallelScratch holds the buffers of a parallel transform of one length.
+	parallelScratch struct {
+		even, odd   []float64     // Even and odd samples of the signal.
+		oddSpectrum []complex128  // Spectrum of the odd samples.
+		done        chan struct{} // Signals the completion of the transform of the even samples by an FFT worker.
+	}
+	// fftJob is a transform of a real-valued signal computed by an FFT worker.
+	fftJob struct {
+		spectrum []complex128
+		x        []float64
+		done     chan<- struct{}
+	}
+)
+
+var (
+	twiddleCache         sync.Map // Length to []complex128.
+	parallelScratchPools sync.Map // Length to *sync.Pool of *parallelScratch.
+	fftJobs              chan fftJob
+	startFFTWorkers      sync.Once
+)
+
+// FFTBackend returns the name of the FFT backend the package was built with.
+func FFTBackend() string {
+	return backend.Name()
+}
+
+// fftRealInto transforms a real-valued signal with the configured backend, writing the spectrum into a slice of len(x)
+// values. Even-length signals are packed into a complex signal of half the length, even samples as the real and odd
+// samples as the imaginary part, so a single transform of half the size is computed in the first half of the
+// spectrum, which is then unpacked in place into the spectrum of the real signal.
+func fftRealInto(spectrum []complex128, x []float64) {
+	n := len(x)
+	if n < 4 || n%2 != 0 {
+		for i, value := range x {
+			spectrum[i] = complex(value, 0)
+		}
+		backend.FFTInPlace(spectrum)
+		return
+	}
+
+	half := n / 2
+	packed := spectrum[:half]
+	for i := range packed {
+		packed[i] = complex(x[2*i], x[2*i+1])
+	}
+	backend.FFTInPlace(packed)
+
+	// E[k] = (Z[k] + conj(Z[n/2-k])) / 2 and O[k] = (Z[k] - conj(Z[n/2-k])) / 2i are the spectra of the even and
+	// odd samples, combined with a radix-2 butterfly. Bins k and n/2-k are unpacked together, as each needs the
+	// packed values of both.
+	twiddles := butterflyTwiddles(n)
+	unpack := func(k int, z, mirrored complex128) {
+		even, odd := (z+mirrored)/2, (z-mirrored)/complex(0, 2)
+		t := twiddles[k] * odd
+		spectrum[k] = even + t
+		spectrum[k+half] = even - t
+	}
+	for k := 0; k <= half/2; k++ {
+		m := (half - k) % half
+		zk, zm := packed[k], packed[m]
+		unpack(k, zk, cmplx.Conj(zm))
+		if m != k {
+			unpack(m, zm, cmplx.Conj(zk))
+		}
+	}
+}
+
+// PlanFFTReal prepares real-valued transforms of length n, computing all twiddle factors and backend plans FFTReal
+// and FFTRealInto use for it up front, so the first transform doesn't pay for them.
+func PlanFFTReal(n int) {
+	if n >= ParallelFFTThreshold && n%2 == 0 {
+		butterflyTwiddles(n)
+		fftWorkerJobs()
+		pool := parallelScratchPool(n)
+		pool.Put(pool.Get())
+		n /= 2
+	}
+	if n < 4 || n%2 != 0 {
+		backend.Plan(n)
+		return
+	}
+	butterflyTwiddles(n)
+	backend.Plan(n / 2)
+}
+
+// FFTRealInto is FFTReal writing the spectrum into a slice of len(x) values. It doesn't allocate for power-of-two
+// lengths, including parallel transforms, whose buffers are pooled per length.
+func FFTRealInto(spectrum []complex128, x []float64) {
+	n := len(x)
+	if n < ParallelFFTThreshold || n%2 != 0 {
+		fftRealInto(spectrum, x)
+		return
+	}
+
+	pool := parallelScratchPool(n)
+	scratch := pool.Get().(*parallelScratch)
+	defer pool.Put(scratch)
+
+	half := n / 2
+	for i := range half {
+		scratch.even[i], scratch.odd[i] = x[2*i], x[2*i+1]
+	}
+
+	// The even samples are transformed into the first half of the spectrum by an idle FFT worker, or by the caller
+	// once it's done with the odd samples if all workers are busy.
+	evenSpectrum := spectrum[:half]
+	offloaded := false
+	select {
+	case fftWorkerJobs() <- fftJob{spectrum: evenSpectrum, x: scratch.even, done: scratch.done}:
+		offloaded = true
+	default:
+	}
+	fftRealInto(scratch.oddSpectrum, scratch.odd)
+	if offloaded {
+		<-scratch.done
+	} else {
+		fftRealInto(evenSpectrum, scratch.even)
+	}
+
+	// Bin k+half isn't part of the even spectrum, so the butterflies can be computed in place.
+	twiddles := butterflyTwiddles(n)
+	for k := range half {
+		even, t := evenSpectrum[k], twiddles[k]*scratch.oddSpectrum[k]
+		spectrum[k] = even + t
+		spectrum[k+half] = even - t
+	}
+}
+
+// FFTReal returns the FFT of a real-valued signal. Even-length transforms of at least ParallelFFTThreshold points
+// are split into the transforms of the even and odd samples, computed concurrently and combined with a single
+// radix-2 butterfly stage.
+func FFTReal(x []float64) []complex128 {
+	spectrum := make([]complex128, len(x))
+	FFTRealInto(spectrum, x)
+	return spectrum
+}
+
+// FFTRealBatch returns the FFTs of real-valued frames of equal length. Frames are packed in pairs into the real and
+// imaginary parts of a single complex transform whose result is then separated, halving the number of transforms;
+// the transform plan is set up once for the whole batch.
+func FFTRealBatch(frames [][]float64) [][]complex128 {
+	spectra := make([][]complex128, len(frames))
+	if len(frames) == 0 {
+		return spectra
+	}
+
+	n := len(frames[0])
+	backend.Plan(n)
+
+	storage := make([]complex128, len(frames)*n)
+	packed := make([]complex128, n)
+	for i := 0; i < len(frames); i += 2 {
+		spectra[i] = storage[i*n : (i+1)*n]
+		if i+1 == len(frames) {
+			copy(spectra[i], FFTReal(frames[i]))
+			break
+		}
+		spectra[i+1] = storage[(i+1)*n : (i+2)*n]
+
+		for j := range packed {
+			packed[j] = complex(frames[i][j], frames[i+1][j])
+		}
+		transformed := backend.FFT(packed)
+
+		// X[k] = (Z[k] + conj(Z[n-k])) / 2 and Y[k] = (Z[k] - conj(Z[n-k])) / 2i.
+		for k := range n {
+			z, mirrored := transformed[k], cmplx.Conj(transformed[(n-k)%n])
+			spectra[i][k] = (z + mirrored) / 2
+			spectra[i+1][k] = (z - mirrored) / complex(0, 2)
+		}
+	}
+
+	return spectra
+}
+
+// parallelScratchPool returns the pool of buffers of parallel transforms of length n.
+func parallelScratchPool(n int) *sync.Pool {
+	if pool, ok := parallelScratchPools.Load(n); ok {
+		return pool.(*sync.Pool)
+	}
+	pool := &sync.Pool{New: func() any {
+		return &parallelScratch{
+			even:        make([]float64, n/2),
+			odd:         make([]float64, n/2),
+			oddSpectrum: make([]complex128, n/2),
+			done:        make(chan struct{}, 1),
+		}
+	}}
+	actual, _ := parallelScratchPools.LoadOrStore(n, pool)
+	return actual.(*sync.Pool)
+}
+
+// fftWorkerJobs returns the channel of the FFT workers, starting one per processor on first use. The channel is
+// unbuffered, so a job is only accepted by an idle worker.
+func fftWorkerJobs() chan<- fftJob {
+	startFFTWorkers.Do(func() {
+		fftJobs = make(chan fftJob)
+		for range runtime.GOMAXPROCS(0) {
+			go func() {
+				for job := range fftJobs {
+					fftRealInto(job.spectrum, job.x)
+					job.done <- struct{}{}
+				}
+			}()
+		}
+	})
+	return fftJobs
+}
+
+// butterflyTwiddles returns the shared twiddle factors exp(-2*pi*i*k/n) for k < n/2.
+func butterflyTwiddles(n int) []complex128 {
+	if twiddles, ok := twiddleCache.Load(n); ok {
+		return twiddles.([]complex128)
+	}
+	twiddles := make([]complex128, n/2)
+	for k := range twiddles {
+		sin, cos := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		twiddles[k] = complex(cos, sin)
+	}
+	actual, _ := twiddleCache.LoadOrStore(n, twiddles)
+	return actual.([]complex128)
+}
@@ -0,0 +1,107 @@
+//go:build fftw && cgo
+
+package internal
+
+/*
+#cgo LDFLAGS: -lfftw3 -lm
+#include <fftw3.h>
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+var backend fftBackend = newFFTWBackend()
+
+type (
+	// fftwBackend computes transforms with FFTW, which is several times faster than pure Go at large sizes. It is
+	// enabled with the fftw build tag and requires libfftw3.
+	fftwBackend struct {
+		mu    sync.Mutex // FFTW planning isn't thread-safe, plan execution is.
+		plans map[int]*fftwPlan
+	}
+	// fftwPlan is a plan for one transform length along with its input and output buffers. Buffers are pooled, so
+	// concurrent transforms of the same length don't share them.
+	fftwPlan struct {
+		plan    C.fftw_plan
+		n       int
+		buffers sync.Pool
+	}
+	fftwBuffers struct {
+		in, out *C.fftw_complex
+	}
+)
+
+func newFFTWBackend() *fftwBackend {
+	return &fftwBackend{plans: map[int]*fftwPlan{}}
+}
+
+func (*fftwBackend) Name() string {
+	return "fftw"
+}
+
+func (b *fftwBackend) Plan(n int) {
+	b.plan(n)
+}
+
+func (b *fftwBackend) FFT(x []complex128) []complex128 {
+	if len(x) == 0 {
+		return nil
+	}
+	result := append([]complex128(nil), x...)
+	b.FFTInPlace(result)
+	return result
+}
+
+func (b *fftwBackend) FFTInPlace(x []complex128) {
+	if len(x) == 0 {
+		return
+	}
+
+	plan := b.plan(len(x))
+	buffers := plan.buffers.Get().(*fftwBuffers)
+	defer plan.buffers.Put(buffers)
+
+	in := unsafe.Slice((*complex128)(unsafe.Pointer(buffers.in)), len(x))
+	out := unsafe.Slice((*complex128)(unsafe.Pointer(buffers.out)), len(x))
+	copy(in, x)
+	C.fftw_execute_dft(plan.plan, buffers.in, buffers.out)
+	copy(x, out)
+}
+
+func (b *fftwBackend) plan(n int) *fftwPlan {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if plan, ok := b.plans[n]; ok {
+		return plan
+	}
+
+	buffers := newFFTWBuffers(n)
+	plan := &fftwPlan{
+		plan: C.fftw_plan_dft_1d(C.int(n), buffers.in, buffers.out, C.FFTW_FORWARD, C.FFTW_ESTIMATE),
+		n:    n,
+	}
+	plan.buffers.New = func() any { return newFFTWBuffers(n) }
+	plan.buffers.Put(buffers)
+	b.plans[n] = plan
+	return plan
+}
+
+// newFFTWBuffers allocates buffers with fftw_malloc, so they have the alignment the plan was created with. The
+// buffers are released with fftw_free once the pool drops them.
+func newFFTWBuffers(n int) *fftwBuffers {
+	size := C.size_t(n) * C.size_t(unsafe.Sizeof(complex128(0)))
+	buffers := &fftwBuffers{
+		in:  (*C.fftw_complex)(C.fftw_malloc(size)),
+		out: (*C.fftw_complex)(C.fftw_malloc(size)),
+	}
+	runtime.SetFinalizer(buffers, func(buffers *fftwBuffers) {
+		C.fftw_free(unsafe.Pointer(buffers.in))
+		C.fftw_free(unsafe.Pointer(buffers.out))
+	})
+	return buffers
+}
@@ -0,0 +1,156 @@
+//go:build !fftw || !cgo
+
+package internal
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+)
+
+var backend fftBackend = &pureGoBackend{}
+
+type (
+	// pureGoBackend computes transforms with a dependency-free iterative radix-2 FFT. Other lengths are handled
+	// with Bluestein's algorithm on top of it.
+	pureGoBackend struct {
+		radix2Plans    sync.Map // Length to *radix2Plan.
+		bluesteinPlans sync.Map // Length to *bluesteinPlan.
+	}
+	// radix2Plan holds the precomputed twiddle factors and bit-reversal permutation for one power-of-two length.
+	radix2Plan struct {
+		twiddles []complex128
+		reversed []int
+	}
+	// bluesteinPlan holds the chirp and the transformed convolution kernel for one non-power-of-two length.
+	bluesteinPlan struct {
+		chirp  []complex128
+		kernel []complex128
+		radix2 *radix2Plan
+	}
+)
+
+func (*pureGoBackend) Name() string {
+	return "pure-go"
+}
+
+func (b *pureGoBackend) Plan(n int) {
+	if isPowerOfTwo(n) {
+		b.radix2Plan(n)
+	} else if n > 1 {
+		b.bluesteinPlan(n)
+	}
+}
+
+func (b *pureGoBackend) FFT(x []complex128) []complex128 {
+	result := append([]complex128(nil), x...)
+	b.FFTInPlace(result)
+	return result
+}
+
+func (b *pureGoBackend) FFTInPlace(x []complex128) {
+	switch {
+	case len(x) <= 1:
+	case isPowerOfTwo(len(x)):
+		b.radix2Plan(len(x)).transform(x)
+	default:
+		b.bluesteinPlan(len(x)).transform(x)
+	}
+}
+
+func (b *pureGoBackend) radix2Plan(n int) *radix2Plan {
+	if plan, ok := b.radix2Plans.Load(n); ok {
+		return plan.(*radix2Plan)
+	}
+
+	plan := &radix2Plan{twiddles: make([]complex128, n/2), reversed: make([]int, n)}
+	for k := range plan.twiddles {
+		sin, cos := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		plan.twiddles[k] = complex(cos, sin)
+	}
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range plan.reversed {
+		plan.reversed[i] = int(bits.Reverse64(uint64(i)) >> shift)
+	}
+
+	actual, _ := b.radix2Plans.LoadOrStore(n, plan)
+	return actual.(*radix2Plan)
+}
+
+func (b *pureGoBackend) bluesteinPlan(n int) *bluesteinPlan {
+	if plan, ok := b.bluesteinPlans.Load(n); ok {
+		return plan.(*bluesteinPlan)
+	}
+
+	m := 1 << bits.Len(uint(2*n-2))
+	plan := &bluesteinPlan{
+		chirp:  make([]complex128, n),
+		kernel: make([]complex128, m),
+		radix2: b.radix2Plan(m),
+	}
+	for k := range plan.chirp {
+		// k^2 mod 2n keeps the angle small for large k.
+		sin, cos := math.Sincos(-math.Pi * float64((k*k)%(2*n)) / float64(n))
+		plan.chirp[k] = complex(cos, sin)
+	}
+	plan.kernel[0] = conj(plan.chirp[0])
+	for k := 1; k < n; k++ {
+		plan.kernel[k] = conj(plan.chirp[k])
+		plan.kernel[m-k] = conj(plan.chirp[k])
+	}
+	plan.radix2.transform(plan.kernel)
+
+	actual, _ := b.bluesteinPlans.LoadOrStore(n, plan)
+	return actual.(*bluesteinPlan)
+}
+
+// transform computes the FFT of x in place using the iterative radix-2 Cooley-Tukey algorithm.
+func (p *radix2Plan) transform(x []complex128) {
+	n := len(x)
+	for i, j := range p.reversed {
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half, stride := size/2, n/size
+		for start := 0; start < n; start += size {
+			for k := range half {
+				t := p.twiddles[k*stride] * x[start+k+half]
+				x[start+k+half] = x[start+k] - t
+				x[start+k] += t
+			}
+		}
+	}
+}
+
+// transform computes the FFT of x in place by expressing it as a convolution with a chirp, evaluated with
+// power-of-two transforms.
+func (p *bluesteinPlan) transform(x []complex128) {
+	n, m := len(x), len(p.kernel)
+	padded := make([]complex128, m)
+	for k := range n {
+		padded[k] = x[k] * p.chirp[k]
+	}
+
+	p.radix2.transform(padded)
+	for k := range padded {
+		padded[k] = conj(padded[k] * p.kernel[k])
+	}
+	// The inverse transform is computed as conj(FFT(conj(x))) / m.
+	p.radix2.transform(padded)
+
+	scale := complex(1/float64(m), 0)
+	for k := range n {
+		x[k] = conj(padded[k]) * scale * p.chirp[k]
+	}
+}
+
+func conj(c complex128) complex128 {
+	return complex(real(c), -imag(c))
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+	"testing"
+)
+
+// dft returns the discrete Fourier transform of a signal computed from its definition.
+func dft(x []complex128) []complex128 {
+	n := len(x)
+	// exp(-2*pi*i*m/n) for every m, indexed by i*k mod n to avoid accumulating angle errors.
+	roots := make([]complex128, n)
+	for m := range roots {
+		sin, cos := math.Sincos(-2 * math.Pi * float64(m) / float64(n))
+		roots[m] = complex(cos, sin)
+	}
+	spectrum := make([]complex128, n)
+	for k := range spectrum {
+		m := 0
+		for _, value := range x {
+			spectrum[k] += value * roots[m]
+			if m += k; m >= n {
+				m -= n
+			}
+		}
+	}
+	return spectrum
+}
+
+// complexSignal returns the real-valued signal as a complex one.
+func complexSignal(x []float64) []complex128 {
+	signal := make([]complex128, len(x))
+	for i, value := range x {
+		signal[i] = complex(value, 0)
+	}
+	return signal
+}
+
+// fftTestSizes are the transform sizes checked against the DFT: all sizes up to 32, odd, prime and other
+// non-power-of-two sizes handled by Bluestein's algorithm, and parallel sizes.
+var fftTestSizes = func() []int {
+	var sizes []int
+	for n := 1; n <= 32; n++ {
+		sizes = append(sizes, n)
+	}
+	return append(sizes, 37, 97, 100, 127, 441, 480, 960, 1000, 1024, 4096,
+		ParallelFFTThreshold, ParallelFFTThreshold+6)
+}()
+
+func TestFFT(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range fftTestSizes {
+		if n >= ParallelFFTThreshold {
+			// The backend has no separate path for large transforms, only FFTReal does.
+			continue
+		}
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			t.Parallel()
+
+			x := make([]complex128, n)
+			for i, value := range testSignal(2 * n)[:n] {
+				x[i] = complex(value, math.Cos(0.11*float64(i*i)))
+			}
+			want := dft(x)
+
+			got := backend.FFT(x)
+			if err := maxError(got, want); err > 1e-9 {
+				t.Errorf("FFT differs from the DFT by %g", err)
+			}
+			backend.FFTInPlace(x)
+			if err := maxError(x, want); err > 1e-9 {
+				t.Errorf("FFTInPlace differs from the DFT by %g", err)
+			}
+		})
+	}
+}
+
+func TestFFTReal(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range fftTestSizes {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			t.Parallel()
+
+			x := testSignal(n)
+			want := dft(complexSignal(x))
+
+			if err := maxError(FFTReal(x), want); err > 1e-9 {
+				t.Errorf("FFTReal differs from the DFT by %g", err)
+			}
+			spectrum := make([]complex128, n)
+			FFTRealInto(spectrum, x)
+			if err := maxError(spectrum, want); err > 1e-9 {
+				t.Errorf("FFTRealInto differs from the DFT by %g", err)
+			}
+		})
+	}
+}
+
+func TestFFTRealBatch(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{1, 2, 8, 13, 960, 1024} {
+		for _, count := range []int{0, 1, 2, 3} {
+			frames := make([][]float64, count)
+			for i := range frames {
+				frames[i] = testSignal(n + i)[i:]
+			}
+
+			spectra := FFTRealBatch(frames)
+			if len(spectra) != count {
+				t.Fatalf("incorrect number of spectra for %d frames of %d, got %d", count, n, len(spectra))
+			}
+			for i, frame := range frames {
+				if err := maxError(spectra[i], dft(complexSignal(frame))); err > 1e-9 {
+					t.Errorf("spectrum %d of %d frames of %d differs from the DFT by %g", i, count, n, err)
+				}
+			}
+		}
+	}
+}
+
+// dftBin returns bin k of the discrete Fourier transform of a real-valued signal.
+func dftBin(x []float64, k int) complex128 {
+	var bin complex128
+	for i, value := range x {
+		// i*k mod n keeps the angle small for large signals.
+		sin, cos := math.Sincos(-2 * math.Pi * float64((i*k)%len(x)) / float64(len(x)))
+		bin += complex(value*cos, value*sin)
+	}
+	return bin
+}
+
+// testSignal returns n samples of a deterministic signal with energy in all bins.
+func testSignal(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = math.Sin(0.37*float64(i)) + 0.5*math.Cos(1.91*float64(i)*float64(i%7)) + float64(i%3) - 1
+	}
+	return x
+}
+
+// maxError returns the largest distance between the spectra, relative to the largest magnitude of the reference.
+func maxError(got, want []complex128) float64 {
+	scale, err := 1.0, 0.0
+	for k := range want {
+		scale = max(scale, cmplx.Abs(want[k]))
+		err = max(err, cmplx.Abs(got[k]-want[k]))
+	}
+	return err / scale
+}
+
+func TestFFTReal_Parallel(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{ParallelFFTThreshold, 2 * ParallelFFTThreshold, ParallelFFTThreshold + 6} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			t.Parallel()
+
+			x := testSignal(n)
+			want := make([]complex128, n)
+			fftRealInto(want, x)
+			got := FFTReal(x)
+			if err := maxError(got, want); err > 1e-12 {
+				t.Errorf("parallel transform differs from the sequential one by %g", err)
+			}
+
+			// A definition-based DFT of all bins would take too long, so a few of them are spot-checked.
+			for _, k := range []int{0, 1, 2, 777, n/2 - 1, n / 2, n/2 + 1, n - 1} {
+				if want := dftBin(x, k); cmplx.Abs(got[k]-want) > 1e-9*float64(n) {
+					t.Errorf("incorrect bin %d, got %v, want %v", k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFFTRealInto_ParallelConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const goroutines, n = 8, ParallelFFTThreshold
+	x := testSignal(n)
+	want := FFTReal(x)
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spectrum := make([]complex128, n)
+			for range 4 {
+				FFTRealInto(spectrum, x)
+				if err := maxError(spectrum, want); err != 0 {
+					t.Errorf("concurrent transform differs by %g", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestFFTRealInto_Allocations isn't parallel, as allocations are counted process-wide.
+func TestFFTRealInto_Allocations(t *testing.T) {
+	for _, n := range []int{1024, ParallelFFTThreshold, 4 * ParallelFFTThreshold} {
+		x := testSignal(n)
+		spectrum := make([]complex128, n)
+		PlanFFTReal(n)
+		if allocs := testing.AllocsPerRun(10, func() { FFTRealInto(spectrum, x) }); allocs != 0 {
+			t.Errorf("FFTRealInto of %d points allocates %v times per run, want 0", n, allocs)
+		}
+	}
+}
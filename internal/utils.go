@@ -3,8 +3,6 @@ package internal
 import (
 	"math"
 	"math/cmplx"
-
-	"github.com/mjibson/go-dsp/fft"
 )
 
 const CurveSize = 34
@@ -49,36 +47,143 @@ func ComputeSpectrumWeights(frameSize int, sampleRate float64, curve WeightingCu
 	return weights
 }
 
-// CartesianToPolar converts a slice of complex numbers to polar coordinates,
-// returning the magnitude and phase as separate slices.
-func CartesianToPolar(complex []complex128) (magnitude []float64, phase []float64) {
-	magnitude, phase = make([]float64, len(complex)), make([]float64, len(complex))
+// HarmonicSupport returns the fraction of the given harmonics of f0 that are present in the magnitude spectrum.
+// A harmonic is present when the magnitude around its expected bin reaches minRelativeMagnitude of the spectrum
+// maximum. Harmonics above the last bin are not taken into account.
+func HarmonicSupport(spectrum []float64, binFrequency, f0 float64, harmonics []int, minRelativeMagnitude float64) float64 {
+	maxMagnitude := 0.0
+	for _, magnitude := range spectrum {
+		maxMagnitude = max(maxMagnitude, magnitude)
+	}
+	if maxMagnitude == 0 || f0 <= 0 {
+		return 0
+	}
+
+	present, considered := 0, 0
+	for _, harmonic := range harmonics {
+		bin := int(math.Round(float64(harmonic) * f0 / binFrequency))
+		if bin >= len(spectrum) {
+			continue
+		}
+		considered++
 
-	for i, cnum := range complex {
-		magnitude[i] = math.Sqrt(math.Pow(real(cnum), 2) + math.Pow(imag(cnum), 2))
-		phase[i] = math.Atan2(imag(cnum), real(cnum))
+		magnitude := spectrum[bin]
+		if bin > 0 {
+			magnitude = max(magnitude, spectrum[bin-1])
+		}
+		if bin+1 < len(spectrum) {
+			magnitude = max(magnitude, spectrum[bin+1])
+		}
+		if magnitude >= minRelativeMagnitude*maxMagnitude {
+			present++
+		}
 	}
 
-	return
+	if considered == 0 {
+		return 0
+	}
+	return float64(present) / float64(considered)
 }
 
-// PrepareSpectrum applies a Hann window to the input frame and computes the FFT, making the result suitable for
-// pitch detection with the YIN algorithm.
-func PrepareSpectrum(frame []float64) []float64 {
-	applyHannWindow(frame)
+// PrepareSpectrum applies the window coefficients to the input frame, zero-pads it to fftSize samples and computes the
+// FFT, making the result suitable for pitch detection with the YIN algorithm. Windowed samples and spectrum bins with
+// a magnitude below flushThreshold are flushed to zero, which avoids slow denormal arithmetic on decaying signals;
+// zero disables flushing.
+func PrepareSpectrum(frame, window []float64, fftSize int, flushThreshold float64) []float64 {
+	ApplyWindow(frame, window)
+	FlushToZero(frame, flushThreshold)
 
-	complexSpectrum := fft.FFTReal(frame)
+	complexSpectrum := FFTReal(zeroPad(frame, fftSize))
 
 	spectrum := make([]float64, len(complexSpectrum)/2+1)
 	for i := range spectrum {
 		spectrum[i] = cmplx.Abs(complexSpectrum[i])
 	}
+	FlushToZero(spectrum, flushThreshold)
 
 	return spectrum
 }
 
-func applyHannWindow(frame []float64) {
-	for i := range frame {
-		frame[i] *= 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(len(frame)-1)))
+// PrepareSpectrumInto is PrepareSpectrum writing into caller-provided memory, see WindowFrame and SpectrumInto. It
+// returns the sum of the squared samples of the frame before windowing.
+func PrepareSpectrumInto(
+	spectrum []float64, work []complex128, padded, frame, window []float64, flushThreshold float64,
+) float64 {
+	squares := WindowFrame(frame, window, flushThreshold)
+	SpectrumInto(spectrum, work, padded, frame, flushThreshold)
+	return squares
+}
+
+// WindowFrame applies the window coefficients to the frame in place and flushes windowed samples below
+// flushThreshold to zero, like PrepareSpectrum. It returns the sum of the squared samples before windowing, measured
+// in the windowing pass, e.g. for a silence gate.
+func WindowFrame(frame, window []float64, flushThreshold float64) float64 {
+	squares := ApplyWindowSquares(frame, window)
+	FlushToZero(frame, flushThreshold)
+	return squares
+}
+
+// SpectrumInto computes the magnitude spectrum of a windowed frame into caller-provided memory: the frame is
+// zero-padded into padded, which may start with the frame itself, transformed into work, both of the FFT size, and
+// its magnitudes are written to spectrum.
+func SpectrumInto(spectrum []float64, work []complex128, padded, frame []float64, flushThreshold float64) {
+	copy(padded, frame)
+	clear(padded[len(frame):])
+	FFTRealInto(work, padded)
+
+	for i := range spectrum {
+		spectrum[i] = cmplx.Abs(work[i])
+	}
+	FlushToZero(spectrum, flushThreshold)
+}
+
+// Spectra is the batched variant of SpectrumInto for windowed frames of equal length, transforming the frames with
+// FFTRealBatch and storing all spectra in one contiguous allocation.
+func Spectra(frames [][]float64, fftSize int, flushThreshold float64) [][]float64 {
+	spectra := make([][]float64, len(frames))
+	if len(frames) == 0 {
+		return spectra
+	}
+
+	padded := frames
+	if fftSize > len(frames[0]) {
+		padded = make([][]float64, len(frames))
+		for i, frame := range frames {
+			padded[i] = zeroPad(frame, fftSize)
+		}
+	}
+
+	spectrumLen := fftSize/2 + 1
+	storage := make([]float64, len(frames)*spectrumLen)
+	for i, complexSpectrum := range FFTRealBatch(padded) {
+		spectra[i] = storage[i*spectrumLen : (i+1)*spectrumLen]
+		for j := range spectra[i] {
+			spectra[i][j] = cmplx.Abs(complexSpectrum[j])
+		}
+		FlushToZero(spectra[i], flushThreshold)
+	}
+
+	return spectra
+}
+
+// zeroPad returns the frame extended with zeros to size samples, or the frame itself if it's not shorter.
+func zeroPad(frame []float64, size int) []float64 {
+	if len(frame) >= size {
+		return frame
+	}
+	padded := make([]float64, size)
+	copy(padded, frame)
+	return padded
+}
+
+// FlushToZero sets values with a magnitude below threshold to zero in place.
+func FlushToZero(values []float64, threshold float64) {
+	if threshold <= 0 {
+		return
+	}
+	for i, value := range values {
+		if math.Abs(value) < threshold {
+			values[i] = 0
+		}
 	}
 }
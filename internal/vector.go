@@ -0,0 +1,19 @@
+package internal
+
+// MultiplyInPlace multiplies x by w element-wise in place, using SIMD instructions where available. w must have at
+// least len(x) values.
+func MultiplyInPlace(x, w []float64) {
+	multiplyInPlace(x, w[:len(x)])
+}
+
+// MultiplyInPlaceSquares is MultiplyInPlace returning the sum of the squares of x before the multiplication, which
+// measures the power of a frame in the pass windowing it. w must have at least len(x) values.
+func MultiplyInPlaceSquares(x, w []float64) float64 {
+	return multiplyInPlaceSquares(x, w[:len(x)])
+}
+
+// WeightedSquares stores x[i]*x[i]*w[i] in dst[i] for every index of dst and returns their sum, using SIMD
+// instructions where available. x and w must have at least len(dst) values.
+func WeightedSquares(dst, x, w []float64) float64 {
+	return weightedSquares(dst, x[:len(dst)], w[:len(dst)])
+}
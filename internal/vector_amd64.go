@@ -0,0 +1,18 @@
+//go:build !purego && !(fftw && cgo)
+
+package internal
+
+// multiplyInPlace is implemented with SSE2, which every amd64 CPU supports.
+//
+//go:noescape
+func multiplyInPlace(x, w []float64)
+
+// multiplyInPlaceSquares is implemented with SSE2, which every amd64 CPU supports.
+//
+//go:noescape
+func multiplyInPlaceSquares(x, w []float64) float64
+
+// weightedSquares is implemented with SSE2, which every amd64 CPU supports.
+//
+//go:noescape
+func weightedSquares(dst, x, w []float64) float64
@@ -0,0 +1,117 @@
+//go:build !purego && !(fftw && cgo)
+
+#include "textflag.h"
+
+// func multiplyInPlace(x, w []float64)
+TEXT ·multiplyInPlace(SB), NOSPLIT, $0-48
+	MOVQ x_base+0(FP), DI
+	MOVQ x_len+8(FP), CX
+	MOVQ w_base+24(FP), SI
+	XORQ AX, AX
+	MOVQ CX, BX
+	ANDQ $-4, BX
+
+quads:
+	CMPQ AX, BX
+	JGE  single
+	MOVUPD (DI)(AX*8), X0
+	MOVUPD 16(DI)(AX*8), X1
+	MOVUPD (SI)(AX*8), X2
+	MOVUPD 16(SI)(AX*8), X3
+	MULPD  X2, X0
+	MULPD  X3, X1
+	MOVUPD X0, (DI)(AX*8)
+	MOVUPD X1, 16(DI)(AX*8)
+	ADDQ   $4, AX
+	JMP    quads
+
+single:
+	CMPQ  AX, CX
+	JGE   done
+	MOVSD (DI)(AX*8), X0
+	MULSD (SI)(AX*8), X0
+	MOVSD X0, (DI)(AX*8)
+	INCQ  AX
+	JMP   single
+
+done:
+	RET
+
+// func multiplyInPlaceSquares(x, w []float64) float64
+TEXT ·multiplyInPlaceSquares(SB), NOSPLIT, $0-56
+	MOVQ  x_base+0(FP), DI
+	MOVQ  x_len+8(FP), CX
+	MOVQ  w_base+24(FP), SI
+	XORPD X4, X4 // Even and odd lane sums.
+	XORQ  AX, AX
+	MOVQ  CX, BX
+	ANDQ  $-2, BX
+
+squarePairs:
+	CMPQ   AX, BX
+	JGE    squareTail
+	MOVUPD (DI)(AX*8), X0
+	MOVAPD X0, X1
+	MULPD  X1, X1
+	ADDPD  X1, X4
+	MOVUPD (SI)(AX*8), X2
+	MULPD  X2, X0
+	MOVUPD X0, (DI)(AX*8)
+	ADDQ   $2, AX
+	JMP    squarePairs
+
+squareTail:
+	CMPQ  AX, CX
+	JGE   squareSum
+	MOVSD (DI)(AX*8), X0
+	MOVSD X0, X1
+	MULSD X1, X1
+	ADDSD X1, X4
+	MULSD (SI)(AX*8), X0
+	MOVSD X0, (DI)(AX*8)
+
+squareSum:
+	MOVAPD   X4, X5
+	UNPCKHPD X5, X5
+	ADDSD    X5, X4
+	MOVSD    X4, ret+48(FP)
+	RET
+
+// func weightedSquares(dst, x, w []float64) float64
+TEXT ·weightedSquares(SB), NOSPLIT, $0-80
+	MOVQ  dst_base+0(FP), DI
+	MOVQ  dst_len+8(FP), CX
+	MOVQ  x_base+24(FP), SI
+	MOVQ  w_base+48(FP), DX
+	XORPD X4, X4 // Even and odd lane sums.
+	XORQ  AX, AX
+	MOVQ  CX, BX
+	ANDQ  $-2, BX
+
+pairs:
+	CMPQ   AX, BX
+	JGE    tail
+	MOVUPD (SI)(AX*8), X0
+	MULPD  X0, X0
+	MOVUPD (DX)(AX*8), X1
+	MULPD  X1, X0
+	MOVUPD X0, (DI)(AX*8)
+	ADDPD  X0, X4
+	ADDQ   $2, AX
+	JMP    pairs
+
+tail:
+	CMPQ  AX, CX
+	JGE   sum
+	MOVSD (SI)(AX*8), X0
+	MULSD X0, X0
+	MULSD (DX)(AX*8), X0
+	MOVSD X0, (DI)(AX*8)
+	ADDSD X0, X4
+
+sum:
+	MOVAPD   X4, X5
+	UNPCKHPD X5, X5
+	ADDSD    X5, X4
+	MOVSD    X4, ret+72(FP)
+	RET
@@ -0,0 +1,43 @@
+//go:build !amd64 || purego || (fftw && cgo)
+
+package internal
+
+func multiplyInPlace(x, w []float64) {
+	for i := range x {
+		x[i] *= w[i]
+	}
+}
+
+func multiplyInPlaceSquares(x, w []float64) float64 {
+	// Two partial sums, matching the lanes of the SIMD implementations.
+	even, odd := 0.0, 0.0
+	i := 0
+	for ; i+1 < len(x); i += 2 {
+		even += x[i] * x[i]
+		odd += x[i+1] * x[i+1]
+		x[i] *= w[i]
+		x[i+1] *= w[i+1]
+	}
+	if i < len(x) {
+		even += x[i] * x[i]
+		x[i] *= w[i]
+	}
+	return even + odd
+}
+
+func weightedSquares(dst, x, w []float64) float64 {
+	// Two partial sums, matching the lanes of the SIMD implementations.
+	even, odd := 0.0, 0.0
+	i := 0
+	for ; i+1 < len(dst); i += 2 {
+		dst[i] = x[i] * x[i] * w[i]
+		dst[i+1] = x[i+1] * x[i+1] * w[i+1]
+		even += dst[i]
+		odd += dst[i+1]
+	}
+	if i < len(dst) {
+		dst[i] = x[i] * x[i] * w[i]
+		even += dst[i]
+	}
+	return even + odd
+}
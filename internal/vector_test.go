@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVector(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 1, 2, 3, 4, 5, 7, 8, 1025} {
+		x, w := make([]float64, n), make([]float64, n+3)
+		for i := range x {
+			x[i] = math.Sin(float64(i)) + 0.5
+		}
+		for i := range w {
+			w[i] = float64(i%5) + 0.25
+		}
+
+		dst := make([]float64, n)
+		sum, wantSum := WeightedSquares(dst, x, w), 0.0
+		for i := range dst {
+			want := x[i] * x[i] * w[i]
+			wantSum += want
+			if dst[i] != want {
+				t.Errorf("incorrect weighted square %d of %d, got %g, want %g", i, n, dst[i], want)
+			}
+		}
+		if math.Abs(sum-wantSum) > 1e-12*math.Max(1, wantSum) {
+			t.Errorf("incorrect sum of %d, got %g, want %g", n, sum, wantSum)
+		}
+
+		product := append([]float64(nil), x...)
+		MultiplyInPlace(product, w)
+		for i := range product {
+			if want := x[i] * w[i]; product[i] != want {
+				t.Errorf("incorrect product %d of %d, got %g, want %g", i, n, product[i], want)
+			}
+		}
+
+		squared := append([]float64(nil), x...)
+		squares, wantSquares := MultiplyInPlaceSquares(squared, w), 0.0
+		for i := range squared {
+			wantSquares += x[i] * x[i]
+			if squared[i] != product[i] {
+				t.Errorf("incorrect product %d of %d with squares, got %g, want %g", i, n, squared[i], product[i])
+			}
+		}
+		if math.Abs(squares-wantSquares) > 1e-12*math.Max(1, wantSquares) {
+			t.Errorf("incorrect sum of squares of %d, got %g, want %g", n, squares, wantSquares)
+		}
+	}
+}
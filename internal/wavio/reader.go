@@ -0,0 +1,122 @@
+package wavio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const formatExtensible = 0xFFFE
+
+// Audio is decoded WAVE file content.
+type Audio struct {
+	SampleRate int       // Sampling rate in Hz.
+	Channels   int       // Number of interleaved channels.
+	Data       []float64 // Interleaved samples normalized to [-1, 1].
+}
+
+// Read decodes an integer PCM or IEEE float WAVE file.
+func Read(r io.Reader) (*Audio, error) {
+	var riffHeader [12]byte
+	if _, err := io.ReadFull(r, riffHeader[:]); err != nil {
+		return nil, fmt.Errorf("failed to read RIFF header: %w", err)
+	}
+	if string(riffHeader[:4]) != "RIFF" || string(riffHeader[8:]) != "WAVE" {
+		return nil, errors.New("not a RIFF WAVE file")
+	}
+
+	var (
+		format, channels, bitsPerSample uint16
+		sampleRate                      uint32
+		hasFormat                       bool
+	)
+
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return nil, fmt.Errorf("failed to find data chunk: %w", err)
+		}
+		chunkID, chunkSize := string(chunkHeader[:4]), binary.LittleEndian.Uint32(chunkHeader[4:])
+
+		switch chunkID {
+		case "fmt ":
+			chunk := make([]byte, chunkSize)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			if len(chunk) < 16 {
+				return nil, errors.New("fmt chunk is too short")
+			}
+			format = binary.LittleEndian.Uint16(chunk[0:])
+			channels = binary.LittleEndian.Uint16(chunk[2:])
+			sampleRate = binary.LittleEndian.Uint32(chunk[4:])
+			bitsPerSample = binary.LittleEndian.Uint16(chunk[14:])
+			if format == formatExtensible && len(chunk) >= 26 {
+				format = binary.LittleEndian.Uint16(chunk[24:])
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, errors.New("data chunk precedes fmt chunk")
+			}
+			if channels == 0 {
+				return nil, errors.New("invalid channel count 0")
+			}
+			chunk := make([]byte, chunkSize)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("failed to read data chunk: %w", err)
+			}
+			data, err := decodeSamples(chunk, format, bitsPerSample)
+			if err != nil {
+				return nil, err
+			}
+			return &Audio{SampleRate: int(sampleRate), Channels: int(channels), Data: data}, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(chunkSize+chunkSize%2)); err != nil {
+				return nil, fmt.Errorf("failed to skip %q chunk: %w", chunkID, err)
+			}
+		}
+	}
+}
+
+func decodeSamples(chunk []byte, format, bitsPerSample uint16) ([]float64, error) {
+	bytesPerSample := int(bitsPerSample / 8)
+	if bytesPerSample == 0 {
+		return nil, fmt.Errorf("unsupported bits per sample: %d", bitsPerSample)
+	}
+	data := make([]float64, len(chunk)/bytesPerSample)
+
+	switch {
+	case format == formatPCM && bitsPerSample == 8:
+		for i := range data {
+			data[i] = (float64(chunk[i]) - 128) / 128
+		}
+	case format == formatPCM && bitsPerSample == 16:
+		for i := range data {
+			data[i] = float64(int16(binary.LittleEndian.Uint16(chunk[2*i:]))) / (1 << 15)
+		}
+	case format == formatPCM && bitsPerSample == 24:
+		for i := range data {
+			sample := int32(chunk[3*i]) | int32(chunk[3*i+1])<<8 | int32(int8(chunk[3*i+2]))<<16
+			data[i] = float64(sample) / (1 << 23)
+		}
+	case format == formatPCM && bitsPerSample == 32:
+		for i := range data {
+			data[i] = float64(int32(binary.LittleEndian.Uint32(chunk[4*i:]))) / (1 << 31)
+		}
+	case format == formatIEEEFloat && bitsPerSample == 32:
+		for i := range data {
+			data[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(chunk[4*i:])))
+		}
+	case format == formatIEEEFloat && bitsPerSample == 64:
+		for i := range data {
+			data[i] = math.Float64frombits(binary.LittleEndian.Uint64(chunk[8*i:]))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported WAVE format %d with %d bits per sample", format, bitsPerSample)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,86 @@
+// Package wavio reads and writes RIFF WAVE files without third-party dependencies.
+package wavio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	formatPCM       = 1
+	formatIEEEFloat = 3
+	headerSize      = 44
+)
+
+// Writer writes mono 32-bit float samples to a WAVE file. The chunk sizes are patched in when the writer is closed,
+// so the underlying writer must support seeking.
+type Writer struct {
+	w          io.WriteSeeker
+	sampleRate int
+	samples    int
+	buffer     []byte
+}
+
+// NewWriter writes a WAVE header with the given sample rate to w and returns a Writer appending samples to it.
+func NewWriter(w io.WriteSeeker, sampleRate int) (*Writer, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("sample rate must be positive, got %d", sampleRate)
+	}
+	writer := &Writer{w: w, sampleRate: sampleRate}
+	if err := writer.writeHeader(); err != nil {
+		return nil, fmt.Errorf("failed to write WAVE header: %w", err)
+	}
+	return writer, nil
+}
+
+// Write appends samples to the file.
+func (w *Writer) Write(samples []float64) error {
+	w.buffer = w.buffer[:0]
+	for _, sample := range samples {
+		w.buffer = binary.LittleEndian.AppendUint32(w.buffer, math.Float32bits(float32(sample)))
+	}
+	if _, err := w.w.Write(w.buffer); err != nil {
+		return err
+	}
+	w.samples += len(samples)
+	return nil
+}
+
+// Close patches the chunk sizes in the header. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := w.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	_, err := w.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (w *Writer) writeHeader() error {
+	dataSize := uint32(w.samples * 4)
+	if uint64(w.samples)*4 > math.MaxUint32-headerSize {
+		return errors.New("WAVE file size exceeds 4 GiB")
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, headerSize-8+dataSize)
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16)
+	header = binary.LittleEndian.AppendUint16(header, formatIEEEFloat)
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint32(header, uint32(w.sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(w.sampleRate*4))
+	header = binary.LittleEndian.AppendUint16(header, 4)
+	header = binary.LittleEndian.AppendUint16(header, 32)
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, dataSize)
+
+	_, err := w.w.Write(header)
+	return err
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Names of the registered windows.
+const (
+	HannWindow           = "hann"            // Default window of spectrum preparation.
+	HammingWindow        = "hamming"         // Hamming window, with a lower first sidelobe than Hann.
+	BlackmanWindow       = "blackman"        // Exact-ish three-term Blackman window.
+	BlackmanHarrisWindow = "blackman-harris" // Four-term Blackman-Harris window with -92 dB sidelobes.
+	FlatTopWindow        = "flat-top"        // Flat-top window for accurate peak amplitudes.
+)
+
+type windowKey struct {
+	name string
+	beta float64 // Shape parameter of the Kaiser window.
+	size int
+}
+
+var (
+	windowFunctions = map[string]func(i, n int) float64{
+		HannWindow:           cosineWindow(0.5, 0.5),
+		HammingWindow:        cosineWindow(0.54, 0.46),
+		BlackmanWindow:       cosineWindow(0.42, 0.5, 0.08),
+		BlackmanHarrisWindow: cosineWindow(0.35875, 0.48829, 0.14128, 0.01168),
+		FlatTopWindow:        cosineWindow(0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368),
+	}
+	windowCache sync.Map
+)
+
+// Window returns the coefficients of the named window of the given size. The coefficients are computed once per
+// (window, size) and shared by all callers, so the returned slice must not be modified.
+func Window(name string, size int) ([]float64, error) {
+	key := windowKey{name: name, size: size}
+	if coefficients, ok := windowCache.Load(key); ok {
+		return coefficients.([]float64), nil
+	}
+
+	function, ok := windowFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown window: %s", name)
+	}
+	return cacheWindow(key, function), nil
+}
+
+// CachedKaiserWindow returns the coefficients of the Kaiser window with shape parameter beta of the given size,
+// cached like Window, so the returned slice must not be modified. The cache is never evicted, so it's meant for a
+// fixed set of betas, e.g. the default one.
+func CachedKaiserWindow(beta float64, size int) []float64 {
+	key := windowKey{name: "kaiser", beta: beta, size: size}
+	if coefficients, ok := windowCache.Load(key); ok {
+		return coefficients.([]float64)
+	}
+	return cacheWindow(key, KaiserWindow(beta))
+}
+
+// cacheWindow computes the coefficients of the window function and stores them in the cache, returning the cached
+// coefficients if another caller stored them first.
+func cacheWindow(key windowKey, function func(i, n int) float64) []float64 {
+	actual, _ := windowCache.LoadOrStore(key, WindowCoefficients(function, key.size))
+	return actual.([]float64)
+}
+
+// ApplyWindowSquares is ApplyWindow returning the sum of the squared samples of the frame before windowing, measured
+// in the same pass.
+func ApplyWindowSquares(frame, coefficients []float64) float64 {
+	return MultiplyInPlaceSquares(frame, coefficients)
+}
+
+// ApplyWindow multiplies the frame by the window coefficients in place.
+func ApplyWindow(frame, coefficients []float64) {
+	MultiplyInPlace(frame, coefficients)
+}
+
+// WindowCoefficients returns the coefficients of the window function of the given size. Unlike Window, they aren't
+// cached, so the caller owns them.
+func WindowCoefficients(function func(i, n int) float64, size int) []float64 {
+	coefficients := make([]float64, size)
+	for i := range coefficients {
+		coefficients[i] = function(i, size)
+	}
+	return coefficients
+}
+
+// NamedWindow returns the function of the named window, or false if no such window is registered.
+func NamedWindow(name string) (func(i, n int) float64, bool) {
+	function, ok := windowFunctions[name]
+	return function, ok
+}
+
+// KaiserWindow returns the function of the Kaiser window with shape parameter beta, which trades main lobe width
+// for sidelobe level as beta grows.
+func KaiserWindow(beta float64) func(i, n int) float64 {
+	return func(i, n int) float64 {
+		if n == 1 {
+			return 1
+		}
+		x := 2*float64(i)/float64(n-1) - 1
+		return besselI0(beta*math.Sqrt(max(0, 1-x*x))) / besselI0(beta)
+	}
+}
+
+// cosineWindow returns the symmetric generalized cosine window with the given coefficients, alternating in sign.
+func cosineWindow(coefficients ...float64) func(i, n int) float64 {
+	return func(i, n int) float64 {
+		if n == 1 {
+			return 1
+		}
+		phase := 2 * math.Pi * float64(i) / float64(n-1)
+		value, sign := 0.0, 1.0
+		for k, coefficient := range coefficients {
+			value += sign * coefficient * math.Cos(float64(k)*phase)
+			sign = -sign
+		}
+		return value
+	}
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order zero, summing its power series until
+// the terms become negligible.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; term > sum*1e-16; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+	}
+	return sum
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	for name, function := range windowFunctions {
+		for _, size := range []int{1, 2, 7, 1024} {
+			coefficients, err := Window(name, size)
+			if err != nil {
+				t.Fatalf("error getting %s window of %d: %v", name, size, err)
+			}
+			for i, coefficient := range coefficients {
+				if want := function(i, size); coefficient != want {
+					t.Errorf("incorrect coefficient %d of %s window of %d, got %g, want %g", i, name, size, coefficient,
+						want)
+				}
+			}
+
+			cached, err := Window(name, size)
+			if err != nil {
+				t.Fatalf("error getting cached %s window of %d: %v", name, size, err)
+			}
+			if len(cached) != size || &cached[0] != &coefficients[0] {
+				t.Errorf("%s window of %d isn't shared between calls", name, size)
+			}
+		}
+	}
+
+	if _, err := Window("triangle", 16); err == nil {
+		t.Error("expected an error for an unknown window")
+	}
+
+	kaiser := CachedKaiserWindow(8.6, 64)
+	if want := WindowCoefficients(KaiserWindow(8.6), 64); !slices.Equal(kaiser, want) {
+		t.Errorf("incorrect cached Kaiser window, got %v, want %v", kaiser, want)
+	}
+	if &CachedKaiserWindow(8.6, 64)[0] != &kaiser[0] || &CachedKaiserWindow(5, 64)[0] == &kaiser[0] {
+		t.Error("Kaiser windows aren't cached per shape parameter")
+	}
+}
+
+func TestWindow_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	// A size no other test uses, so every goroutine races to compute the window.
+	const goroutines, size = 16, 4099
+	windows := make([][]float64, goroutines)
+	var wg sync.WaitGroup
+	for i := range windows {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			windows[i], _ = Window(BlackmanHarrisWindow, size)
+		}()
+	}
+	wg.Wait()
+
+	for i, window := range windows {
+		if len(window) != size || &window[0] != &windows[0][0] {
+			t.Fatalf("window %d isn't the shared window", i)
+		}
+	}
+}
+
+func TestApplyWindow_KeepsWindow(t *testing.T) {
+	t.Parallel()
+
+	window, err := Window(HannWindow, 2048)
+	if err != nil {
+		t.Fatalf("error getting window: %v", err)
+	}
+	want := slices.Clone(window)
+
+	frame := make([]float64, len(window))
+	for i := range frame {
+		frame[i] = float64(i)
+	}
+	ApplyWindow(frame, window)
+	PrepareSpectrum(frame, window, len(frame), 0)
+
+	if !slices.Equal(window, want) {
+		t.Error("applying the window modified the shared coefficients")
+	}
+}
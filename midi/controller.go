@@ -0,0 +1,194 @@
+package midi
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// DefaultMPEBendRange is the pitch bend range of MPE member channels in semitones, the default of the MPE
+// specification.
+const DefaultMPEBendRange = 48.0
+
+type (
+	// Sender sends MIDI messages, e.g. to an output port of a MIDI library. The message must not be retained after
+	// Send returns.
+	Sender interface {
+		Send(message []byte) error
+	}
+	// ControllerParams configure a Controller.
+	ControllerParams struct {
+		Mapper        music.NoteMapper // Maps frequencies to notes, e.g. with a reference A4 or transposition.
+		MinConfidence float64          // Minimum detection confidence of frames playing notes.
+		OnsetFrames   int              // Consecutive frames near a new note before it is played, at least one.
+		ReleaseFrames int              // Consecutive frames without pitch before the note is released, at least one.
+		Hysteresis    float64          // Cents beyond the midpoint between notes a pitch must reach to change the note.
+		Velocity      int              // Velocity of the notes, DefaultVelocity is used if zero.
+		// Pitch bend range in semitones, DefaultBendRange is used if zero, or DefaultMPEBendRange with MPE.
+		BendRange float64
+		Channel   int // Channel of the notes without MPE, from 0 to 15.
+		// Number of member channels of the MPE lower zone, from 1 to 15, zero disables MPE. Notes rotate through
+		// channels 1 to MPEChannels and channel 0 is the manager channel.
+		MPEChannels int
+	}
+	// Controller turns the detection results of a monophonic stream into MIDI notes, following the deviation of the
+	// pitch from each note with pitch bend, e.g. to play a synthesizer from a voice or guitar. With MPE, each note is
+	// played on its own member channel, so the bend of a note doesn't affect the release of the previous one. A
+	// Controller is not safe for concurrent use.
+	Controller struct {
+		sender  Sender
+		params  ControllerParams
+		note    int // Playing note, -1 if none.
+		channel int // Channel of the playing note.
+		bend    int // Last pitch bend value sent on the channel of the playing note.
+		// Note the pitch approaches and the number of consecutive frames near it, before it's played.
+		candidate, candidateFrames int
+		silentFrames               int
+		nextChannel                int // Next MPE member channel, counted from zero.
+	}
+)
+
+// DefaultControllerParams are controller params suited to frames 512 samples apart at 44.1 kHz.
+var DefaultControllerParams = ControllerParams{
+	MinConfidence: 0.8,
+	OnsetFrames:   2,
+	ReleaseFrames: 4,
+	Hysteresis:    20,
+}
+
+// NewController creates a Controller and sends the configuration of its channels: the pitch bend range, and with MPE
+// the zone configuration.
+func NewController(sender Sender, params ControllerParams) (*Controller, error) {
+	if params.Velocity == 0 {
+		params.Velocity = DefaultVelocity
+	}
+	if params.BendRange == 0 {
+		params.BendRange = DefaultBendRange
+		if params.MPEChannels > 0 {
+			params.BendRange = DefaultMPEBendRange
+		}
+	}
+	if params.OnsetFrames < 1 || params.ReleaseFrames < 1 {
+		return nil, fmt.Errorf(
+			"invalid 'OnsetFrames' and 'ReleaseFrames': expected positive values, got %d and %d",
+			params.OnsetFrames, params.ReleaseFrames,
+		)
+	}
+	if !(params.Hysteresis >= 0 && params.Hysteresis < 50) {
+		return nil, fmt.Errorf("invalid 'Hysteresis': expected value in range [0, 50), got %v", params.Hysteresis)
+	}
+	if params.Velocity < 0 || params.Velocity > 127 {
+		return nil, fmt.Errorf("invalid 'Velocity': expected value in range [1, 127], got %d", params.Velocity)
+	}
+	if !(params.BendRange > 0 && params.BendRange < 128) {
+		return nil, fmt.Errorf("invalid 'BendRange': expected value in range (0, 128), got %v", params.BendRange)
+	}
+	if params.Channel < 0 || params.Channel > 15 {
+		return nil, fmt.Errorf("invalid 'Channel': expected value in range [0, 15], got %d", params.Channel)
+	}
+	if params.MPEChannels < 0 || params.MPEChannels > 15 {
+		return nil, fmt.Errorf("invalid 'MPEChannels': expected value in range [0, 15], got %d", params.MPEChannels)
+	}
+
+	controller := &Controller{sender: sender, params: params, note: -1, candidate: -1}
+	var messages [][]byte
+	if params.MPEChannels > 0 {
+		messages = rpnMessages(0, rpnMPEConfiguration, byte(params.MPEChannels), 0)
+		for channel := 1; channel <= params.MPEChannels; channel++ {
+			messages = append(messages, bendRangeMessages(channel, params.BendRange)...)
+		}
+	} else {
+		messages = bendRangeMessages(params.Channel, params.BendRange)
+	}
+	if err := controller.send(messages...); err != nil {
+		return nil, err
+	}
+	return controller, nil
+}
+
+// Update plays the detection result of the next frame of the stream: a confident pitch starts a note once it stays
+// near one for OnsetFrames, bends the playing note while it stays within Hysteresis cents past the midpoint to the
+// adjacent notes, and changes the note once it moves further. The note is released after ReleaseFrames frames
+// without a confident pitch.
+func (c *Controller) Update(result yinfft.Result) error {
+	if !result.Voiced || result.Confidence < c.params.MinConfidence {
+		c.candidate, c.candidateFrames = -1, 0
+		if c.silentFrames++; c.silentFrames >= c.params.ReleaseFrames {
+			return c.Release()
+		}
+		return nil
+	}
+	c.silentFrames = 0
+
+	mapped := c.params.Mapper.Note(result.Frequency)
+	pitch := float64(mapped.MIDI) + mapped.Cents/100
+	if c.note >= 0 {
+		cents := 100 * (pitch - float64(c.note))
+		if math.Abs(cents) <= 50+c.params.Hysteresis {
+			c.candidate, c.candidateFrames = -1, 0
+			return c.bendTo(cents)
+		}
+	}
+
+	if mapped.MIDI != c.candidate {
+		c.candidate, c.candidateFrames = mapped.MIDI, 0
+	}
+	if c.candidateFrames++; c.candidateFrames < c.params.OnsetFrames {
+		return nil
+	}
+	if mapped.MIDI < 0 || mapped.MIDI > 127 {
+		return nil
+	}
+	if err := c.Release(); err != nil {
+		return err
+	}
+	c.candidate, c.candidateFrames = -1, 0
+	return c.play(mapped.MIDI, mapped.Cents)
+}
+
+// Release releases the playing note, if any, e.g. when the stream ends.
+func (c *Controller) Release() error {
+	if c.note < 0 {
+		return nil
+	}
+	note := c.note
+	c.note = -1
+	return c.send([]byte{byte(statusNoteOff | c.channel), byte(note), 0})
+}
+
+// play bends the channel of the next note and plays it.
+func (c *Controller) play(note int, cents float64) error {
+	c.channel = c.params.Channel
+	if c.params.MPEChannels > 0 {
+		c.channel = 1 + c.nextChannel
+		c.nextChannel = (c.nextChannel + 1) % c.params.MPEChannels
+	}
+	c.bend = pitchBend(cents, c.params.BendRange)
+	c.note = note
+	return c.send(
+		pitchBendMessage(c.channel, c.bend),
+		[]byte{byte(statusNoteOn | c.channel), byte(note), byte(c.params.Velocity)},
+	)
+}
+
+// bendTo bends the playing note to the deviation in cents, unless the channel already has that bend.
+func (c *Controller) bendTo(cents float64) error {
+	bend := pitchBend(cents, c.params.BendRange)
+	if bend == c.bend {
+		return nil
+	}
+	c.bend = bend
+	return c.send(pitchBendMessage(c.channel, bend))
+}
+
+// send sends the messages in order, stopping at the first error.
+func (c *Controller) send(messages ...[]byte) error {
+	for _, message := range messages {
+		if err := c.sender.Send(message); err != nil {
+			return fmt.Errorf("failed to send MIDI message: %w", err)
+		}
+	}
+	return nil
+}
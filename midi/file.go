@@ -0,0 +1,166 @@
+package midi
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+type (
+	// FileOptions configure WriteFile.
+	FileOptions struct {
+		Title     string  // Optional name of the track.
+		Tempo     float64 // Tempo in quarter notes per minute, DefaultTempo is used if zero.
+		Division  int     // Ticks per quarter note, DefaultDivision is used if zero.
+		Channel   int     // Channel of the notes, from 0 to 15.
+		Velocity  int     // Velocity of the notes, DefaultVelocity is used if zero.
+		BendRange float64 // Pitch bend range in semitones, DefaultBendRange is used if zero.
+	}
+	// timedMessage is a message of the track at an absolute time in ticks.
+	timedMessage struct {
+		tick    int
+		order   int // Order of messages at the same tick: note offs, then pitch bends, then note ons.
+		message []byte
+	}
+)
+
+// Order of messages at the same tick, releasing notes before bending the channel for the next one.
+const (
+	orderNoteOff = iota
+	orderPitchBend
+	orderNoteOn
+)
+
+// WriteFile writes the events as a Standard MIDI File of format 0, with the tempo of the options. Onsets and
+// durations in seconds are converted to ticks at that tempo, so the file plays back in the timing of the recording.
+// The cents of each note are sent as a pitch bend before it, after the bend range is set at the start of the track.
+// Pitch bend applies to the whole channel, so overlapping notes share the bend of the later one.
+func WriteFile(w io.Writer, events []music.NoteEvent, opts FileOptions) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+
+	ticksPerSecond := opts.Tempo / 60 * float64(opts.Division)
+	var messages []timedMessage
+	for _, event := range events {
+		if event.MIDI < 0 || event.MIDI > 127 {
+			return fmt.Errorf("invalid note: MIDI number %d out of range [0, 127]", event.MIDI)
+		}
+		onset := int(math.Round(event.Onset * ticksPerSecond))
+		end := max(int(math.Round(event.End()*ticksPerSecond)), onset+1)
+		messages = append(messages,
+			timedMessage{onset, orderPitchBend, pitchBendMessage(opts.Channel, pitchBend(event.Cents, opts.BendRange))},
+			timedMessage{onset, orderNoteOn, []byte{byte(statusNoteOn | opts.Channel), byte(event.MIDI), byte(opts.Velocity)}},
+			timedMessage{end, orderNoteOff, []byte{byte(statusNoteOff | opts.Channel), byte(event.MIDI), 0}},
+		)
+	}
+	slices.SortStableFunc(messages, func(a, b timedMessage) int {
+		return cmp.Or(cmp.Compare(a.tick, b.tick), cmp.Compare(a.order, b.order))
+	})
+
+	var track bytes.Buffer
+	if opts.Title != "" {
+		writeMeta(&track, 0x03, []byte(opts.Title))
+	}
+	microseconds := int(math.Round(60e6 / opts.Tempo))
+	writeMeta(&track, 0x51, []byte{byte(microseconds >> 16), byte(microseconds >> 8), byte(microseconds)})
+	for _, message := range bendRangeMessages(opts.Channel, opts.BendRange) {
+		writeEvent(&track, 0, message)
+	}
+
+	tick, bend := 0, -1
+	for _, message := range messages {
+		if message.order == orderPitchBend {
+			// Skip bends that don't change the channel.
+			value := int(message.message[1]) | int(message.message[2])<<7
+			if value == bend {
+				continue
+			}
+			bend = value
+		}
+		writeEvent(&track, message.tick-tick, message.message)
+		tick = message.tick
+	}
+	writeMeta(&track, 0x2F, nil)
+
+	header := make([]byte, 0, 22)
+	header = append(header, "MThd"...)
+	header = binary.BigEndian.AppendUint32(header, 6)
+	header = binary.BigEndian.AppendUint16(header, 0) // Format 0, a single track.
+	header = binary.BigEndian.AppendUint16(header, 1)
+	header = binary.BigEndian.AppendUint16(header, uint16(opts.Division))
+	header = append(header, "MTrk"...)
+	header = binary.BigEndian.AppendUint32(header, uint32(track.Len()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(track.Bytes())
+	return err
+}
+
+// withDefaults returns the options with zero fields replaced by their defaults, validating the result.
+func (o FileOptions) withDefaults() (FileOptions, error) {
+	if o.Tempo == 0 {
+		o.Tempo = DefaultTempo
+	}
+	if o.Division == 0 {
+		o.Division = DefaultDivision
+	}
+	if o.Velocity == 0 {
+		o.Velocity = DefaultVelocity
+	}
+	if o.BendRange == 0 {
+		o.BendRange = DefaultBendRange
+	}
+
+	// The tempo is stored as microseconds per quarter note in 24 bits.
+	if !(o.Tempo >= 60e6/(1<<24-1)) || math.IsInf(o.Tempo, 1) {
+		return o, fmt.Errorf("invalid 'Tempo': expected value of at least 3.58, got %v", o.Tempo)
+	}
+	if o.Division < 0 || o.Division > 0x7FFF {
+		return o, fmt.Errorf("invalid 'Division': expected value in range [1, 32767], got %d", o.Division)
+	}
+	if o.Channel < 0 || o.Channel > 15 {
+		return o, fmt.Errorf("invalid 'Channel': expected value in range [0, 15], got %d", o.Channel)
+	}
+	if o.Velocity < 0 || o.Velocity > 127 {
+		return o, fmt.Errorf("invalid 'Velocity': expected value in range [1, 127], got %d", o.Velocity)
+	}
+	if !(o.BendRange > 0 && o.BendRange < 128) {
+		return o, fmt.Errorf("invalid 'BendRange': expected value in range (0, 128), got %v", o.BendRange)
+	}
+	return o, nil
+}
+
+// writeEvent writes a track event after the delta time in ticks.
+func writeEvent(track *bytes.Buffer, delta int, message []byte) {
+	writeVariableLength(track, delta)
+	track.Write(message)
+}
+
+// writeMeta writes a meta event of the given type at the time of the previous event.
+func writeMeta(track *bytes.Buffer, kind byte, data []byte) {
+	writeEvent(track, 0, []byte{0xFF, kind})
+	writeVariableLength(track, len(data))
+	track.Write(data)
+}
+
+// writeVariableLength writes a variable-length quantity, seven bits per byte with the most significant first and the
+// high bit set on all but the last byte.
+func writeVariableLength(track *bytes.Buffer, value int) {
+	var encoded [4]byte
+	i := len(encoded) - 1
+	encoded[i] = byte(value & 0x7F)
+	for value >>= 7; value > 0 && i > 0; value >>= 7 {
+		i--
+		encoded[i] = byte(value&0x7F) | 0x80
+	}
+	track.Write(encoded[i:])
+}
@@ -0,0 +1,67 @@
+// Package midi exports transcribed melodies as Standard MIDI Files and plays detected pitch as live MIDI, with pitch
+// bend carrying the deviation of the notes from the equal-tempered pitches.
+package midi
+
+import "math"
+
+// Default values used for zero FileOptions fields.
+const (
+	DefaultTempo     = 120.0 // Quarter notes per minute.
+	DefaultDivision  = 480   // Ticks per quarter note.
+	DefaultVelocity  = 100
+	DefaultBendRange = 2.0 // Semitones, the default of General MIDI synthesizers.
+)
+
+// Status bytes of the channel messages, to be combined with the channel.
+const (
+	statusNoteOff       = 0x80
+	statusNoteOn        = 0x90
+	statusControlChange = 0xB0
+	statusPitchBend     = 0xE0
+)
+
+// Controllers setting registered parameters.
+const (
+	controllerDataEntry     = 6
+	controllerDataEntryFine = 38
+	controllerRPNFine       = 100
+	controllerRPN           = 101
+)
+
+// pitchBend returns the 14-bit pitch bend value of the deviation in cents for the bend range in semitones, clamped to
+// the range.
+func pitchBend(cents, bendRange float64) int {
+	value := 8192 + math.Round(cents/100/bendRange*8192)
+	return int(min(max(value, 0), 16383))
+}
+
+// Registered parameter numbers.
+const (
+	rpnPitchBendRange   = 0
+	rpnMPEConfiguration = 6
+)
+
+// bendRangeMessages returns the control changes setting the pitch bend range of the channel in semitones.
+func bendRangeMessages(channel int, bendRange float64) [][]byte {
+	semitones := math.Floor(bendRange)
+	return rpnMessages(channel, rpnPitchBendRange, byte(semitones), byte(math.Round((bendRange-semitones)*100)))
+}
+
+// rpnMessages returns the control changes setting the registered parameter of the channel to the coarse and fine
+// values, followed by the null RPN so later data entry doesn't change it.
+func rpnMessages(channel, parameter int, coarse, fine byte) [][]byte {
+	status := byte(statusControlChange | channel)
+	return [][]byte{
+		{status, controllerRPN, 0},
+		{status, controllerRPNFine, byte(parameter)},
+		{status, controllerDataEntry, coarse},
+		{status, controllerDataEntryFine, fine},
+		{status, controllerRPN, 127},
+		{status, controllerRPNFine, 127},
+	}
+}
+
+// pitchBendMessage returns the pitch bend message of the 14-bit value.
+func pitchBendMessage(channel, value int) []byte {
+	return []byte{byte(statusPitchBend | channel), byte(value & 0x7F), byte(value >> 7)}
+}
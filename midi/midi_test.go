@@ -0,0 +1,219 @@
+package midi_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/midi"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// trackEvent is an event of a parsed track at an absolute time in ticks.
+type trackEvent struct {
+	tick    int
+	message []byte
+}
+
+// parseFile parses a Standard MIDI File of a single track without running status.
+func parseFile(t *testing.T, data []byte) (division int, events []trackEvent) {
+	t.Helper()
+
+	if len(data) < 22 || string(data[:4]) != "MThd" || string(data[14:18]) != "MTrk" {
+		t.Fatalf("invalid file header: % x", data[:min(len(data), 22)])
+	}
+	if format, tracks := binary.BigEndian.Uint16(data[8:]), binary.BigEndian.Uint16(data[10:]); format != 0 || tracks != 1 {
+		t.Fatalf("incorrect format %d with %d tracks, want format 0 with 1 track", format, tracks)
+	}
+	division = int(binary.BigEndian.Uint16(data[12:]))
+	track := data[22:]
+	if length := int(binary.BigEndian.Uint32(data[18:])); length != len(track) {
+		t.Fatalf("incorrect track length, got %d, want %d", length, len(track))
+	}
+
+	readVariableLength := func() int {
+		value := 0
+		for {
+			b := track[0]
+			track = track[1:]
+			value = value<<7 | int(b&0x7F)
+			if b&0x80 == 0 {
+				return value
+			}
+		}
+	}
+	tick := 0
+	for len(track) > 0 {
+		tick += readVariableLength()
+		var length int
+		switch status := track[0]; {
+		case status == 0xFF:
+			kind := track[1]
+			track = track[2:]
+			dataLength := readVariableLength()
+			events = append(events, trackEvent{tick, append([]byte{0xFF, kind}, track[:dataLength]...)})
+			track = track[dataLength:]
+			continue
+		case status&0xF0 == 0xC0 || status&0xF0 == 0xD0:
+			length = 2
+		default:
+			length = 3
+		}
+		events = append(events, trackEvent{tick, track[:length]})
+		track = track[length:]
+	}
+	return division, events
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	events := []music.NoteEvent{
+		{Onset: 0, Duration: 0.5, MIDI: 69, Cents: 50},
+		{Onset: 0.5, Duration: 0.25, MIDI: 71, Cents: 50},
+		{Onset: 1, Duration: 1, MIDI: 72, Cents: -100},
+	}
+	var buffer bytes.Buffer
+	if err := midi.WriteFile(&buffer, events, midi.FileOptions{Title: "Melody", Tempo: 60, Channel: 1}); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	division, parsed := parseFile(t, buffer.Bytes())
+	if division != midi.DefaultDivision {
+		t.Errorf("incorrect division, got %d, want %d", division, midi.DefaultDivision)
+	}
+
+	want := []trackEvent{
+		{0, append([]byte{0xFF, 0x03}, "Melody"...)},
+		{0, []byte{0xFF, 0x51, 0x0F, 0x42, 0x40}}, // One second per quarter note.
+		{0, []byte{0xB1, 101, 0}},
+		{0, []byte{0xB1, 100, 0}},
+		{0, []byte{0xB1, 6, 2}},
+		{0, []byte{0xB1, 38, 0}},
+		{0, []byte{0xB1, 101, 127}},
+		{0, []byte{0xB1, 100, 127}},
+		{0, []byte{0xE1, 0x00, 0x50}}, // A quarter of the bend range up, 10240.
+		{0, []byte{0x91, 69, 100}},
+		{240, []byte{0x81, 69, 0}},
+		{240, []byte{0x91, 71, 100}}, // Same bend as the previous note.
+		{360, []byte{0x81, 71, 0}},
+		{480, []byte{0xE1, 0x00, 0x20}}, // Half of the bend range down, 4096.
+		{480, []byte{0x91, 72, 100}},
+		{960, []byte{0x81, 72, 0}},
+		{960, []byte{0xFF, 0x2F}},
+	}
+	if !slices.EqualFunc(parsed, want, func(a, b trackEvent) bool {
+		return a.tick == b.tick && bytes.Equal(a.message, b.message)
+	}) {
+		t.Errorf("incorrect track events\ngot  %v\nwant %v", parsed, want)
+	}
+}
+
+func TestWriteFile_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		events []music.NoteEvent
+		opts   midi.FileOptions
+	}{
+		{"negative tempo", nil, midi.FileOptions{Tempo: -1}},
+		{"channel out of range", nil, midi.FileOptions{Channel: 16}},
+		{"velocity out of range", nil, midi.FileOptions{Velocity: 128}},
+		{"negative bend range", nil, midi.FileOptions{BendRange: -2}},
+		{"note out of range", []music.NoteEvent{{Duration: 1, MIDI: 128}}, midi.FileOptions{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := midi.WriteFile(&bytes.Buffer{}, test.events, test.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// recorder is a midi.Sender recording the sent messages.
+type recorder struct {
+	messages [][]byte
+}
+
+func (r *recorder) Send(message []byte) error {
+	r.messages = append(r.messages, slices.Clone(message))
+	return nil
+}
+
+func TestController(t *testing.T) {
+	t.Parallel()
+
+	voiced := func(frequency float64) yinfft.Result {
+		return yinfft.Result{Frequency: frequency, Confidence: 1, Voiced: true}
+	}
+	frames := []yinfft.Result{
+		voiced(440), voiced(440), // Note on after two frames.
+		voiced(music.MIDIToFrequency(69.25, 0)), // Bend up a quarter semitone.
+		voiced(music.MIDIToFrequency(69.65, 0)), // Within the hysteresis, bend further.
+		voiced(493.88), voiced(493.88),          // New note, on the next member channel with MPE.
+		{}, {}, {}, {}, // Released after four frames.
+	}
+
+	tests := []struct {
+		name   string
+		params midi.ControllerParams
+		want   [][]byte
+	}{
+		{
+			name:   "single channel",
+			params: midi.ControllerParams{OnsetFrames: 2, ReleaseFrames: 4, Hysteresis: 20, Channel: 2},
+			want: [][]byte{
+				{0xB2, 101, 0}, {0xB2, 100, 0}, {0xB2, 6, 2}, {0xB2, 38, 0}, {0xB2, 101, 127}, {0xB2, 100, 127},
+				{0xE2, 0x00, 0x40}, {0x92, 69, 100},
+				{0xE2, 0x00, 0x48},
+				{0xE2, 0x66, 0x54},
+				{0x82, 69, 0}, {0xE2, 0x00, 0x40}, {0x92, 71, 100},
+				{0x82, 71, 0},
+			},
+		},
+		{
+			name:   "MPE",
+			params: midi.ControllerParams{OnsetFrames: 2, ReleaseFrames: 4, Hysteresis: 20, MPEChannels: 2},
+			want: [][]byte{
+				{0xB0, 101, 0}, {0xB0, 100, 6}, {0xB0, 6, 2}, {0xB0, 38, 0}, {0xB0, 101, 127}, {0xB0, 100, 127},
+				{0xB1, 101, 0}, {0xB1, 100, 0}, {0xB1, 6, 48}, {0xB1, 38, 0}, {0xB1, 101, 127}, {0xB1, 100, 127},
+				{0xB2, 101, 0}, {0xB2, 100, 0}, {0xB2, 6, 48}, {0xB2, 38, 0}, {0xB2, 101, 127}, {0xB2, 100, 127},
+				{0xE1, 0x00, 0x40}, {0x91, 69, 100},
+				{0xE1, 0x2B, 0x40},
+				{0xE1, 0x6F, 0x40},
+				{0x81, 69, 0}, {0xE2, 0x00, 0x40}, {0x92, 71, 100},
+				{0x82, 71, 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			sender := &recorder{}
+			controller, err := midi.NewController(sender, test.params)
+			if err != nil {
+				t.Fatalf("error creating controller: %v", err)
+			}
+			for i, frame := range frames {
+				if err := controller.Update(frame); err != nil {
+					t.Fatalf("error updating frame %d: %v", i, err)
+				}
+			}
+			if !slices.EqualFunc(sender.messages, test.want, bytes.Equal) {
+				t.Errorf("incorrect messages\ngot  % x\nwant % x", sender.messages, test.want)
+			}
+		})
+	}
+
+	if _, err := midi.NewController(&recorder{}, midi.ControllerParams{}); err == nil {
+		t.Error("expected an error for zero onset and release frames")
+	}
+}
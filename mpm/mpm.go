@@ -0,0 +1,159 @@
+// Package mpm implements the McLeod Pitch Method, which picks the period from the key maxima of the normalized
+// square difference function. It is robust on vocal recordings and complements the YIN family of algorithms.
+package mpm
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+type (
+	// Params defines configuration options for the MPM pitch detector.
+	Params struct {
+		FrameSize         int     // Length of the input audio frame in samples.
+		SampleRate        float64 // Audio sampling rate in Hz.
+		Cutoff            float64 // Fraction of the highest key maximum the chosen key maximum must reach, in (0, 1].
+		MinFrequency      float64 // Minimum detectable frequency in Hz.
+		MaxFrequency      float64 // Maximum detectable frequency in Hz.
+		ShouldInterpolate bool    // Whether to refine the period with parabolic interpolation.
+	}
+	// PitchDetector detects pitch with the McLeod Pitch Method. It is safe for concurrent use.
+	PitchDetector struct {
+		params           Params
+		minPeriodSamples int
+		maxPeriodSamples int
+		scratchPool      sync.Pool
+	}
+)
+
+// DefaultParams use the cutoff recommended by McLeod and Wyvill.
+var DefaultParams = Params{
+	FrameSize:         2048,
+	SampleRate:        44100,
+	Cutoff:            0.93,
+	MinFrequency:      50,
+	MaxFrequency:      4000,
+	ShouldInterpolate: true,
+}
+
+// New creates a new PitchDetector instance using the provided Params.
+func New(params Params) (*PitchDetector, error) {
+	if params.FrameSize < 4 {
+		return nil, fmt.Errorf("invalid 'frameSize': %d, must be at least 4", params.FrameSize)
+	}
+	if params.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid 'sampleRate': %g, must be positive", params.SampleRate)
+	}
+	if params.Cutoff <= 0 || params.Cutoff > 1 {
+		return nil, fmt.Errorf("invalid 'cutoff': %g, must be in range (0, 1]", params.Cutoff)
+	}
+	if params.MinFrequency <= 0 || params.MaxFrequency <= params.MinFrequency {
+		return nil, fmt.Errorf("invalid frequency range: [%g, %g] Hz", params.MinFrequency, params.MaxFrequency)
+	}
+
+	maxLag := params.FrameSize/2 - 1
+	maxPeriodSamples := min(int(math.Ceil(params.SampleRate/params.MinFrequency)), maxLag)
+	minPeriodSamples := max(int(math.Floor(params.SampleRate/params.MaxFrequency)), 2)
+	if maxPeriodSamples <= minPeriodSamples {
+		return nil, fmt.Errorf(
+			"maxFrequency <= minFrequency or out of range; min detectable = %.2f Hz", params.SampleRate/float64(maxLag),
+		)
+	}
+
+	pd := &PitchDetector{params: params, minPeriodSamples: minPeriodSamples, maxPeriodSamples: maxPeriodSamples}
+	pd.scratchPool.New = func() any {
+		buffer := make([]float64, maxPeriodSamples+2)
+		return &buffer
+	}
+	return pd, nil
+}
+
+// NewWithDefaultParams creates a new PitchDetector instance using the DefaultParams.
+func NewWithDefaultParams() (*PitchDetector, error) {
+	return New(DefaultParams)
+}
+
+// Params returns the params the detector was created with.
+func (pd *PitchDetector) Params() Params {
+	return pd.params
+}
+
+// DetectFromFrame detects the fundamental frequency of the frame, which must match the configured FrameSize and is not
+// modified. The confidence is the clarity, the value of the normalized square difference function at the period.
+// Returns zero frequency and confidence for silent or aperiodic frames.
+func (pd *PitchDetector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	if len(frame) != pd.params.FrameSize {
+		return 0, 0, fmt.Errorf("invalid frame size: expected %d, got %d", pd.params.FrameSize, len(frame))
+	}
+
+	buffer := pd.scratchPool.Get().(*[]float64)
+	defer pd.scratchPool.Put(buffer)
+	nsdf := *buffer
+
+	// Normalized square difference function 2*r(tau)/m(tau), with m updated incrementally as the overlap shrinks.
+	energy := 0.0
+	for _, sample := range frame {
+		energy += sample * sample
+	}
+	if energy == 0 {
+		return 0, 0, nil
+	}
+	m := 2 * energy
+	for tau := range nsdf {
+		if tau > 0 {
+			m -= frame[tau-1]*frame[tau-1] + frame[len(frame)-tau]*frame[len(frame)-tau]
+		}
+		r := 0.0
+		for j := 0; j+tau < len(frame); j++ {
+			r += frame[j] * frame[j+tau]
+		}
+		if m > 0 {
+			nsdf[tau] = 2 * r / m
+		} else {
+			nsdf[tau] = 0
+		}
+	}
+
+	// Key maxima are the highest maxima between consecutive positive zero crossings.
+	var keyMaxima []int
+	highest := 0.0
+	tau := 1
+	for tau < len(nsdf) && nsdf[tau] > 0 {
+		tau++
+	}
+	for tau < len(nsdf)-1 {
+		for tau < len(nsdf)-1 && nsdf[tau] <= 0 {
+			tau++
+		}
+		best := -1
+		for ; tau < len(nsdf)-1 && nsdf[tau] > 0; tau++ {
+			if tau >= pd.minPeriodSamples && tau <= pd.maxPeriodSamples && (best < 0 || nsdf[tau] > nsdf[best]) {
+				best = tau
+			}
+		}
+		if best >= 0 {
+			keyMaxima = append(keyMaxima, best)
+			highest = max(highest, nsdf[best])
+		}
+	}
+	if len(keyMaxima) == 0 {
+		return 0, 0, nil
+	}
+
+	for _, best := range keyMaxima {
+		if nsdf[best] < pd.params.Cutoff*highest {
+			continue
+		}
+		period, clarity := float64(best), nsdf[best]
+		if pd.params.ShouldInterpolate {
+			if curvature := nsdf[best-1] - 2*nsdf[best] + nsdf[best+1]; curvature < 0 {
+				offset := 0.5 * (nsdf[best-1] - nsdf[best+1]) / curvature
+				period += offset
+				clarity -= 0.25 * (nsdf[best-1] - nsdf[best+1]) * offset
+			}
+		}
+		return pd.params.SampleRate / period, min(1, clarity), nil
+	}
+	return 0, 0, nil
+}
@@ -0,0 +1,66 @@
+package mpm_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft/mpm"
+)
+
+func TestDetectFromFrame(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		frameSize int
+		frequency float64
+	}{
+		{"A2 in a short frame", 1024, 110},
+		{"A4", 2048, 440},
+		{"E6", 2048, 1318.51},
+		{"low E1", 4096, 41.2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := mpm.DefaultParams
+			params.FrameSize = test.frameSize
+			params.MinFrequency = 40
+			pitchDetector, err := mpm.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frame := make([]float64, test.frameSize)
+			for i := range frame {
+				phase := 2 * math.Pi * test.frequency * float64(i) / params.SampleRate
+				frame[i] = math.Sin(phase) + 0.5*math.Sin(2*phase) + 0.3*math.Sin(3*phase)
+			}
+			frequency, confidence, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(frequency-test.frequency) > test.frequency*0.005 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.frequency)
+			}
+			if confidence < 0.9 {
+				t.Errorf("incorrect confidence, got %.2f, want >= 0.9", confidence)
+			}
+		})
+	}
+}
+
+func TestDetectFromFrameSilence(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector, err := mpm.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	frequency, confidence, err := pitchDetector.DetectFromFrame(make([]float64, mpm.DefaultParams.FrameSize))
+	if err != nil || frequency != 0 || confidence != 0 {
+		t.Errorf("expected no pitch for silence, got %.2f Hz, confidence %.2f, error %v", frequency, confidence, err)
+	}
+}
@@ -0,0 +1,164 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	multiPitchHarmonics      = 20    // Maximum number of harmonics summed by the salience of a fundamental.
+	multiPitchGridCents      = 10    // Spacing of the fundamental frequencies whose salience is evaluated, in cents.
+	multiPitchTolerance      = 0.01  // Relative deviation of partials from exact harmonics, allowing for inharmonicity.
+	multiPitchAlpha          = 52.0  // Salience weight offset of the fundamental in Hz, after Klapuri (2006).
+	multiPitchBeta           = 320.0 // Salience weight offset of the partials in Hz, after Klapuri (2006).
+	multiPitchMinFundamental = 0.05  // Minimum magnitude of a fundamental relative to the spectrum maximum.
+	multiPitchMinEnergy      = 0.02  // Minimum fraction of the spectrum energy a fundamental's partials must explain.
+	multiPitchLobeBins       = 2     // Half width of the window's main lobe in bins without zero-padding.
+)
+
+// DetectMultiple is an experimental polyphonic mode, returning up to n simultaneous fundamentals of the frame, e.g.
+// the notes of a power chord or a double stop. The frame is modified in place. See DetectMultipleFromSpectrum.
+func (pd *PitchDetector) DetectMultiple(frame []float64, n int) ([]Result, error) {
+	spectrum, err := pd.PrepareSpectrum(frame)
+	if err != nil {
+		return nil, err
+	}
+	return pd.DetectMultipleFromSpectrum(spectrum, n)
+}
+
+// DetectMultipleFromSpectrum returns up to n simultaneous fundamentals of the magnitude spectrum by iterative
+// estimation and cancellation (Klapuri, 2006): the most salient fundamental of the weighted spectrum, whose salience
+// sums the magnitudes of its harmonics with weights decreasing with frequency, is picked and its partials are
+// subtracted, then the residual is searched again. Partials are reduced to the smoothed amplitude of their neighbours
+// rather than removed, so partials shared with other notes keep the other notes' share.
+//
+// Unlike the monophonic detection, every fundamental needs a spectral peak of its own, which rules out the common
+// subharmonic of chord notes. Results are in order of detection, most salient first; their confidence is the fraction
+// of the spectrum energy explained by their partials. Fewer results are returned if the residual holds no further
+// fundamentals.
+func (pd *PitchDetector) DetectMultipleFromSpectrum(spectrum []float64, n int) ([]Result, error) {
+	bins := pd.params.FFTSize()/2 + 1
+	if len(spectrum) != bins {
+		return nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidSpectrumSize, bins, len(spectrum))
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of fundamentals: %d, must be positive", n)
+	}
+
+	energy, peak := 0.0, 0.0
+	for _, magnitude := range spectrum[1:] {
+		energy += magnitude * magnitude
+		peak = max(peak, magnitude)
+	}
+	if energy == 0 {
+		return nil, nil
+	}
+
+	residual := slices.Clone(spectrum)
+	results := make([]Result, 0, n)
+	for len(results) < n {
+		frequency, ok := pd.mostSalient(residual, peak)
+		if !ok {
+			break
+		}
+		explained := pd.cancelHarmonics(residual, frequency)
+		if explained < multiPitchMinEnergy*energy {
+			break
+		}
+		results = append(results, newResult(frequency, explained/energy, pd.params.SampleRate))
+	}
+	return results, nil
+}
+
+// mostSalient returns the fundamental frequency of the highest salience in the residual spectrum among those with a
+// fundamental of at least multiPitchMinFundamental times peak, interpolated at its fundamental's peak. Returns false
+// if there is none.
+func (pd *PitchDetector) mostSalient(residual []float64, peak float64) (float64, bool) {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	nyquist := float64(len(residual)-1) * binFrequency
+	step := math.Exp2(multiPitchGridCents / 1200.0)
+
+	bestFrequency, bestSalience, bestBin := 0.0, 0.0, 0
+	for f0 := pd.params.MinFrequency; f0 <= min(pd.params.MaxFrequency, nyquist); f0 *= step {
+		fundamentalBin := pd.partialBin(residual, f0)
+		if residual[fundamentalBin] < multiPitchMinFundamental*peak {
+			continue
+		}
+
+		salience := 0.0
+		for harmonic := 1; harmonic <= multiPitchHarmonics && float64(harmonic)*f0 <= nyquist; harmonic++ {
+			frequency := float64(harmonic) * f0
+			bin := pd.partialBin(residual, frequency)
+			weight := (f0 + multiPitchAlpha) / (frequency + multiPitchBeta)
+			salience += weight * residual[bin] * math.Sqrt(pd.weights[bin])
+		}
+		if salience > bestSalience {
+			bestFrequency, bestSalience, bestBin = f0, salience, fundamentalBin
+		}
+	}
+	if bestSalience == 0 {
+		return 0, false
+	}
+
+	// The fundamental's peak is interpolated in the log-magnitude domain, falling back to the grid frequency.
+	if bestBin > 0 && bestBin < len(residual)-1 {
+		left, middle, right := residual[bestBin-1], residual[bestBin], residual[bestBin+1]
+		if left > 0 && right > 0 && middle >= left && middle >= right {
+			left, middle, right = math.Log(left), math.Log(middle), math.Log(right)
+			if denominator := left - 2*middle + right; denominator < 0 {
+				return (float64(bestBin) + 0.5*(left-right)/denominator) * binFrequency, true
+			}
+		}
+	}
+	return bestFrequency, true
+}
+
+// partialBin returns the bin of the largest magnitude within multiPitchTolerance of the frequency, at least the
+// nearest bin and its neighbours.
+func (pd *PitchDetector) partialBin(spectrum []float64, frequency float64) int {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	center := frequency / binFrequency
+	low := max(1, min(int(math.Floor(center*(1-multiPitchTolerance))), int(math.Round(center))-1))
+	high := min(len(spectrum)-1, max(int(math.Ceil(center*(1+multiPitchTolerance))), int(math.Round(center))+1))
+	best := low
+	for bin := low + 1; bin <= high; bin++ {
+		if spectrum[bin] > spectrum[best] {
+			best = bin
+		}
+	}
+	return best
+}
+
+// cancelHarmonics subtracts the partials of the fundamental frequency from the residual spectrum and returns the
+// removed energy. Each partial's main lobe is scaled down to the smaller of its amplitude and the mean amplitude of
+// the neighbouring partials, the spectral smoothness principle of Klapuri.
+func (pd *PitchDetector) cancelHarmonics(residual []float64, f0 float64) float64 {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	nyquist := float64(len(residual)-1) * binFrequency
+	count := min(multiPitchHarmonics, int(nyquist/f0))
+	partialBins, amplitudes := make([]int, count), make([]float64, count)
+	for i := range count {
+		partialBins[i] = pd.partialBin(residual, float64(i+1)*f0)
+		amplitudes[i] = residual[partialBins[i]]
+	}
+
+	lobe := multiPitchLobeBins * max(1, pd.params.ZeroPadFactor)
+	removed := 0.0
+	for i, bin := range partialBins {
+		if amplitudes[i] == 0 {
+			continue
+		}
+		smoothed := amplitudes[i]
+		if i > 0 && i < count-1 {
+			smoothed = min(smoothed, (amplitudes[i-1]+amplitudes[i]+amplitudes[i+1])/3)
+		}
+		scale := 1 - smoothed/amplitudes[i]
+		for j := max(1, bin-lobe); j <= min(len(residual)-1, bin+lobe); j++ {
+			before := residual[j]
+			residual[j] *= scale
+			removed += before*before - residual[j]*residual[j]
+		}
+	}
+	return removed
+}
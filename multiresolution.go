@@ -0,0 +1,108 @@
+package yinfft
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// MultiResolutionParams configure a MultiResolutionDetector.
+	MultiResolutionParams struct {
+		Params         Params  // Params of the long frame, which covers the whole frequency range.
+		ShortFrameSize int     // Length of the short frame at the end of the long frame, FrameSize/4 if zero.
+		Crossover      float64 // Frequency in Hz from which the short frame is used, four short frame periods if zero.
+	}
+	// MultiResolutionDetector detects pitch with a long frame for low frequencies and a short frame for high
+	// frequencies. The long frame resolves low bass notes, while the short frame, the most recent samples of the long
+	// one, reacts faster to high notes. The long frame is analyzed first: if it detects a pitch below Crossover, that
+	// pitch is returned, otherwise the short frame is analyzed from Crossover up and its pitch is returned if it
+	// detects one.
+	MultiResolutionDetector struct {
+		long       *PitchDetector
+		short      *PitchDetector
+		crossover  float64
+		shortFrame []float64
+	}
+)
+
+var _ PitchAlgorithm = (*MultiResolutionDetector)(nil)
+
+// NewMultiResolutionDetector creates a MultiResolutionDetector. The short frame detector shares the long frame params
+// except for the frame size, the frequency range starting at Crossover, HopSize, BinWeights and Decimation, which only
+// apply to the long frame.
+func NewMultiResolutionDetector(params MultiResolutionParams) (*MultiResolutionDetector, error) {
+	long, err := New(params.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	frameSize := params.Params.FrameSize
+	if params.ShortFrameSize == 0 {
+		params.ShortFrameSize = frameSize / 4
+	}
+	if params.ShortFrameSize < 4 || params.ShortFrameSize >= frameSize {
+		return nil, fmt.Errorf(
+			"invalid 'shortFrameSize': %d, must be in range [4, %d)", params.ShortFrameSize, frameSize,
+		)
+	}
+	if params.Crossover == 0 {
+		params.Crossover = recommendedPeriods * params.Params.SampleRate / float64(params.ShortFrameSize)
+	}
+	if !(params.Crossover > params.Params.MinFrequency && params.Crossover < params.Params.MaxFrequency) {
+		return nil, fmt.Errorf(
+			"invalid 'crossover': %g Hz, must be in range (%g, %g) Hz",
+			params.Crossover, params.Params.MinFrequency, params.Params.MaxFrequency,
+		)
+	}
+	if minCrossover := params.Params.SampleRate / float64(params.ShortFrameSize/2); params.Crossover < minCrossover {
+		return nil, fmt.Errorf(
+			"invalid 'crossover': %g Hz, the short frame detects down to %.2f Hz", params.Crossover, minCrossover,
+		)
+	}
+
+	shortParams := params.Params
+	shortParams.FrameSize, shortParams.MinFrequency = params.ShortFrameSize, params.Crossover
+	shortParams.HopSize, shortParams.BinWeights, shortParams.Decimation = 0, nil, 0
+	short, err := New(shortParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid short frame: %w", err)
+	}
+
+	return &MultiResolutionDetector{
+		long:       long,
+		short:      short,
+		crossover:  params.Crossover,
+		shortFrame: make([]float64, params.ShortFrameSize),
+	}, nil
+}
+
+// Detect detects the pitch of a long frame, see MultiResolutionDetector. The frame is modified in place. A
+// MultiResolutionDetector reuses a frame buffer, so it is not safe for concurrent use.
+func (m *MultiResolutionDetector) Detect(frame []float64) (Result, error) {
+	if err := m.long.checkFrame(frame); err != nil {
+		return Result{}, err
+	}
+	copy(m.shortFrame, frame[len(frame)-len(m.shortFrame):])
+
+	long, err := m.long.Detect(frame)
+	if err != nil && !errors.Is(err, ErrNoPitch) {
+		return Result{}, err
+	}
+	if long.Voiced && long.Frequency < m.crossover {
+		return long, nil
+	}
+
+	short, shortErr := m.short.Detect(m.shortFrame)
+	if shortErr != nil && !errors.Is(shortErr, ErrNoPitch) {
+		return Result{}, shortErr
+	}
+	if short.Voiced {
+		return short, nil
+	}
+	return long, err
+}
+
+// Crossover returns the frequency in Hz from which the short frame is used.
+func (m *MultiResolutionDetector) Crossover() float64 {
+	return m.crossover
+}
@@ -0,0 +1,75 @@
+package music
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// naturalPitchClasses are the pitch classes of the natural note letters.
+var naturalPitchClasses = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// FrequencyToMIDI returns the fractional MIDI note number of the frequency in equal temperament, e.g. 69.5 for a
+// quarter tone above A4. The reference frequency of A4 is a4, DefaultA4 if zero.
+func FrequencyToMIDI(frequency, a4 float64) float64 {
+	return 69 + 12*math.Log2(frequency/NoteMapper{A4: a4}.a4())
+}
+
+// MIDIToFrequency returns the frequency of the fractional MIDI note number in equal temperament, the inverse of
+// FrequencyToMIDI.
+func MIDIToFrequency(midi, a4 float64) float64 {
+	return NoteMapper{A4: a4}.a4() * math.Exp2((midi-69)/12)
+}
+
+// FrequencyToNote returns the note closest to the frequency in equal temperament with the deviation in cents, see
+// NoteMapper.Note for stretch tuning.
+func FrequencyToNote(frequency, a4 float64) Note {
+	return NoteMapper{A4: a4}.Note(frequency)
+}
+
+// NoteToFrequency returns the frequency of the named note in equal temperament, see ParseNote.
+func NoteToFrequency(name string, a4 float64) (float64, error) {
+	midi, err := ParseNote(name)
+	if err != nil {
+		return 0, err
+	}
+	return MIDIToFrequency(float64(midi), a4), nil
+}
+
+// NoteName returns the name of the MIDI note in scientific pitch notation with sharps, e.g. "C#4" for 61.
+func NoteName(midi int) string {
+	return noteNames[((midi%12)+12)%12] + strconv.Itoa(int(math.Floor(float64(midi)/12))-1)
+}
+
+// ParseNote returns the MIDI note number of a note name in scientific pitch notation: a letter, any number of sharps
+// ('#') or flats ('b'), and the octave, e.g. "A4", "Eb2" or "C#-1".
+func ParseNote(name string) (int, error) {
+	if name == "" {
+		return 0, fmt.Errorf("invalid note name: %q", name)
+	}
+	pitchClass, ok := naturalPitchClasses[strings.ToUpper(name[:1])[0]]
+	if !ok {
+		return 0, fmt.Errorf("invalid note name: %q, must start with a letter from A to G", name)
+	}
+
+	rest := name[1:]
+	for len(rest) > 0 && (rest[0] == '#' || rest[0] == 'b') {
+		if rest[0] == '#' {
+			pitchClass++
+		} else {
+			pitchClass--
+		}
+		rest = rest[1:]
+	}
+	octave, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid note name: %q, must end with the octave", name)
+	}
+	return 12*(octave+1) + pitchClass, nil
+}
+
+// String returns the name of the note in scientific pitch notation, e.g. "C#4".
+func (n Note) String() string {
+	return n.Name + strconv.Itoa(n.Octave)
+}
@@ -0,0 +1,14 @@
+package music
+
+// NoteEvent is a discrete note of a transcribed melody.
+type NoteEvent struct {
+	Onset    float64 // Start of the note in seconds.
+	Duration float64 // Duration of the note in seconds.
+	MIDI     int     // MIDI note number.
+	Cents    float64 // Mean deviation of the note from the MIDI pitch in cents.
+}
+
+// End returns the end of the note in seconds.
+func (e NoteEvent) End() float64 {
+	return e.Onset + e.Duration
+}
@@ -0,0 +1,87 @@
+package music
+
+import (
+	"math"
+)
+
+// Key is a major or minor key.
+type Key struct {
+	Tonic int  // Pitch class of the tonic, 0 for C.
+	Minor bool // Whether the key is minor.
+}
+
+var (
+	// Krumhansl-Kessler key profiles, starting at the tonic.
+	majorProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+
+	// Tonic names of the major keys by the number of sharps in the signature, from 5 flats to 6 sharps.
+	majorKeyNames = [12]string{"Db", "Ab", "Eb", "Bb", "F", "C", "G", "D", "A", "E", "B", "F#"}
+	minorKeyNames = [12]string{"Bb", "F", "C", "G", "D", "A", "E", "B", "F#", "C#", "G#", "D#"}
+)
+
+// EstimateKey estimates the key of the events with the Krumhansl-Schmuckler algorithm, correlating the
+// duration-weighted pitch class distribution with the major and minor key profiles.
+func EstimateKey(events []NoteEvent) Key {
+	var histogram [12]float64
+	for _, event := range events {
+		histogram[((event.MIDI%12)+12)%12] += event.Duration
+	}
+
+	best, bestCorrelation := Key{}, math.Inf(-1)
+	for tonic := range 12 {
+		for _, minor := range []bool{false, true} {
+			profile := majorProfile
+			if minor {
+				profile = minorProfile
+			}
+			if correlation := correlate(histogram, profile, tonic); correlation > bestCorrelation {
+				best, bestCorrelation = Key{Tonic: tonic, Minor: minor}, correlation
+			}
+		}
+	}
+	return best
+}
+
+// Fifths returns the number of sharps in the key signature, negative for flats.
+func (k Key) Fifths() int {
+	tonic := k.Tonic
+	if k.Minor {
+		tonic += 3
+	}
+	fifths := (((tonic * 7) % 12) + 12) % 12
+	if fifths > 6 {
+		fifths -= 12
+	}
+	return fifths
+}
+
+// String returns the name of the key, e.g. "Bb" or "F#m".
+func (k Key) String() string {
+	if k.Minor {
+		return minorKeyNames[k.Fifths()+5] + "m"
+	}
+	return majorKeyNames[k.Fifths()+5]
+}
+
+// correlate returns the Pearson correlation of the histogram with the profile rotated to the tonic.
+func correlate(histogram, profile [12]float64, tonic int) float64 {
+	var histogramMean, profileMean float64
+	for i := range 12 {
+		histogramMean += histogram[i] / 12
+		profileMean += profile[i] / 12
+	}
+
+	var covariance, histogramVariance, profileVariance float64
+	for i := range 12 {
+		h := histogram[(i+tonic)%12] - histogramMean
+		p := profile[i] - profileMean
+		covariance += h * p
+		histogramVariance += h * h
+		profileVariance += p * p
+	}
+	if histogramVariance == 0 {
+		return 0
+	}
+	return covariance / math.Sqrt(histogramVariance*profileVariance)
+}
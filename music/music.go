@@ -0,0 +1,85 @@
+// Package music maps detected frequencies to musical notes and their deviation in cents.
+package music
+
+import (
+	"math"
+)
+
+// Reference frequencies of A4 in Hz.
+const (
+	DefaultA4    = 440.0 // Standard concert pitch, ISO 16.
+	OrchestralA4 = 442.0 // Common concert pitch of European orchestras.
+	VerdiA4      = 432.0 // Verdi's tuning.
+	BaroqueA4    = 415.0 // Baroque pitch of historically informed performance, a semitone below DefaultA4.
+)
+
+var noteNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+type (
+	// Note describes a musical note and the deviation of a measured frequency from it.
+	Note struct {
+		Name   string  // Pitch class name, e.g. "C#".
+		Octave int     // Octave in scientific pitch notation, e.g. 4 for A4.
+		MIDI   int     // MIDI note number.
+		Cents  float64 // Deviation of the measured frequency from the note in cents.
+	}
+	// NoteMapper maps frequencies to notes.
+	NoteMapper struct {
+		A4      float64      // Reference frequency of A4 in Hz, DefaultA4 is used if zero.
+		Stretch StretchCurve // Optional stretch tuning curve, pure equal temperament is used if nil.
+		// Semitones from sounding to written pitch of transposing instruments, e.g. 2 for B-flat clarinet or trumpet,
+		// whose written C sounds a whole tone lower. Notes are written pitches, the stretch curve applies to sounding
+		// pitches.
+		Transposition int
+	}
+)
+
+// Note returns the note closest to the given frequency. If a stretch curve is configured, the cent deviation is
+// reported relative to the stretched target of the note, so a well-tuned instrument reads as in tune.
+func (m NoteMapper) Note(frequency float64) Note {
+	midi := 69 + 12*math.Log2(frequency/m.a4())
+
+	nearest := int(math.Round(midi))
+	cents := m.deviation(midi, nearest)
+	if m.Stretch != nil {
+		for _, candidate := range []int{nearest - 1, nearest + 1} {
+			if candidateCents := m.deviation(midi, candidate); math.Abs(candidateCents) < math.Abs(cents) {
+				nearest, cents = candidate, candidateCents
+			}
+		}
+	}
+
+	written := nearest + m.Transposition
+	return Note{
+		Name:   noteNames[((written%12)+12)%12],
+		Octave: int(math.Floor(float64(written)/12)) - 1,
+		MIDI:   written,
+		Cents:  cents,
+	}
+}
+
+// Frequency returns the target frequency of the given MIDI note, a written pitch if Transposition is set, including
+// the stretch curve if configured.
+func (m NoteMapper) Frequency(midi int) float64 {
+	midi -= m.Transposition
+	cents := float64(midi-69) * 100
+	if m.Stretch != nil {
+		cents += m.Stretch(midi)
+	}
+	return m.a4() * math.Pow(2, cents/1200)
+}
+
+func (m NoteMapper) deviation(midi float64, note int) float64 {
+	cents := (midi - float64(note)) * 100
+	if m.Stretch != nil {
+		cents -= m.Stretch(note)
+	}
+	return cents
+}
+
+func (m NoteMapper) a4() float64 {
+	if m.A4 == 0 {
+		return DefaultA4
+	}
+	return m.A4
+}
@@ -0,0 +1,473 @@
+package music_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+func TestNoteMapper_Note(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		mapper    music.NoteMapper
+		frequency float64
+		wantName  string
+		wantOct   int
+		wantCents float64
+	}{
+		{"A4 in equal temperament", music.NoteMapper{}, 440, "A", 4, 0},
+		{"E2 in equal temperament", music.NoteMapper{}, 82.41, "E", 2, 0},
+		{"sharp C3", music.NoteMapper{}, 131.57, "C", 3, 10},
+		{"stretched A0 is in tune", music.NoteMapper{Stretch: music.Railsback}, 27.03, "A", 0, 0},
+		{"stretched C8 is in tune", music.NoteMapper{Stretch: music.Railsback}, 4259.18, "C", 8, 0},
+		{"A0 is flat without stretch", music.NoteMapper{}, 27.03, "A", 0, -30},
+		{"A4 in orchestral tuning", music.NoteMapper{A4: music.OrchestralA4}, 442, "A", 4, 0},
+		{"A4 in baroque tuning", music.NoteMapper{A4: music.BaroqueA4}, 415, "A", 4, 0},
+		{"440 Hz is sharp in baroque tuning", music.NoteMapper{A4: music.BaroqueA4}, 440, "A#", 4, 1},
+		{"B-flat clarinet", music.NoteMapper{Transposition: 2}, 233.08, "C", 4, 0},
+		{"E-flat alto saxophone", music.NoteMapper{Transposition: 9}, 261.63, "A", 4, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			note := test.mapper.Note(test.frequency)
+			if note.Name != test.wantName || note.Octave != test.wantOct {
+				t.Errorf("incorrect note, got %s%d, want %s%d", note.Name, note.Octave, test.wantName, test.wantOct)
+			}
+			if math.Abs(note.Cents-test.wantCents) > 1 {
+				t.Errorf("incorrect deviation, got %.2f cents, want %.2f cents", note.Cents, test.wantCents)
+			}
+		})
+	}
+}
+
+func TestNoteMapper_Frequency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		mapper        music.NoteMapper
+		midi          int
+		wantFrequency float64
+	}{
+		{"A4", music.NoteMapper{}, 69, 440},
+		{"A4 in baroque tuning", music.NoteMapper{A4: music.BaroqueA4}, 69, 415},
+		{"written C4 of a B-flat clarinet", music.NoteMapper{Transposition: 2}, 60, 233.08},
+		{"written A4 of a horn in F", music.NoteMapper{Transposition: 7}, 69, 293.66},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if frequency := test.mapper.Frequency(test.midi); math.Abs(frequency-test.wantFrequency) > 0.01 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestEstimateKey(t *testing.T) {
+	t.Parallel()
+
+	scale := func(tonic int, steps []int) []music.NoteEvent {
+		events := make([]music.NoteEvent, len(steps))
+		for i, step := range steps {
+			duration := 0.5
+			if step == 0 || step == 7 {
+				duration = 1
+			}
+			events[i] = music.NoteEvent{Onset: float64(i), Duration: duration, MIDI: 60 + tonic + step}
+		}
+		return events
+	}
+	major := []int{0, 2, 4, 5, 7, 9, 11, 12}
+	minor := []int{0, 2, 3, 5, 7, 8, 10, 12}
+
+	tests := []struct {
+		name   string
+		events []music.NoteEvent
+		want   string
+	}{
+		{"C major", scale(0, major), "C"},
+		{"B flat major", scale(10, major), "Bb"},
+		{"A minor", scale(9, minor), "Am"},
+		{"F sharp minor", scale(6, minor), "F#m"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if key := music.EstimateKey(test.events); key.String() != test.want {
+				t.Errorf("incorrect key, got %s, want %s", key, test.want)
+			}
+		})
+	}
+}
+
+func TestFrequencyToMIDI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		frequency float64
+		a4        float64
+		wantMIDI  float64
+	}{
+		{"A4", 440, 0, 69},
+		{"middle C", 261.63, 0, 60},
+		{"quarter tone above A4", 452.89, 0, 69.5},
+		{"A4 at 442 Hz", 442, 442, 69},
+		{"A3 at 432 Hz", 216, 432, 57},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			midi := music.FrequencyToMIDI(test.frequency, test.a4)
+			if math.Abs(midi-test.wantMIDI) > 0.01 {
+				t.Errorf("incorrect MIDI note, got %.3f, want %.3f", midi, test.wantMIDI)
+			}
+			if frequency := music.MIDIToFrequency(midi, test.a4); math.Abs(frequency-test.frequency) > 1e-9 {
+				t.Errorf("incorrect round trip, got %.6f Hz, want %.6f Hz", frequency, test.frequency)
+			}
+		})
+	}
+}
+
+func TestFrequencyToNote(t *testing.T) {
+	t.Parallel()
+
+	if note := music.FrequencyToNote(446, 442); note.String() != "A4" || math.Abs(note.Cents-15.6) > 0.1 {
+		t.Errorf("incorrect note, got %s %+.1f cents, want A4 +15.6 cents", note, note.Cents)
+	}
+}
+
+func TestParseNote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		wantMIDI int
+		wantErr  bool
+	}{
+		{"A4", 69, false},
+		{"C4", 60, false},
+		{"C#4", 61, false},
+		{"Db4", 61, false},
+		{"e2", 40, false},
+		{"B#3", 60, false},
+		{"Cb4", 59, false},
+		{"C-1", 0, false},
+		{"", 0, true},
+		{"H4", 0, true},
+		{"C", 0, true},
+		{"C#x", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			midi, err := music.ParseNote(test.name)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error state, got %v, want error %t", err, test.wantErr)
+			}
+			if midi != test.wantMIDI {
+				t.Errorf("incorrect MIDI note, got %d, want %d", midi, test.wantMIDI)
+			}
+		})
+	}
+
+	frequency, err := music.NoteToFrequency("A3", 415.3)
+	if err != nil || math.Abs(frequency-207.65) > 0.01 {
+		t.Errorf("incorrect frequency, got %.2f Hz (%v), want 207.65 Hz", frequency, err)
+	}
+}
+
+func TestNoteName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		midi int
+		want string
+	}{
+		{0, "C-1"},
+		{40, "E2"},
+		{61, "C#4"},
+		{69, "A4"},
+		{108, "C8"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			t.Parallel()
+
+			if name := music.NoteName(test.midi); name != test.want {
+				t.Errorf("incorrect name, got %s, want %s", name, test.want)
+			}
+		})
+	}
+}
+
+func TestTemperament_Curve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		temperament music.Temperament
+		tonic       int
+		frequency   float64
+		wantName    string
+		wantCents   float64
+	}{
+		{"pure third in equal temperament", music.EqualTemperament, 0, 261.63 * 5 / 4, "E", -13.7},
+		{"pure third in just intonation", music.JustIntonation, 0, 261.63 * 5 / 4, "E", 0},
+		{"pure third in meantone", music.QuarterCommaMeantone, 0, 261.63 * 5 / 4, "E", 0},
+		{"pure fifth in Pythagorean", music.Pythagorean, 0, 261.63 * 3 / 2, "G", 0},
+		{"pythagorean third is sharp in just intonation", music.JustIntonation, 0, 261.63 * 81 / 64, "E", 21.5},
+		{"pure third on D", music.JustIntonation, 2, 293.66 * 5 / 4, "F#", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			note := music.NoteMapper{Stretch: test.temperament.Curve(test.tonic)}.Note(test.frequency)
+			if note.Name != test.wantName || math.Abs(note.Cents-test.wantCents) > 0.1 {
+				t.Errorf(
+					"incorrect note, got %s %+.1f cents, want %s %+.1f cents", note.Name, note.Cents, test.wantName,
+					test.wantCents,
+				)
+			}
+		})
+	}
+}
+
+func TestParseScala(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		input       string
+		wantDegrees []float64
+		wantErr     bool
+	}{
+		{
+			"ratios and cents",
+			"! pentatonic.scl\n!\nJust pentatonic\n 5\n!\n 9/8\n 5/4 major third\n 701.955\n 5/3\n 2\n",
+			[]float64{203.91, 386.31, 701.96, 884.36, 1200},
+			false,
+		},
+		{"empty description", "\n1\n1200.0\n", []float64{1200}, false},
+		{"missing degrees", "Scale\n3\n100.0\n", nil, true},
+		{"invalid count", "Scale\nthree\n", nil, true},
+		{"invalid ratio", "Scale\n1\n3/0\n", nil, true},
+		{"invalid cents", "Scale\n1\n1.2.3\n", nil, true},
+		{"negative period", "Scale\n1\n-1200.0\n", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			scale, err := music.ParseScala(strings.NewReader(test.input))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error state, got %v, want error %t", err, test.wantErr)
+			}
+			if len(scale.Degrees) != len(test.wantDegrees) {
+				t.Fatalf("incorrect degrees, got %.2f, want %.2f", scale.Degrees, test.wantDegrees)
+			}
+			for i, degree := range scale.Degrees {
+				if math.Abs(degree-test.wantDegrees[i]) > 0.01 {
+					t.Errorf("incorrect degrees, got %.2f, want %.2f", scale.Degrees, test.wantDegrees)
+				}
+			}
+		})
+	}
+}
+
+func TestScale_Nearest(t *testing.T) {
+	t.Parallel()
+
+	// 19 tone equal temperament, with steps of about 63 cents.
+	var edo19 music.Scale
+	for step := 1; step <= 19; step++ {
+		edo19.Degrees = append(edo19.Degrees, float64(step)*1200/19)
+	}
+
+	tests := []struct {
+		name       string
+		frequency  float64
+		wantDegree int
+		wantPeriod int
+		wantCents  float64
+	}{
+		{"root", 440, 0, 0, 0},
+		{"third step", 440 * math.Exp2(3.0/19), 3, 0, 0},
+		{"sharp fifth step", 440 * math.Exp2(5.0/19+10.0/1200), 5, 0, 10},
+		{"flat octave", 440 * math.Exp2(1-5.0/1200), 0, 1, -5},
+		{"below the root", 220 * math.Exp2(18.0/19), 18, -1, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			degree := edo19.Nearest(test.frequency, 440)
+			if degree.Degree != test.wantDegree || degree.Period != test.wantPeriod ||
+				math.Abs(degree.Cents-test.wantCents) > 1e-6 {
+				t.Errorf(
+					"incorrect degree, got %+v, want degree %d of period %d at %+.1f cents", degree, test.wantDegree,
+					test.wantPeriod, test.wantCents,
+				)
+			}
+		})
+	}
+}
+
+func TestScale_Temperament(t *testing.T) {
+	t.Parallel()
+
+	input := "Just intonation\n12\n16/15\n9/8\n6/5\n5/4\n4/3\n45/32\n3/2\n8/5\n5/3\n16/9\n15/8\n2/1\n"
+	scale, err := music.ParseScala(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("error parsing scale: %v", err)
+	}
+	temperament, err := scale.Temperament()
+	if err != nil {
+		t.Fatalf("error converting scale: %v", err)
+	}
+	for pitchClass, cents := range temperament {
+		if math.Abs(cents-music.JustIntonation[pitchClass]) > 1e-9 {
+			t.Errorf("incorrect temperament, got %.2f, want %.2f", temperament, music.JustIntonation)
+		}
+	}
+
+	if _, err := (music.Scale{Degrees: []float64{600, 1200}}).Temperament(); err == nil {
+		t.Error("expected an error for a scale of 2 degrees")
+	}
+}
+
+func TestSegment(t *testing.T) {
+	t.Parallel()
+
+	type run struct {
+		frequency float64 // Zero for unvoiced frames.
+		frames    int
+	}
+	const hop = 0.01
+	contour := func(runs ...run) []yinfft.Result {
+		var results []yinfft.Result
+		for _, r := range runs {
+			for range r.frames {
+				results = append(results, yinfft.Result{
+					Frequency:  r.frequency,
+					Confidence: 1,
+					Voiced:     r.frequency > 0,
+					Time:       float64(len(results)) * hop,
+				})
+			}
+		}
+		return results
+	}
+
+	tests := []struct {
+		name    string
+		results []yinfft.Result
+		params  music.SegmentParams
+		want    []music.NoteEvent
+	}{
+		{
+			name:    "single note",
+			results: contour(run{440, 20}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.2, MIDI: 69}},
+		},
+		{
+			name:    "legato notes",
+			results: contour(run{440, 20}, run{493.88, 20}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.2, MIDI: 69}, {Onset: 0.2, Duration: 0.2, MIDI: 71}},
+		},
+		{
+			name:    "glitch absorbed by the note",
+			results: contour(run{440, 10}, run{880, 2}, run{440, 10}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.22, MIDI: 69}},
+		},
+		{
+			name:    "short gap bridged",
+			results: contour(run{440, 10}, run{0, 2}, run{440, 10}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.22, MIDI: 69}},
+		},
+		{
+			name:    "rest between repeated notes",
+			results: contour(run{440, 10}, run{0, 10}, run{440, 10}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.1, MIDI: 69}, {Onset: 0.2, Duration: 0.1, MIDI: 69}},
+		},
+		{
+			name:    "short note dropped",
+			results: contour(run{440, 3}, run{0, 10}, run{440, 10}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0.13, Duration: 0.1, MIDI: 69}},
+		},
+		{
+			name:    "mean deviation in cents",
+			results: contour(run{music.MIDIToFrequency(69.2, 0), 10}),
+			params:  music.DefaultSegmentParams,
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.1, MIDI: 69, Cents: 20}},
+		},
+		{
+			name:    "transposed",
+			results: contour(run{440, 10}),
+			params:  music.SegmentParams{Mapper: music.NoteMapper{Transposition: 2}, MaxDeviation: 50},
+			want:    []music.NoteEvent{{Onset: 0, Duration: 0.1, MIDI: 71}},
+		},
+		{
+			name:    "no results",
+			params:  music.DefaultSegmentParams,
+			results: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			events, err := music.Segment(test.results, test.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(events) != len(test.want) {
+				t.Fatalf("incorrect number of events, got %+v, want %+v", events, test.want)
+			}
+			for i, event := range events {
+				want := test.want[i]
+				if event.MIDI != want.MIDI || math.Abs(event.Onset-want.Onset) > 1e-9 ||
+					math.Abs(event.Duration-want.Duration) > 1e-9 || math.Abs(event.Cents-want.Cents) > 0.1 {
+					t.Errorf("incorrect event %d, got %+v, want %+v", i, event, want)
+				}
+			}
+		})
+	}
+
+	if _, err := music.Segment(contour(run{440, 10}), music.SegmentParams{}); err == nil {
+		t.Error("expected an error for a zero maximum deviation")
+	}
+	single := contour(run{440, 1})
+	if _, err := music.Segment(single, music.DefaultSegmentParams); err == nil {
+		t.Error("expected an error for an unknown frame duration")
+	}
+}
@@ -0,0 +1,132 @@
+package music
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Scale is a microtonal scale as defined by a Scala file, see ParseScala.
+	Scale struct {
+		Description string    // Description line of the file.
+		Degrees     []float64 // Pitches of the degrees above the root in cents, the last is the period.
+	}
+	// ScaleDegree is the degree of a scale nearest to a frequency.
+	ScaleDegree struct {
+		Degree int     // Index of the degree, 0 for the root.
+		Period int     // Number of periods, usually octaves, above the root frequency, negative below it.
+		Cents  float64 // Deviation of the frequency from the degree in cents.
+	}
+)
+
+// ParseScala parses a scale in the Scala .scl format: lines starting with '!' are comments, the first other line is
+// the description, the second the number of degrees, followed by that many pitches. A pitch containing a period is in
+// cents, any other is a ratio such as "3/2" or "2". Text after a pitch is ignored.
+func ParseScala(r io.Reader) (Scale, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); !strings.HasPrefix(line, "!") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Scale{}, fmt.Errorf("failed to read scale: %w", err)
+	}
+	if len(lines) < 2 {
+		return Scale{}, errors.New("invalid scale: missing description or number of degrees")
+	}
+
+	count, err := strconv.Atoi(firstField(lines[1]))
+	if err != nil || count < 1 {
+		return Scale{}, fmt.Errorf("invalid number of degrees: %q", lines[1])
+	}
+	if len(lines)-2 < count {
+		return Scale{}, fmt.Errorf("invalid scale: expected %d degrees, got %d", count, len(lines)-2)
+	}
+
+	scale := Scale{Description: strings.TrimSpace(lines[0]), Degrees: make([]float64, count)}
+	for i, line := range lines[2 : 2+count] {
+		cents, err := parsePitch(firstField(line))
+		if err != nil {
+			return Scale{}, fmt.Errorf("invalid degree %d: %w", i+1, err)
+		}
+		scale.Degrees[i] = cents
+	}
+	if period := scale.Degrees[count-1]; period <= 0 {
+		return Scale{}, fmt.Errorf("invalid period: %g cents, must be positive", period)
+	}
+	return scale, nil
+}
+
+// Nearest returns the degree of the scale nearest to the frequency, with the root of the scale at the given
+// frequency in Hz.
+func (s Scale) Nearest(frequency, root float64) ScaleDegree {
+	cents := 1200 * math.Log2(frequency/root)
+	period := s.Degrees[len(s.Degrees)-1]
+	nearest := ScaleDegree{Period: int(math.Floor(cents / period))}
+	cents -= float64(nearest.Period) * period
+	nearest.Cents = cents
+
+	// The root of the next period is a candidate too, as its degree 0.
+	for degree, pitch := range s.Degrees {
+		if math.Abs(cents-pitch) < math.Abs(nearest.Cents) {
+			nearest.Degree, nearest.Cents = (degree+1)%len(s.Degrees), cents-pitch
+		}
+	}
+	if nearest.Degree == 0 && nearest.Cents < 0 {
+		nearest.Period++
+	}
+	return nearest
+}
+
+// Temperament returns a scale of 12 degrees repeating at the octave as a temperament, e.g. to use a historical
+// tuning from the Scala archive with a NoteMapper.
+func (s Scale) Temperament() (Temperament, error) {
+	if len(s.Degrees) != 12 || math.Abs(s.Degrees[11]-1200) > 1e-6 {
+		return Temperament{}, fmt.Errorf(
+			"invalid scale for a temperament: %d degrees, must be 12 with an octave period", len(s.Degrees),
+		)
+	}
+	var temperament Temperament
+	for pitchClass := 1; pitchClass < 12; pitchClass++ {
+		temperament[pitchClass] = s.Degrees[pitchClass-1] - float64(100*pitchClass)
+	}
+	return temperament, nil
+}
+
+// parsePitch parses a Scala pitch into cents.
+func parsePitch(pitch string) (float64, error) {
+	if strings.Contains(pitch, ".") {
+		cents, err := strconv.ParseFloat(pitch, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cents value: %q", pitch)
+		}
+		return cents, nil
+	}
+
+	numerator, denominator, found := strings.Cut(pitch, "/")
+	if !found {
+		denominator = "1"
+	}
+	n, errNumerator := strconv.ParseUint(numerator, 10, 64)
+	d, errDenominator := strconv.ParseUint(denominator, 10, 64)
+	if errNumerator != nil || errDenominator != nil || n == 0 || d == 0 {
+		return 0, fmt.Errorf("invalid ratio: %q", pitch)
+	}
+	return 1200 * math.Log2(float64(n)/float64(d)), nil
+}
+
+// firstField returns the first whitespace separated field of the line, empty if there is none.
+func firstField(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
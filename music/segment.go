@@ -0,0 +1,121 @@
+package music
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+type (
+	// SegmentParams configure Segment.
+	SegmentParams struct {
+		Mapper        NoteMapper // Maps frequencies to notes, e.g. with a reference A4 or transposition.
+		MinDuration   float64    // Minimum duration of notes in seconds, shorter pitch changes don't start notes.
+		MaxDeviation  float64    // Maximum deviation of a frame from the mean pitch of its note in cents.
+		MaxGap        float64    // Longest run of unvoiced frames in seconds bridged within a note.
+		MinConfidence float64    // Minimum confidence of voiced frames, less confident frames count as unvoiced.
+		// Time each result covers in seconds, usually the hop size of the detection. It is derived from the spacing
+		// of the first two results if zero.
+		FrameDuration float64
+	}
+	// segment is a run of frames of stable pitch.
+	segment struct {
+		start, end float64 // Start of the first and end of the last frame in seconds.
+		pitchSum   float64 // Sum of the fractional MIDI pitches of the frames.
+		frames     int
+	}
+)
+
+// DefaultSegmentParams are segmentation params suited to sung and played melodies.
+var DefaultSegmentParams = SegmentParams{
+	MinDuration:   0.06,
+	MaxDeviation:  60,
+	MaxGap:        0.03,
+	MinConfidence: 0.5,
+}
+
+// Segment converts a pitch contour, such as the results of yinfft.PitchDetector.DetectAll or of a PitchSmoother, into
+// note events. A note continues while its frames stay within MaxDeviation of its mean pitch; a deviating run of
+// frames of stable pitch starts a new note once it lasts MinDuration, while shorter ones, such as glitches and
+// ornaments, are absorbed by the note. Unvoiced runs up to MaxGap are bridged, longer ones end the note. Notes are
+// reported at their mean pitch, and notes shorter than MinDuration are dropped. The results must be in time order.
+func Segment(results []yinfft.Result, params SegmentParams) ([]NoteEvent, error) {
+	if params.MinDuration < 0 || params.MaxDeviation <= 0 || params.MaxGap < 0 {
+		return nil, fmt.Errorf(
+			"invalid segment params: minDuration %g and maxGap %g must not be negative, maxDeviation %g must be positive",
+			params.MinDuration, params.MaxGap, params.MaxDeviation,
+		)
+	}
+	frameDuration := params.FrameDuration
+	if frameDuration == 0 && len(results) > 1 {
+		frameDuration = results[1].Time - results[0].Time
+	}
+	if !(frameDuration > 0) && len(results) > 0 {
+		return nil, fmt.Errorf("invalid frame duration: %g s, must be positive", frameDuration)
+	}
+
+	var events []NoteEvent
+	var note, pending segment
+	emit := func() {
+		if note.frames > 0 && note.end-note.start >= params.MinDuration-1e-9 {
+			events = append(events, note.event())
+		}
+		note = segment{}
+	}
+
+	for _, result := range results {
+		if !result.Voiced || result.Confidence < params.MinConfidence {
+			if note.frames > 0 && result.Time-note.end >= params.MaxGap-1e-9 {
+				emit()
+			}
+			pending = segment{}
+			continue
+		}
+
+		mapped := params.Mapper.Note(result.Frequency)
+		pitch := float64(mapped.MIDI) + mapped.Cents/100
+		frame := segment{start: result.Time, end: result.Time + frameDuration, pitchSum: pitch, frames: 1}
+		switch {
+		case note.frames == 0:
+			note = frame
+		case note.deviation(pitch) <= params.MaxDeviation:
+			note.add(frame)
+			pending = segment{}
+		case pending.frames > 0 && pending.deviation(pitch) <= params.MaxDeviation:
+			pending.add(frame)
+		default:
+			pending = frame
+		}
+
+		// A stable deviating run becomes the next note, a shorter one extends the current note.
+		if pending.frames > 0 && pending.end-pending.start >= params.MinDuration-1e-9 {
+			note.end = pending.start
+			emit()
+			note, pending = pending, segment{}
+		} else if pending.frames > 0 {
+			note.end = pending.end
+		}
+	}
+	emit()
+	return events, nil
+}
+
+// add appends the frames of the other segment.
+func (s *segment) add(other segment) {
+	s.end = other.end
+	s.pitchSum += other.pitchSum
+	s.frames += other.frames
+}
+
+// deviation returns the deviation of the pitch from the mean pitch of the segment in cents.
+func (s segment) deviation(pitch float64) float64 {
+	return 100 * math.Abs(pitch-s.pitchSum/float64(s.frames))
+}
+
+// event returns the note event of the segment.
+func (s segment) event() NoteEvent {
+	pitch := s.pitchSum / float64(s.frames)
+	midi := int(math.Round(pitch))
+	return NoteEvent{Onset: s.start, Duration: s.end - s.start, MIDI: midi, Cents: 100 * (pitch - float64(midi))}
+}
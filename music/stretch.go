@@ -0,0 +1,51 @@
+package music
+
+import (
+	"cmp"
+	"slices"
+)
+
+type (
+	// StretchCurve returns the deviation in cents of a well-tuned instrument from equal temperament for a MIDI note.
+	StretchCurve func(midi int) float64
+	// StretchPoint defines the deviation of a single note on a stretch curve.
+	StretchPoint struct {
+		MIDI  int     // MIDI note number.
+		Cents float64 // Deviation from equal temperament in cents.
+	}
+)
+
+// railsbackPoints approximate the average Railsback curve of a well-tuned piano, from A0 to C8.
+var railsbackPoints = []StretchPoint{
+	{21, -30}, {33, -15}, {45, -6}, {57, -2}, {69, 0}, {81, 3}, {93, 10}, {105, 22}, {108, 30},
+}
+
+// Railsback is the typical stretch tuning curve of a piano, lowering the bass and raising the treble to compensate
+// for the inharmonicity of the strings.
+var Railsback = NewStretchCurve(railsbackPoints)
+
+// NewStretchCurve creates a stretch curve interpolating linearly between the given points. Notes outside the range
+// of the points get the deviation of the nearest point.
+func NewStretchCurve(points []StretchPoint) StretchCurve {
+	points = slices.Clone(points)
+	slices.SortFunc(points, func(a, b StretchPoint) int {
+		return cmp.Compare(a.MIDI, b.MIDI)
+	})
+
+	return func(midi int) float64 {
+		if len(points) == 0 {
+			return 0
+		}
+		if midi <= points[0].MIDI {
+			return points[0].Cents
+		}
+		for i := 1; i < len(points); i++ {
+			if midi <= points[i].MIDI {
+				left, right := points[i-1], points[i]
+				ratio := float64(midi-left.MIDI) / float64(right.MIDI-left.MIDI)
+				return left.Cents + ratio*(right.Cents-left.Cents)
+			}
+		}
+		return points[len(points)-1].Cents
+	}
+}
@@ -0,0 +1,51 @@
+package music
+
+import "math"
+
+// Temperament gives the deviation in cents of each pitch class from equal temperament, counted in semitones from the
+// tonic, which is in tune. Use Curve to measure notes against it with a NoteMapper.
+type Temperament [12]float64
+
+var (
+	// EqualTemperament is twelve-tone equal temperament, the default of NoteMapper.
+	EqualTemperament = Temperament{}
+	// JustIntonation is five-limit just intonation, with pure thirds and fifths on the tonic.
+	JustIntonation = ratioTemperament(1, 16.0/15, 9.0/8, 6.0/5, 5.0/4, 4.0/3, 45.0/32, 3.0/2, 8.0/5, 5.0/3, 16.0/9, 15.0/8)
+	// Pythagorean is tuned in pure fifths along the chain from Eb to G# for a tonic of C, leaving the wolf fifth
+	// between G# and Eb.
+	Pythagorean = fifthsTemperament(1200 * math.Log2(1.5))
+	// QuarterCommaMeantone narrows the fifths of the same chain by a quarter syntonic comma, making the major thirds
+	// pure.
+	QuarterCommaMeantone = fifthsTemperament(1200 * math.Log2(5) / 4)
+)
+
+// Curve returns the temperament as a stretch curve for the tonic pitch class, 0 for C.
+func (t Temperament) Curve(tonic int) StretchCurve {
+	return func(midi int) float64 {
+		return t[((midi-tonic)%12+12)%12]
+	}
+}
+
+// ratioTemperament returns the temperament of the frequency ratios of the pitch classes to the tonic.
+func ratioTemperament(ratios ...float64) Temperament {
+	var temperament Temperament
+	for pitchClass, ratio := range ratios {
+		temperament[pitchClass] = 1200*math.Log2(ratio) - float64(100*pitchClass)
+	}
+	return temperament
+}
+
+// fifthsTemperament returns the temperament of a chain of fifths of the given size in cents, from three fifths below
+// the tonic to eight above it.
+func fifthsTemperament(fifth float64) Temperament {
+	var temperament Temperament
+	for step := -3; step <= 8; step++ {
+		cents := math.Mod(float64(step)*fifth, 1200)
+		if cents < 0 {
+			cents += 1200
+		}
+		pitchClass := ((7*step)%12 + 12) % 12
+		temperament[pitchClass] = cents - float64(100*pitchClass)
+	}
+	return temperament
+}
@@ -0,0 +1,119 @@
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+var (
+	// Letters and accidentals of the pitch classes, spelled with sharps and with flats.
+	sharpSpelling = [12]abcPitchClass{{'C', 0}, {'C', 1}, {'D', 0}, {'D', 1}, {'E', 0}, {'F', 0}, {'F', 1}, {'G', 0}, {'G', 1}, {'A', 0}, {'A', 1}, {'B', 0}}
+	flatSpelling  = [12]abcPitchClass{{'C', 0}, {'D', -1}, {'D', 0}, {'E', -1}, {'E', 0}, {'F', 0}, {'G', -1}, {'G', 0}, {'A', -1}, {'A', 0}, {'B', -1}, {'B', 0}}
+
+	// Letters altered by key signatures, in the order sharps and flats are added.
+	sharpOrder = "FCGDAEB"
+	flatOrder  = "BEADGCF"
+)
+
+// abcPitchClass is a spelled pitch class.
+type abcPitchClass struct {
+	letter     byte
+	accidental int // 1 for sharp, -1 for flat.
+}
+
+// WriteABC quantizes the events and writes them as a single ABC notation tune, with the meter, tempo and key of the
+// options and the grid unit as the default note length.
+func WriteABC(w io.Writer, events []music.NoteEvent, opts Options) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	notes, err := Quantize(events, opts)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(w)
+	buffered.WriteString("X:1\n")
+	if opts.Title != "" {
+		fmt.Fprintf(buffered, "T:%s\n", opts.Title)
+	}
+	fmt.Fprintf(buffered, "M:%d/%d\n", opts.Meter.Beats, opts.Meter.BeatUnit)
+	fmt.Fprintf(buffered, "L:1/%d\n", 4*opts.Division)
+	fmt.Fprintf(buffered, "Q:1/4=%s\n", strconv.FormatFloat(opts.Tempo, 'f', -1, 64))
+	fmt.Fprintf(buffered, "K:%s\n", opts.Key)
+
+	spelling, signature := abcKeySignature(opts.Key)
+	barAccidentals := map[string]int{}
+	for _, note := range notes {
+		pieces := split(note, opts)
+		for i, piece := range pieces {
+			if note.Rest {
+				buffered.WriteString("z")
+			} else {
+				buffered.WriteString(abcPitch(note.MIDI, spelling, signature, barAccidentals))
+			}
+			if piece.length != 1 {
+				buffered.WriteString(strconv.Itoa(piece.length))
+			}
+			if !note.Rest && i < len(pieces)-1 {
+				buffered.WriteString("-")
+			}
+			if piece.barEnd {
+				buffered.WriteString(" |\n")
+				clear(barAccidentals)
+			} else {
+				buffered.WriteString(" ")
+			}
+		}
+	}
+
+	buffered.WriteString("|]\n")
+	return buffered.Flush()
+}
+
+// abcKeySignature returns the spelling of pitch classes in the key and the accidentals its signature applies to each
+// letter.
+func abcKeySignature(key music.Key) ([12]abcPitchClass, map[byte]int) {
+	fifths := key.Fifths()
+	signature := map[byte]int{}
+	if fifths >= 0 {
+		for _, letter := range []byte(sharpOrder[:fifths]) {
+			signature[letter] = 1
+		}
+		return sharpSpelling, signature
+	}
+	for _, letter := range []byte(flatOrder[:-fifths]) {
+		signature[letter] = -1
+	}
+	return flatSpelling, signature
+}
+
+// abcPitch returns the ABC pitch of a MIDI note, writing an accidental only if the key signature and the preceding
+// accidentals in the bar don't already imply it.
+func abcPitch(midi int, spelling [12]abcPitchClass, signature map[byte]int, barAccidentals map[string]int) string {
+	pitchClass := spelling[midi%12]
+	octave := midi/12 - 1
+	letter := string(pitchClass.letter)
+	switch {
+	case octave >= 5:
+		letter = strings.ToLower(letter) + strings.Repeat("'", octave-5)
+	case octave < 4:
+		letter += strings.Repeat(",", 4-octave)
+	}
+
+	current, ok := barAccidentals[letter]
+	if !ok {
+		current = signature[pitchClass.letter]
+	}
+	if current == pitchClass.accidental {
+		return letter
+	}
+	barAccidentals[letter] = pitchClass.accidental
+	return [...]string{"_", "=", "^"}[pitchClass.accidental+1] + letter
+}
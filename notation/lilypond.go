@@ -0,0 +1,72 @@
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// LilyPondVersion is the LilyPond version written into the generated files.
+const LilyPondVersion = "2.24.0"
+
+var lilyPondNames = [12]string{"c", "cis", "d", "dis", "e", "f", "fis", "g", "gis", "a", "ais", "b"}
+
+// WriteLilyPond quantizes the events and writes them as a LilyPond score, which can be engraved with
+// `lilypond score.ly`.
+func WriteLilyPond(w io.Writer, events []music.NoteEvent, opts Options) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	notes, err := Quantize(events, opts)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(w)
+	fmt.Fprintf(buffered, "\\version %q\n", LilyPondVersion)
+	if opts.Title != "" {
+		fmt.Fprintf(buffered, "\\header { title = %s }\n", strconv.Quote(opts.Title))
+	}
+	fmt.Fprintf(buffered, "\\score {\n  {\n    \\tempo 4 = %s\n", strconv.FormatFloat(opts.Tempo, 'f', -1, 64))
+	fmt.Fprintf(buffered, "    \\time %d/%d\n    ", opts.Meter.Beats, opts.Meter.BeatUnit)
+
+	for _, note := range notes {
+		name := "r"
+		if !note.Rest {
+			name = lilyPondPitch(note.MIDI)
+		}
+		pieces := split(note, opts)
+		for i, piece := range pieces {
+			buffered.WriteString(name + strconv.Itoa(piece.denominator))
+			if piece.dotted {
+				buffered.WriteString(".")
+			}
+			if !note.Rest && i < len(pieces)-1 {
+				buffered.WriteString("~")
+			}
+			if piece.barEnd {
+				buffered.WriteString(" |\n    ")
+			} else {
+				buffered.WriteString(" ")
+			}
+		}
+	}
+
+	buffered.WriteString("\\bar \"|.\"\n  }\n  \\layout { }\n  \\midi { }\n}\n")
+	return buffered.Flush()
+}
+
+// lilyPondPitch returns the absolute LilyPond pitch of a MIDI note, where c' is the middle C.
+func lilyPondPitch(midi int) string {
+	octave := midi/12 - 1
+	name := lilyPondNames[midi%12]
+	if octave >= 3 {
+		return name + strings.Repeat("'", octave-3)
+	}
+	return name + strings.Repeat(",", 3-octave)
+}
@@ -0,0 +1,147 @@
+package notation
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// MusicXMLVersion is the MusicXML version of the generated files.
+const MusicXMLVersion = "4.0"
+
+// musicXMLTypes are the MusicXML note types by note value denominator.
+var musicXMLTypes = map[int]string{
+	1: "whole", 2: "half", 4: "quarter", 8: "eighth", 16: "16th", 32: "32nd", 64: "64th", 128: "128th",
+	256: "256th", 512: "512th", 1024: "1024th",
+}
+
+// WriteMusicXML quantizes the events and writes them as a single-voice MusicXML score in partwise format, which opens
+// in notation programs such as MuseScore. Melodies below F3 on average are written in the bass clef.
+func WriteMusicXML(w io.Writer, events []music.NoteEvent, opts Options) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	notes, err := Quantize(events, opts)
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		notes = []Note{{Rest: true, Length: opts.barLength()}}
+	}
+	if _, ok := musicXMLTypes[4*opts.Division]; !ok {
+		return fmt.Errorf("invalid 'Division': %d is finer than MusicXML note types", opts.Division)
+	}
+
+	buffered := bufio.NewWriter(w)
+	buffered.WriteString(xml.Header)
+	fmt.Fprintf(buffered, "<!DOCTYPE score-partwise PUBLIC \"-//Recordare//DTD MusicXML %s Partwise//EN\" "+
+		"\"http://www.musicxml.org/dtds/partwise.dtd\">\n", MusicXMLVersion)
+	fmt.Fprintf(buffered, "<score-partwise version=%q>\n", MusicXMLVersion)
+	if opts.Title != "" {
+		buffered.WriteString("  <work>\n    <work-title>")
+		xml.EscapeText(buffered, []byte(opts.Title))
+		buffered.WriteString("</work-title>\n  </work>\n")
+	}
+	buffered.WriteString("  <part-list>\n    <score-part id=\"P1\">\n      <part-name>Melody</part-name>\n" +
+		"    </score-part>\n  </part-list>\n  <part id=\"P1\">\n")
+
+	tempo := strconv.FormatFloat(opts.Tempo, 'f', -1, 64)
+	mode := "major"
+	if opts.Key.Minor {
+		mode = "minor"
+	}
+	clef := "<sign>G</sign><line>2</line>"
+	if musicXMLBassClef(notes) {
+		clef = "<sign>F</sign><line>4</line>"
+	}
+	fmt.Fprintf(buffered, "    <measure number=\"1\">\n      <attributes>\n        <divisions>%d</divisions>\n",
+		opts.Division)
+	fmt.Fprintf(buffered, "        <key><fifths>%d</fifths><mode>%s</mode></key>\n", opts.Key.Fifths(), mode)
+	fmt.Fprintf(buffered, "        <time><beats>%d</beats><beat-type>%d</beat-type></time>\n",
+		opts.Meter.Beats, opts.Meter.BeatUnit)
+	fmt.Fprintf(buffered, "        <clef>%s</clef>\n      </attributes>\n", clef)
+	fmt.Fprintf(buffered, "      <direction placement=\"above\">\n        <direction-type>\n          <metronome>"+
+		"<beat-unit>quarter</beat-unit><per-minute>%s</per-minute></metronome>\n        </direction-type>\n"+
+		"        <sound tempo=\"%s\"/>\n      </direction>\n", tempo, tempo)
+
+	spelling := sharpSpelling
+	if opts.Key.Fifths() < 0 {
+		spelling = flatSpelling
+	}
+	measure, open := 1, true
+	for _, note := range notes {
+		pieces := split(note, opts)
+		for i, piece := range pieces {
+			if !open {
+				measure++
+				fmt.Fprintf(buffered, "    <measure number=\"%d\">\n", measure)
+				open = true
+			}
+			writeMusicXMLNote(buffered, note, piece, spelling, i > 0, i < len(pieces)-1)
+			if piece.barEnd {
+				buffered.WriteString("    </measure>\n")
+				open = false
+			}
+		}
+	}
+	if open {
+		buffered.WriteString("    </measure>\n")
+	}
+
+	buffered.WriteString("  </part>\n</score-partwise>\n")
+	return buffered.Flush()
+}
+
+// writeMusicXMLNote writes a piece of the note, tied to the previous and next pieces as given.
+func writeMusicXMLNote(w *bufio.Writer, note Note, piece piece, spelling [12]abcPitchClass, tieStop, tieStart bool) {
+	w.WriteString("      <note>\n")
+	if note.Rest {
+		w.WriteString("        <rest/>\n")
+	} else {
+		pitchClass := spelling[note.MIDI%12]
+		w.WriteString("        <pitch><step>" + string(pitchClass.letter) + "</step>")
+		if pitchClass.accidental != 0 {
+			fmt.Fprintf(w, "<alter>%d</alter>", pitchClass.accidental)
+		}
+		fmt.Fprintf(w, "<octave>%d</octave></pitch>\n", note.MIDI/12-1)
+	}
+	fmt.Fprintf(w, "        <duration>%d</duration>\n", piece.length)
+	if tieStop {
+		w.WriteString("        <tie type=\"stop\"/>\n")
+	}
+	if tieStart {
+		w.WriteString("        <tie type=\"start\"/>\n")
+	}
+	fmt.Fprintf(w, "        <voice>1</voice>\n        <type>%s</type>\n", musicXMLTypes[piece.denominator])
+	if piece.dotted {
+		w.WriteString("        <dot/>\n")
+	}
+	if tieStop || tieStart {
+		w.WriteString("        <notations>")
+		if tieStop {
+			w.WriteString("<tied type=\"stop\"/>")
+		}
+		if tieStart {
+			w.WriteString("<tied type=\"start\"/>")
+		}
+		w.WriteString("</notations>\n")
+	}
+	w.WriteString("      </note>\n")
+}
+
+// musicXMLBassClef reports whether the average pitch of the notes is below F3, suiting the bass clef.
+func musicXMLBassClef(notes []Note) bool {
+	var sum, count int
+	for _, note := range notes {
+		if !note.Rest {
+			sum += note.MIDI * note.Length
+			count += note.Length
+		}
+	}
+	return count > 0 && sum < 53*count
+}
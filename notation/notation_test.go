@@ -0,0 +1,136 @@
+package notation_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+	"github.com/FreibergVlad/go-yinfft/notation"
+)
+
+// melody is C4 for a quarter note, a quarter rest, E4 for a dotted half tied over the bar line and A3 for an eighth,
+// at 120 BPM.
+var melody = []music.NoteEvent{
+	{Onset: 0.01, Duration: 0.48, MIDI: 60},
+	{Onset: 1.0, Duration: 1.5, MIDI: 64},
+	{Onset: 2.5, Duration: 0.26, MIDI: 57},
+}
+
+func TestQuantize(t *testing.T) {
+	t.Parallel()
+
+	notes, err := notation.Quantize(melody, notation.Options{})
+	if err != nil {
+		t.Fatalf("error quantizing events: %v", err)
+	}
+	want := []notation.Note{
+		{MIDI: 60, Start: 0, Length: 4},
+		{Rest: true, Start: 4, Length: 4},
+		{MIDI: 64, Start: 8, Length: 12},
+		{MIDI: 57, Start: 20, Length: 2},
+	}
+	if !slices.Equal(notes, want) {
+		t.Errorf("incorrect notes, got %v, want %v", notes, want)
+	}
+
+	if _, err := notation.Quantize(melody, notation.Options{Division: 3}); err == nil {
+		t.Errorf("expected error for non power of two division")
+	}
+}
+
+func TestWriteLilyPond(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	if err := notation.WriteLilyPond(&buffer, melody, notation.Options{Title: "Test"}); err != nil {
+		t.Fatalf("error writing LilyPond: %v", err)
+	}
+	for _, want := range []string{`title = "Test"`, `\tempo 4 = 120`, `\time 4/4`, "c'4 r4 e'2~ |", "e'4 a8 "} {
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buffer.String())
+		}
+	}
+}
+
+func TestWriteABC(t *testing.T) {
+	t.Parallel()
+
+	events := append(slices.Clone(melody),
+		music.NoteEvent{Onset: 2.75, Duration: 0.25, MIDI: 65},
+		music.NoteEvent{Onset: 3.0, Duration: 0.25, MIDI: 66},
+		music.NoteEvent{Onset: 3.25, Duration: 0.25, MIDI: 66},
+		music.NoteEvent{Onset: 3.5, Duration: 0.25, MIDI: 65},
+	)
+	var buffer bytes.Buffer
+	opts := notation.Options{Title: "Test", Key: music.Key{Tonic: 7}}
+	if err := notation.WriteABC(&buffer, events, opts); err != nil {
+		t.Fatalf("error writing ABC: %v", err)
+	}
+	for _, want := range []string{"T:Test\n", "M:4/4\n", "L:1/16\n", "Q:1/4=120\n", "K:G\n", "C4 z4 E8- |\nE4 A,2 =F2 ^F2 F2 =F2 "} {
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buffer.String())
+		}
+	}
+}
+
+func TestWriteMusicXML(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	opts := notation.Options{Title: "Tom & Jerry", Key: music.Key{Tonic: 5}}
+	if err := notation.WriteMusicXML(&buffer, melody, opts); err != nil {
+		t.Fatalf("error writing MusicXML: %v", err)
+	}
+	var score struct {
+		Measures []struct {
+			Notes []struct {
+				Step     string    `xml:"pitch>step"`
+				Octave   int       `xml:"pitch>octave"`
+				Rest     *struct{} `xml:"rest"`
+				Duration int       `xml:"duration"`
+				Type     string    `xml:"type"`
+				Ties     []struct {
+					Type string `xml:"type,attr"`
+				} `xml:"tie"`
+			} `xml:"note"`
+		} `xml:"part>measure"`
+	}
+	if err := xml.Unmarshal(buffer.Bytes(), &score); err != nil {
+		t.Fatalf("error parsing MusicXML: %v\n%s", err, buffer.String())
+	}
+
+	for _, want := range []string{
+		"<work-title>Tom &amp; Jerry</work-title>", "<divisions>4</divisions>", "<fifths>-1</fifths>",
+		"<beats>4</beats><beat-type>4</beat-type>", "<per-minute>120</per-minute>", "<sign>G</sign>",
+	} {
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, buffer.String())
+		}
+	}
+	if len(score.Measures) != 2 {
+		t.Fatalf("incorrect number of measures, got %d, want 2", len(score.Measures))
+	}
+	var got []string
+	for _, measure := range score.Measures {
+		for _, note := range measure.Notes {
+			description := fmt.Sprintf("%s%d %d %s", note.Step, note.Octave, note.Duration, note.Type)
+			if note.Rest != nil {
+				description = fmt.Sprintf("rest %d %s", note.Duration, note.Type)
+			}
+			for _, tie := range note.Ties {
+				description += " tie-" + tie.Type
+			}
+			got = append(got, description)
+		}
+	}
+	want := []string{
+		"C4 4 quarter", "rest 4 quarter", "E4 8 half tie-start", "E4 4 quarter tie-stop", "A3 2 eighth",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("incorrect notes, got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+// Package notation renders transcribed note events as sheet music notation.
+package notation
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// Default values used for zero Options fields.
+const (
+	DefaultTempo    = 120.0
+	DefaultDivision = 4
+)
+
+type (
+	// Meter is a time signature.
+	Meter struct {
+		Beats    int // Number of beats per bar.
+		BeatUnit int // Note value of a beat, e.g. 4 for a quarter note.
+	}
+	// Options configure the quantization and rendering of note events.
+	Options struct {
+		Title    string    // Optional title of the piece.
+		Tempo    float64   // Tempo in quarter notes per minute, DefaultTempo is used if zero.
+		Division int       // Grid subdivisions per quarter note, a power of two, DefaultDivision is used if zero.
+		Meter    Meter     // Time signature, 4/4 is used if zero.
+		Key      music.Key // Key of the piece, e.g. from music.EstimateKey.
+	}
+	// Note is a note or a rest quantized to the grid.
+	Note struct {
+		MIDI   int  // MIDI note number, unused for rests.
+		Rest   bool // Whether this is a rest.
+		Start  int  // Start in grid units.
+		Length int  // Length in grid units.
+	}
+)
+
+// CommonTime is the 4/4 meter.
+var CommonTime = Meter{Beats: 4, BeatUnit: 4}
+
+// Quantize snaps the onsets and ends of the events to the grid and returns a monophonic sequence of notes and rests
+// starting at time zero. Notes shorter than half a grid unit are dropped and overlapping notes are truncated.
+func Quantize(events []music.NoteEvent, opts Options) ([]Note, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b music.NoteEvent) int {
+		return cmp.Compare(a.Onset, b.Onset)
+	})
+
+	unitsPerSecond := opts.Tempo / 60 * float64(opts.Division)
+	var notes []Note
+	position := 0
+	for i, event := range events {
+		start := int(math.Round(event.Onset * unitsPerSecond))
+		end := int(math.Round(event.End() * unitsPerSecond))
+		if i+1 < len(events) {
+			end = min(end, int(math.Round(events[i+1].Onset*unitsPerSecond)))
+		}
+		start = max(start, position)
+		if end <= start {
+			continue
+		}
+		if start > position {
+			notes = append(notes, Note{Rest: true, Start: position, Length: start - position})
+		}
+		notes = append(notes, Note{MIDI: event.MIDI, Start: start, Length: end - start})
+		position = end
+	}
+
+	return notes, nil
+}
+
+// withDefaults returns the options with zero fields replaced by their defaults, validating the result.
+func (o Options) withDefaults() (Options, error) {
+	if o.Tempo == 0 {
+		o.Tempo = DefaultTempo
+	}
+	if o.Division == 0 {
+		o.Division = DefaultDivision
+	}
+	if o.Meter == (Meter{}) {
+		o.Meter = CommonTime
+	}
+
+	if o.Tempo < 0 || math.IsInf(o.Tempo, 0) || math.IsNaN(o.Tempo) {
+		return o, fmt.Errorf("invalid 'Tempo': expected positive value, got %v", o.Tempo)
+	}
+	if o.Division < 0 || o.Division&(o.Division-1) != 0 {
+		return o, fmt.Errorf("invalid 'Division': expected power of two, got %d", o.Division)
+	}
+	if o.Meter.Beats <= 0 || o.Meter.BeatUnit <= 0 || o.Meter.BeatUnit&(o.Meter.BeatUnit-1) != 0 ||
+		4*o.Division%o.Meter.BeatUnit != 0 {
+		return o, fmt.Errorf("invalid 'Meter': %d/%d can't be represented on the grid", o.Meter.Beats, o.Meter.BeatUnit)
+	}
+	return o, nil
+}
+
+// barLength returns the length of a bar in grid units.
+func (o Options) barLength() int {
+	return o.Meter.Beats * 4 * o.Division / o.Meter.BeatUnit
+}
+
+// piece is a part of a note with a length directly representable in notation.
+type piece struct {
+	denominator int  // Note value, e.g. 4 for a quarter note.
+	dotted      bool // Whether the note value is dotted.
+	length      int  // Length in grid units.
+	barEnd      bool // Whether the piece ends a bar.
+}
+
+// split splits the note at the bar lines and into representable, possibly dotted, note values, largest first.
+func split(note Note, opts Options) []piece {
+	wholeLength := 4 * opts.Division
+	barLength := opts.barLength()
+
+	var pieces []piece
+	position, remaining := note.Start, note.Length
+	for remaining > 0 {
+		segment := min(remaining, barLength-position%barLength)
+		for segment > 0 {
+			var next piece
+			for denominator := 1; denominator <= wholeLength; denominator *= 2 {
+				length := wholeLength / denominator
+				if length%2 == 0 && length*3/2 <= segment {
+					next = piece{denominator: denominator, dotted: true, length: length * 3 / 2}
+					break
+				}
+				if length <= segment {
+					next = piece{denominator: denominator, length: length}
+					break
+				}
+			}
+			position += next.length
+			segment -= next.length
+			remaining -= next.length
+			next.barEnd = position%barLength == 0
+			pieces = append(pieces, next)
+		}
+	}
+	return pieces
+}
@@ -0,0 +1,208 @@
+// Package onset detects note onsets with spectral flux or high-frequency content, computed on the windowed magnitude
+// spectra of the same pipeline that feeds the pitch detector, so note segmentation and rhythm-aware applications
+// don't need a second DSP library.
+package onset
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// Method defines the onset detection function.
+type Method string
+
+const (
+	// MethodSpectralFlux sums the increases of the bin magnitudes over the previous frame, responding to pitched and
+	// percussive onsets alike.
+	MethodSpectralFlux Method = "flux"
+	// MethodHFC is the increase of the high-frequency content, the energy of the bins weighted by their index, over
+	// the previous frame. It emphasizes the broadband attacks of percussive and plucked notes.
+	MethodHFC Method = "hfc"
+)
+
+type (
+	// Params configure a Detector.
+	Params struct {
+		Method       Method            // Onset detection function, MethodSpectralFlux if empty.
+		SampleRate   float64           // Sample rate of the signal in Hz.
+		FrameSize    int               // Number of samples of the analysis frames.
+		HopSize      int               // Number of samples between successive frames.
+		Window       yinfft.WindowType // Analysis window, yinfft.WindowHann if empty; the Kaiser window isn't supported.
+		Threshold    float64           // Height above the moving median of the normalized detection function, in [0, 1).
+		MedianWindow int               // Number of frames on each side of the moving median.
+		MinInterval  float64           // Minimum time between onsets in seconds.
+	}
+	// Detector computes onset detection functions and picks onsets from them. It keeps the previous spectrum
+	// between frames, so it must not be shared across streams.
+	Detector struct {
+		params   Params
+		window   []float64
+		frame    []float64
+		work     []complex128
+		spectrum []float64
+		previous []float64 // Spectrum of the previous frame, zero before the first one.
+		hfc      float64   // High-frequency content of the previous frame.
+	}
+)
+
+// DefaultParams detect onsets with spectral flux at 44.1 kHz, with a resolution of about 12 ms.
+var DefaultParams = Params{
+	Method:       MethodSpectralFlux,
+	SampleRate:   44100,
+	FrameSize:    2048,
+	HopSize:      512,
+	Window:       yinfft.WindowHann,
+	Threshold:    0.1,
+	MedianWindow: 4,
+	MinInterval:  0.05,
+}
+
+// New creates a Detector with the given params.
+func New(params Params) (*Detector, error) {
+	switch params.Method {
+	case "":
+		params.Method = MethodSpectralFlux
+	case MethodSpectralFlux, MethodHFC:
+	default:
+		return nil, fmt.Errorf("invalid 'Method': %s", params.Method)
+	}
+	if !(params.SampleRate > 0) {
+		return nil, fmt.Errorf("invalid 'SampleRate': expected positive value, got %.2f", params.SampleRate)
+	}
+	if params.FrameSize < 2 {
+		return nil, fmt.Errorf("invalid 'FrameSize': expected value of at least 2, got %d", params.FrameSize)
+	}
+	if params.HopSize < 1 {
+		return nil, fmt.Errorf("invalid 'HopSize': expected positive value, got %d", params.HopSize)
+	}
+	if params.Threshold < 0 || params.Threshold >= 1 {
+		return nil, fmt.Errorf("invalid 'Threshold': expected value in range [0, 1), got %.2f", params.Threshold)
+	}
+	if params.MedianWindow < 0 {
+		return nil, fmt.Errorf("invalid 'MedianWindow': expected non-negative value, got %d", params.MedianWindow)
+	}
+	if params.MinInterval < 0 {
+		return nil, fmt.Errorf("invalid 'MinInterval': expected non-negative value, got %.2f", params.MinInterval)
+	}
+	if params.Window == "" {
+		params.Window = yinfft.WindowHann
+	}
+	window, err := internal.Window(string(params.Window), params.FrameSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'Window': %w", err)
+	}
+
+	bins := params.FrameSize/2 + 1
+	return &Detector{
+		params:   params,
+		window:   window,
+		frame:    make([]float64, params.FrameSize),
+		work:     make([]complex128, params.FrameSize),
+		spectrum: make([]float64, bins),
+		previous: make([]float64, bins),
+	}, nil
+}
+
+// Params returns the params of the detector.
+func (d *Detector) Params() Params {
+	return d.params
+}
+
+// Process returns the onset detection function of the next frame of the stream, which must have FrameSize samples.
+// The frame is not modified. Values are not normalized, they grow with the level of the signal.
+func (d *Detector) Process(frame []float64) (float64, error) {
+	if len(frame) != d.params.FrameSize {
+		return 0, fmt.Errorf("%w: expected %d, got %d", yinfft.ErrInvalidFrameSize, d.params.FrameSize, len(frame))
+	}
+	copy(d.frame, frame)
+	internal.PrepareSpectrumInto(d.spectrum, d.work, d.frame, d.frame, d.window, 0)
+
+	var value float64
+	switch d.params.Method {
+	case MethodHFC:
+		var hfc float64
+		for k, magnitude := range d.spectrum {
+			hfc += float64(k) * magnitude * magnitude
+		}
+		hfc /= float64(len(d.spectrum))
+		value = max(0, hfc-d.hfc)
+		d.hfc = hfc
+	default:
+		for k, magnitude := range d.spectrum {
+			value += max(0, magnitude-d.previous[k])
+		}
+	}
+	copy(d.previous, d.spectrum)
+	return value, nil
+}
+
+// Reset forgets the previous frame, e.g. before processing a new stream.
+func (d *Detector) Reset() {
+	clear(d.previous)
+	d.hfc = 0
+}
+
+// Function resets the detector and returns the onset detection function of every frame fully contained in the
+// signal, the i-th frame starting at sample i*HopSize.
+func (d *Detector) Function(signal []float64) ([]float64, error) {
+	d.Reset()
+	if len(signal) < d.params.FrameSize {
+		return nil, nil
+	}
+	values := make([]float64, (len(signal)-d.params.FrameSize)/d.params.HopSize+1)
+	for i := range values {
+		start := i * d.params.HopSize
+		value, err := d.Process(signal[start : start+d.params.FrameSize])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Detect returns the onset times of the signal in seconds. Onsets are the local maxima of the detection function,
+// normalized to a maximum of 1, that exceed its moving median by Threshold and follow the previous onset by at
+// least MinInterval. An onset is timed at the center of its frame, where a change of the signal contributes most to
+// the detection function.
+func (d *Detector) Detect(signal []float64) ([]float64, error) {
+	values, err := d.Function(signal)
+	if err != nil {
+		return nil, err
+	}
+	var peak float64
+	for _, value := range values {
+		peak = max(peak, value)
+	}
+	if peak == 0 {
+		return nil, nil
+	}
+	normalized := make([]float64, len(values))
+	for i, value := range values {
+		normalized[i] = value / peak
+	}
+
+	var onsets []float64
+	median := make([]float64, 0, 2*d.params.MedianWindow+1)
+	for i, value := range normalized {
+		if i > 0 && value <= normalized[i-1] || i+1 < len(normalized) && value < normalized[i+1] {
+			continue
+		}
+		from, to := max(0, i-d.params.MedianWindow), min(len(normalized), i+d.params.MedianWindow+1)
+		median = append(median[:0], normalized[from:to]...)
+		slices.Sort(median)
+		if value < median[len(median)/2]+d.params.Threshold {
+			continue
+		}
+
+		time := (float64(i*d.params.HopSize) + float64(d.params.FrameSize)/2) / d.params.SampleRate
+		if len(onsets) > 0 && time-onsets[len(onsets)-1] < d.params.MinInterval {
+			continue
+		}
+		onsets = append(onsets, time)
+	}
+	return onsets, nil
+}
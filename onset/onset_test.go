@@ -0,0 +1,120 @@
+package onset_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/onset"
+)
+
+// notes returns a signal of decaying tones starting at the given times in seconds.
+func notes(sampleRate, length float64, onsets []float64, frequencies []float64) []float64 {
+	signal := make([]float64, int(length*sampleRate))
+	for i, start := range onsets {
+		for n := int(start * sampleRate); n < len(signal); n++ {
+			t := float64(n)/sampleRate - start
+			signal[n] += math.Exp(-4*t) * math.Sin(2*math.Pi*frequencies[i]*t)
+		}
+	}
+	return signal
+}
+
+func TestDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	onsets := []float64{0.25, 0.75, 1.1, 1.6}
+	signal := notes(onset.DefaultParams.SampleRate, 2, onsets, []float64{220, 330, 440, 262})
+
+	tests := []struct {
+		name   string
+		method onset.Method
+	}{
+		{"spectral flux", onset.MethodSpectralFlux},
+		{"high-frequency content", onset.MethodHFC},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := onset.DefaultParams
+			params.Method = test.method
+			detector, err := onset.New(params)
+			if err != nil {
+				t.Fatalf("error creating detector: %v", err)
+			}
+			detected, err := detector.Detect(signal)
+			if err != nil {
+				t.Fatalf("error detecting onsets: %v", err)
+			}
+			if len(detected) != len(onsets) {
+				t.Fatalf("incorrect number of onsets, got %.3f, want %.3f", detected, onsets)
+			}
+			for i, time := range detected {
+				if math.Abs(time-onsets[i]) > 0.02 {
+					t.Errorf("incorrect onset %d, got %.3f s, want %.3f s", i, time, onsets[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDetector_Process(t *testing.T) {
+	t.Parallel()
+
+	detector, err := onset.New(onset.DefaultParams)
+	if err != nil {
+		t.Fatalf("error creating detector: %v", err)
+	}
+	frameSize := onset.DefaultParams.FrameSize
+
+	silence, err := detector.Process(make([]float64, frameSize))
+	if err != nil {
+		t.Fatalf("error processing frame: %v", err)
+	}
+	tone := notes(onset.DefaultParams.SampleRate, 0.1, []float64{0}, []float64{440})[:frameSize]
+	attack, err := detector.Process(tone)
+	if err != nil {
+		t.Fatalf("error processing frame: %v", err)
+	}
+	if silence != 0 || attack <= 0 {
+		t.Errorf("incorrect detection function, got %g for silence and %g for an attack", silence, attack)
+	}
+
+	if _, err := detector.Process(make([]float64, frameSize-1)); !errors.Is(err, yinfft.ErrInvalidFrameSize) {
+		t.Errorf("expected ErrInvalidFrameSize, got %v", err)
+	}
+	if onsets, err := detector.Detect(make([]float64, 4*frameSize)); err != nil || len(onsets) != 0 {
+		t.Errorf("expected no onsets in silence, got %v, %v", onsets, err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(*onset.Params)
+	}{
+		{"unknown method", func(p *onset.Params) { p.Method = "energy" }},
+		{"zero sample rate", func(p *onset.Params) { p.SampleRate = 0 }},
+		{"zero hop size", func(p *onset.Params) { p.HopSize = 0 }},
+		{"threshold of 1", func(p *onset.Params) { p.Threshold = 1 }},
+		{"negative median window", func(p *onset.Params) { p.MedianWindow = -1 }},
+		{"kaiser window", func(p *onset.Params) { p.Window = yinfft.WindowKaiser }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := onset.DefaultParams
+			test.modify(&params)
+			if _, err := onset.New(params); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
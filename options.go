@@ -0,0 +1,109 @@
+package yinfft
+
+// Option configures the params of a PitchDetector created with NewWithOptions.
+type Option func(*Params)
+
+// NewWithOptions creates a new PitchDetector starting from DefaultParams and applying the options in order.
+func NewWithOptions(opts ...Option) (*PitchDetector, error) {
+	params := DefaultParams
+	for _, opt := range opts {
+		opt(&params)
+	}
+	return New(params)
+}
+
+// WithFrameSize sets the length of the input audio frame in samples.
+func WithFrameSize(frameSize int) Option {
+	return func(p *Params) { p.FrameSize = frameSize }
+}
+
+// WithSampleRate sets the audio sampling rate in Hz.
+func WithSampleRate(sampleRate float64) Option {
+	return func(p *Params) { p.SampleRate = sampleRate }
+}
+
+// WithWeighting sets the weighting curve, e.g. WeightingA or WeightingCustom.
+func WithWeighting(weightingType WeightingType) Option {
+	return func(p *Params) { p.WeightingType = weightingType }
+}
+
+// WithFrequencyRange sets the minimum and maximum detectable frequencies in Hz.
+func WithFrequencyRange(minFrequency, maxFrequency float64) Option {
+	return func(p *Params) { p.MinFrequency, p.MaxFrequency = minFrequency, maxFrequency }
+}
+
+// WithTolerance sets the peak detection tolerance.
+func WithTolerance(tolerance float64) Option {
+	return func(p *Params) { p.Tolerance = tolerance }
+}
+
+// WithInterpolation sets whether to apply interpolation to the detected frequency.
+func WithInterpolation(shouldInterpolate bool) Option {
+	return func(p *Params) { p.ShouldInterpolate = shouldInterpolate }
+}
+
+// WithHopSize sets the frame advance of DetectAll in samples.
+func WithHopSize(hopSize int) Option {
+	return func(p *Params) { p.HopSize = hopSize }
+}
+
+// WithLogger sets the logger for debug messages.
+func WithLogger(logger logger) Option {
+	return func(p *Params) { p.Logger = logger }
+}
+
+// WithMissingFundamental sets whether to accept a weak or absent fundamental supported by its harmonics.
+func WithMissingFundamental(missingFundamental bool) Option {
+	return func(p *Params) { p.MissingFundamental = missingFundamental }
+}
+
+// WithOctaveCorrection sets whether to prefer half the detected period if it's a deep minimum too.
+func WithOctaveCorrection(octaveCorrection bool) Option {
+	return func(p *Params) { p.OctaveCorrection = octaveCorrection }
+}
+
+// WithZeroPadFactor sets the FFT size as a multiple of the frame size, zero-padding frames before the FFT.
+func WithZeroPadFactor(factor int) Option {
+	return func(p *Params) { p.ZeroPadFactor = factor }
+}
+
+// WithDecimation sets the factor frames are low-pass filtered and downsampled by before analysis.
+func WithDecimation(factor int) Option {
+	return func(p *Params) { p.Decimation = factor }
+}
+
+// WithWindow sets the analysis window applied before the FFT.
+func WithWindow(window WindowType) Option {
+	return func(p *Params) { p.Window = window }
+}
+
+// WithWindowFunc sets a custom analysis window applied before the FFT, overriding the named window.
+func WithWindowFunc(window WindowFunc) Option {
+	return func(p *Params) { p.WindowFunc = window }
+}
+
+// WithHPSCheck sets how the harmonic product spectrum cross-checks the octave of detected pitches.
+func WithHPSCheck(check HPSCheck) Option {
+	return func(p *Params) { p.HPSCheck = check }
+}
+
+// WithSilenceThreshold sets the RMS level in dBFS below which frames are unvoiced without analysis, e.g. -90 as in
+// aubio. Zero disables the gate.
+func WithSilenceThreshold(thresholdDB float64) Option {
+	return func(p *Params) { p.SilenceThresholdDB = thresholdDB }
+}
+
+// WithReferenceA4 sets the reference frequency of A4 in Hz used by Chroma, e.g. 442 for orchestras tuning higher.
+func WithReferenceA4(frequency float64) Option {
+	return func(p *Params) { p.ReferenceA4 = frequency }
+}
+
+// WithOnNoPitch sets what detection returns for frames without a detectable pitch.
+func WithOnNoPitch(mode NoPitchMode) Option {
+	return func(p *Params) { p.OnNoPitch = mode }
+}
+
+// WithParams replaces all params, e.g. to start from a preset before applying further options.
+func WithParams(params Params) Option {
+	return func(p *Params) { *p = params }
+}
@@ -0,0 +1,188 @@
+package yinfft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// OverlapAnalyzer detects pitch on a stream analyzed with heavily overlapping frames. Instead of recomputing the FFT
+// for every frame, the spectrum is updated per hop from the samples entering and leaving the frame, and the window
+// is applied in the frequency domain. This costs O(FrameSize * hop) per frame instead of O(FrameSize * log(FrameSize)),
+// so it pays off for small hops, e.g. for high hop rate vibrato analysis. The update is used for hops of at most
+// twice the bit length of FrameSize, e.g. 24 samples for a FrameSize of 2048 and 28 for 8192, see Incremental. For
+// larger hops, where the update would cost more than a transform, the spectrum is recomputed with a full FFT per hop,
+// which costs about as much as DetectFromFrame, e.g. for a FrameSize of 8192 and a hop of 1024. Spectra use a periodic
+// Hann window, which differs from the symmetric window used by DetectFromFrame by less than one part in FrameSize.
+//
+// Hops are sanitized and validated according to SanitizeMode and ValidateFrames, and frames below SilenceThresholdDB
+// are unvoiced without analysis, as with DetectFromFrame.
+type OverlapAnalyzer struct {
+	detector     *PitchDetector
+	hopSize      int
+	history      []float64    // Last FrameSize samples, oldest first.
+	filled       int          // Number of valid samples in history.
+	rectangular  []complex128 // Unwindowed spectrum of the current frame, bins 0 to FrameSize/2+1.
+	rotations    []complex128 // Per-bin phase rotation exp(2*pi*i*k*hop/N) of a frame advance.
+	steps        []complex128 // Per-bin phase step exp(2*pi*i*k/N) between samples.
+	coefficients []float64    // Per-bin Goertzel coefficient 2*cos(2*pi*k/N).
+	differences  []float64    // Samples entering minus samples leaving the frame in the current hop.
+	magnitude    []float64    // Windowed magnitude spectrum passed to the detector.
+	incremental  bool         // Whether the spectrum is updated per hop rather than recomputed.
+	hops         int          // Hops since the last full transform.
+	resyncPeriod int          // Number of hops after which the spectrum is recomputed to bound rounding drift.
+}
+
+// NewOverlapAnalyzer creates an OverlapAnalyzer advancing the analysis frame by hopSize samples per Push. Detectors
+// with zero-padding, decimation or a window other than Hann are not supported.
+func (pd *PitchDetector) NewOverlapAnalyzer(hopSize int) (*OverlapAnalyzer, error) {
+	frameSize := pd.params.FrameSize
+	if hopSize <= 0 || hopSize > frameSize {
+		return nil, fmt.Errorf("invalid hop size: %d, must be in range [1, %d]", hopSize, frameSize)
+	}
+	if !pd.params.isHannWindow() {
+		return nil, errors.New("only the Hann window is supported")
+	}
+	if pd.params.FFTSize() != frameSize {
+		return nil, fmt.Errorf(
+			"zero-padding and decimation are not supported, 'zeroPadFactor' is %d and 'decimation' is %d",
+			pd.params.ZeroPadFactor, pd.params.Decimation,
+		)
+	}
+
+	bins := frameSize/2 + 2
+	analyzer := &OverlapAnalyzer{
+		detector:     pd,
+		hopSize:      hopSize,
+		history:      make([]float64, frameSize),
+		rectangular:  make([]complex128, bins),
+		rotations:    make([]complex128, bins),
+		steps:        make([]complex128, bins),
+		coefficients: make([]float64, bins),
+		differences:  make([]float64, hopSize),
+		magnitude:    make([]float64, frameSize/2+1),
+		incremental:  hopSize <= 2*bits.Len(uint(frameSize)),
+		resyncPeriod: max(1, frameSize/hopSize),
+	}
+	for k := range bins {
+		angle := 2 * math.Pi * float64(k) / float64(frameSize)
+		analyzer.rotations[k] = cmplx.Rect(1, angle*float64(hopSize))
+		analyzer.steps[k] = cmplx.Rect(1, angle)
+		analyzer.coefficients[k] = 2 * math.Cos(angle)
+	}
+
+	return analyzer, nil
+}
+
+// Incremental reports whether the spectrum is updated per hop rather than recomputed with a full FFT, i.e. whether
+// the hop size is at most twice the bit length of FrameSize.
+func (a *OverlapAnalyzer) Incremental() bool {
+	return a.incremental
+}
+
+// Ready reports whether a full frame has been pushed, i.e. whether Push returns detection results.
+func (a *OverlapAnalyzer) Ready() bool {
+	return a.filled == len(a.history)
+}
+
+// Push appends exactly one hop of samples and detects the pitch of the frame ending with them. Until a full frame has
+// been pushed, zero frequency and confidence are returned. The hop is sanitized in place; a hop rejected by
+// validation, with sample indices relative to the hop, isn't appended.
+func (a *OverlapAnalyzer) Push(hop []float64) (frequency float64, confidence float64, err error) {
+	if len(hop) != a.hopSize {
+		return 0, 0, fmt.Errorf("invalid hop size: expected %d, got %d", a.hopSize, len(hop))
+	}
+	if err := a.detector.checkSamples(hop); err != nil {
+		return 0, 0, err
+	}
+
+	frameSize := len(a.history)
+	if !a.Ready() {
+		a.filled = min(frameSize, a.filled+a.hopSize)
+		a.shift(hop)
+		if !a.Ready() {
+			return 0, 0, nil
+		}
+		a.transform()
+	} else if a.hops++; !a.incremental || a.hops >= a.resyncPeriod {
+		a.shift(hop)
+		a.transform()
+	} else {
+		a.update(hop)
+		a.shift(hop)
+	}
+
+	return a.detect()
+}
+
+// Flush detects the pitch of a stream shorter than a frame, zero-padded at the start, and resets the analyzer. If
+// the stream filled a frame, nothing is pending and zero frequency and confidence are returned.
+func (a *OverlapAnalyzer) Flush() (frequency float64, confidence float64, err error) {
+	defer a.Reset()
+	if a.filled == 0 || a.Ready() {
+		return 0, 0, nil
+	}
+	a.transform()
+	return a.detect()
+}
+
+// Reset clears the pushed samples, e.g. before analyzing a new stream.
+func (a *OverlapAnalyzer) Reset() {
+	clear(a.history)
+	clear(a.rectangular)
+	a.filled = 0
+	a.hops = 0
+}
+
+// detect applies the window to the current spectrum and detects its fundamental frequency, unless the current frame
+// is below SilenceThresholdDB.
+func (a *OverlapAnalyzer) detect() (frequency float64, confidence float64, err error) {
+	if a.detector.silent(decibels(meanSquare(a.history))) {
+		return a.detector.noPitch()
+	}
+
+	// Periodic Hann window applied as the 3-tap kernel [-1/4, 1/2, -1/4] in the frequency domain.
+	for k := range a.magnitude {
+		previous := cmplx.Conj(a.rectangular[1])
+		if k > 0 {
+			previous = a.rectangular[k-1]
+		}
+		a.magnitude[k] = cmplx.Abs(0.5*a.rectangular[k] - 0.25*(previous+a.rectangular[k+1]))
+	}
+	internal.FlushToZero(a.magnitude, a.detector.params.DenormalThreshold)
+
+	return a.detector.DetectFromSpectrum(a.magnitude)
+}
+
+// update advances the unwindowed spectrum by one hop: X'[k] = exp(2*pi*i*k*hop/N) * (X[k] + sum(d[m]*W^(k*m))),
+// where d are the differences between the entering and leaving samples. The sum is evaluated per bin with the
+// Goertzel recurrence s[m] = d[m] + 2*cos(2*pi*k/N)*s[m-1] - s[m-2], which takes one real multiplication per sample,
+// and the rotated sum equals exp(2*pi*i*k/N)*s[hop-1] - s[hop-2].
+func (a *OverlapAnalyzer) update(hop []float64) {
+	for m, sample := range hop {
+		a.differences[m] = sample - a.history[m]
+	}
+	for k := range a.rectangular {
+		coefficient := a.coefficients[k]
+		previous, current := 0.0, 0.0
+		for _, difference := range a.differences {
+			previous, current = current, difference+coefficient*current-previous
+		}
+		a.rectangular[k] = a.rotations[k]*a.rectangular[k] + a.steps[k]*complex(current, 0) - complex(previous, 0)
+	}
+}
+
+// transform recomputes the unwindowed spectrum of the current frame with a full FFT.
+func (a *OverlapAnalyzer) transform() {
+	copy(a.rectangular, internal.FFTReal(a.history))
+	a.hops = 0
+}
+
+func (a *OverlapAnalyzer) shift(hop []float64) {
+	copy(a.history, a.history[a.hopSize:])
+	copy(a.history[len(a.history)-a.hopSize:], hop)
+}
@@ -0,0 +1,214 @@
+package yinfft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// Validate checks all params and returns every violation found, joined with errors.Join, or nil if the params are
+// valid. New validates its params the same way.
+func (p Params) Validate() error {
+	var errs []error
+
+	if p.FrameSize < 4 {
+		errs = append(errs, fmt.Errorf("invalid 'frameSize': %d, must be at least 4", p.FrameSize))
+	}
+	if !(p.SampleRate > 0) || math.IsInf(p.SampleRate, 0) {
+		errs = append(errs, fmt.Errorf("invalid 'sampleRate': %g, must be positive", p.SampleRate))
+	}
+	if !(p.Tolerance > 0 && p.Tolerance <= 1) {
+		errs = append(errs, fmt.Errorf("invalid 'tolerance': %g, must be in range (0, 1]", p.Tolerance))
+	}
+
+	switch {
+	case !(p.MinFrequency > 0) || !(p.MaxFrequency > p.MinFrequency):
+		errs = append(errs, fmt.Errorf(
+			"invalid frequency range: [%g, %g] Hz, 'minFrequency' must be positive and below 'maxFrequency'",
+			p.MinFrequency, p.MaxFrequency,
+		))
+	case p.analysisFrameSize() >= 4 && p.SampleRate > 0:
+		if minPeriodSamples, maxPeriodSamples := p.periodRange(); maxPeriodSamples <= minPeriodSamples {
+			minDetectable := p.SampleRate / float64(p.FrameSize/2)
+			errs = append(errs, fmt.Errorf(
+				"maxFrequency <= minFrequency or out of range; min detectable = %.2f Hz", minDetectable,
+			))
+		}
+	}
+
+	if _, ok := weightingCurves[p.WeightingType.normalized()]; !ok {
+		errs = append(errs, fmt.Errorf(
+			"%w: %s; available weighting types: %+q", ErrInvalidWeightingType, p.WeightingType, availableWeightingTypes,
+		))
+	}
+	if !slices.Contains([]SanitizeMode{SanitizeNone, SanitizeZero, SanitizeClamp}, p.SanitizeMode) {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'sanitizeMode': %s, must be one of [%s, %s]", p.SanitizeMode, SanitizeZero, SanitizeClamp,
+		))
+	}
+	if !slices.Contains([]NoPitchMode{NoPitchZero, NoPitchNaN, NoPitchError}, p.OnNoPitch) {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'onNoPitch': %s, must be one of [%s, %s]", p.OnNoPitch, NoPitchNaN, NoPitchError,
+		))
+	}
+	if !slices.Contains([]HPSCheck{HPSCheckNone, HPSCheckFlag, HPSCheckCorrect}, p.HPSCheck) {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'hpsCheck': %s, must be one of [%s, %s]", p.HPSCheck, HPSCheckFlag, HPSCheckCorrect,
+		))
+	}
+	if p.HopSize < 0 || p.HopSize > p.FrameSize {
+		errs = append(errs, fmt.Errorf("invalid 'hopSize': %d, must be in range [0, %d]", p.HopSize, p.FrameSize))
+	}
+	if !(p.SilenceThresholdDB <= 0) {
+		errs = append(errs, fmt.Errorf("invalid 'silenceThresholdDB': %g, must not be positive", p.SilenceThresholdDB))
+	}
+	if p.ReferenceA4 < 0 || math.IsNaN(p.ReferenceA4) || math.IsInf(p.ReferenceA4, 0) {
+		errs = append(errs, fmt.Errorf("invalid 'referenceA4': %g Hz, must be positive or zero", p.ReferenceA4))
+	}
+	if p.DenormalThreshold < 0 {
+		errs = append(errs, fmt.Errorf("'denormalThreshold' must not be negative, got %g", p.DenormalThreshold))
+	}
+	if p.Decimation < 0 || p.Decimation > 1 && (p.FrameSize%p.Decimation != 0 || p.FrameSize/p.Decimation < 4) {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'decimation': %d, must be zero or divide 'frameSize' into at least 4 samples", p.Decimation,
+		))
+	} else if cutoff := internal.DecimationCutoff * p.analysisSampleRate(); p.Decimation > 1 && p.MaxFrequency > cutoff {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'maxFrequency': %g Hz, must not exceed %.2f Hz with decimation by %d", p.MaxFrequency, cutoff,
+			p.Decimation,
+		))
+	}
+	if _, err := p.windowFunction(); err != nil {
+		errs = append(errs, err)
+	}
+	if p.KaiserBeta < 0 {
+		errs = append(errs, fmt.Errorf("invalid 'kaiserBeta': %g, must not be negative", p.KaiserBeta))
+	}
+	if p.ZeroPadFactor < 0 || p.ZeroPadFactor&(p.ZeroPadFactor-1) != 0 {
+		errs = append(errs, fmt.Errorf("invalid 'zeroPadFactor': %d, must be zero or a power of two", p.ZeroPadFactor))
+	} else if p.BinWeights != nil && len(p.BinWeights) != p.FFTSize()/2+1 {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'binWeights' length: expected %d, got %d", p.FFTSize()/2+1, len(p.BinWeights),
+		))
+	}
+	if i := slices.IndexFunc(p.BinWeights, isInvalidWeight); i >= 0 {
+		errs = append(errs, fmt.Errorf(
+			"invalid 'binWeights' value of bin %d: %g, must be finite and not negative", i, p.BinWeights[i],
+		))
+	}
+
+	return errors.Join(errs...)
+}
+
+// isInvalidWeight reports whether a spectrum weight multiplier is negative, NaN or infinite.
+func isInvalidWeight(weight float64) bool {
+	return !(weight >= 0) || math.IsInf(weight, 1)
+}
+
+// SetFrequencyRange changes the detectable frequency range without recreating the detector, e.g. when a tuner switches
+// between instrument presets. It must not be called concurrently with detection.
+func (pd *PitchDetector) SetFrequencyRange(minFrequency, maxFrequency float64) error {
+	params := pd.params
+	params.MinFrequency, params.MaxFrequency = minFrequency, maxFrequency
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	minPeriodSamples, maxPeriodSamples := params.periodRange()
+	if err := pd.peakDetector.SetPositionRange(float64(minPeriodSamples), float64(maxPeriodSamples)); err != nil {
+		return fmt.Errorf("failed to update peak detection algorithm: %w", err)
+	}
+	pd.params = params
+	pd.minPeriodSamples, pd.maxPeriodSamples = minPeriodSamples, maxPeriodSamples
+	return nil
+}
+
+// FFTSize returns the length of the FFT frames are zero-padded to, FrameSize divided by Decimation times
+// ZeroPadFactor. Spectra passed to DetectFromSpectrum have FFTSize()/2+1 bins.
+func (p Params) FFTSize() int {
+	return p.analysisFrameSize() * max(1, p.ZeroPadFactor)
+}
+
+// decimationFactor returns the factor frames are downsampled by before analysis, one if decimation is disabled.
+func (p Params) decimationFactor() int {
+	return max(1, p.Decimation)
+}
+
+// analysisFrameSize returns the length of frames after decimation.
+func (p Params) analysisFrameSize() int {
+	return p.FrameSize / p.decimationFactor()
+}
+
+// analysisSampleRate returns the sample rate of frames after decimation.
+func (p Params) analysisSampleRate() float64 {
+	return p.SampleRate / float64(p.decimationFactor())
+}
+
+// periodRange returns the range of detectable periods in samples of the analysis sample rate.
+func (p Params) periodRange() (minPeriodSamples int, maxPeriodSamples int) {
+	sampleRate, halfFrame := p.analysisSampleRate(), float64(p.analysisFrameSize()/2)
+	maxPeriodSamples = int(math.Min(math.Ceil(sampleRate/p.MinFrequency), halfFrame))
+	minPeriodSamples = int(math.Min(math.Floor(sampleRate/p.MaxFrequency), halfFrame))
+	return minPeriodSamples, maxPeriodSamples
+}
+
+// LoadParams reads JSON encoded params, as written by encoding/json, and validates them. Fields missing from the
+// input keep their DefaultParams values, unknown fields are rejected.
+func LoadParams(r io.Reader) (Params, error) {
+	params := DefaultParams
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return Params{}, fmt.Errorf("failed to decode params: %w", err)
+	}
+	if err := params.Validate(); err != nil {
+		return Params{}, err
+	}
+	return params, nil
+}
+
+// MarshalYAML implements the marshaler interface of the YAML libraries, encoding the params with the same keys as
+// JSON.
+func (p Params) MarshalYAML() (any, error) {
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// UnmarshalYAML implements the unmarshaler interface of the YAML libraries, decoding the params with the same keys as
+// JSON. Like LoadParams, it rejects unknown keys and validates the result. Fields missing from the input are left
+// unchanged, so decoding into a copy of DefaultParams fills in defaults.
+func (p *Params) UnmarshalYAML(unmarshal func(any) error) error {
+	var fields map[string]any
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to decode params: %w", err)
+	}
+
+	params := *p
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return fmt.Errorf("failed to decode params: %w", err)
+	}
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	*p = params
+	return nil
+}
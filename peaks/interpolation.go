@@ -0,0 +1,69 @@
+package peaks
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interpolation is the method refining peak positions and amplitudes between input values.
+type Interpolation string
+
+const (
+	// InterpolationParabolic fits a parabola through the peak and its neighbours, the default if empty.
+	InterpolationParabolic Interpolation = "parabolic"
+	// InterpolationGaussian fits a parabola through the logarithms of the values, i.e. in the log-magnitude domain
+	// the CCRMA method assumes. It is exact for Gaussian shaped peaks, such as those of Gaussian windowed spectra,
+	// and much closer than a linear fit for the main lobes of other windows. Needs positive values.
+	InterpolationGaussian Interpolation = "gaussian"
+	// InterpolationJain estimates the position from the ratio of the peak to its larger neighbour (Jain et al.,
+	// 1979), with the amplitude of the parabolic fit at that position. Needs positive values.
+	InterpolationJain Interpolation = "jain"
+)
+
+// validateInterpolation checks the interpolation method of the params.
+func validateInterpolation(params Params) error {
+	switch params.Interpolation {
+	case "", InterpolationParabolic:
+		return nil
+	case InterpolationGaussian, InterpolationJain:
+		if params.Valleys {
+			return fmt.Errorf("%s interpolation is not supported for valleys", params.Interpolation)
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"invalid Interpolation value: %s, must be one of [%s, %s, %s]",
+			params.Interpolation, InterpolationParabolic, InterpolationGaussian, InterpolationJain,
+		)
+	}
+}
+
+// interpolate refines the peak at currentBin with the configured method. Methods needing positive values fall back
+// to the parabolic fit otherwise.
+func (pd *Detector) interpolate(leftVal, middleVal, rightVal float64, currentBin int) (resultVal, resultBin float64) {
+	positive := leftVal > 0 && middleVal > 0 && rightVal > 0
+	switch {
+	case pd.params.Interpolation == InterpolationGaussian && positive:
+		logVal, logBin := interpolate(math.Log(leftVal), math.Log(middleVal), math.Log(rightVal), currentBin)
+		return math.Exp(logVal), logBin
+	case pd.params.Interpolation == InterpolationJain && positive:
+		return interpolateJain(leftVal, middleVal, rightVal, currentBin)
+	default:
+		return interpolate(leftVal, middleVal, rightVal, currentBin)
+	}
+}
+
+// interpolateJain implements Jain's method: with a the ratio of the larger neighbour to the peak, the true peak lies
+// a/(1+a) bins from the peak towards that neighbour. The amplitude is that of the parabola through the three values.
+func interpolateJain(leftVal, middleVal, rightVal float64, currentBin int) (resultVal, resultBin float64) {
+	deltaX := 0.0
+	if leftVal > rightVal {
+		ratio := leftVal / middleVal
+		deltaX = -ratio / (1 + ratio)
+	} else {
+		ratio := rightVal / middleVal
+		deltaX = ratio / (1 + ratio)
+	}
+	resultVal = middleVal + 0.5*(rightVal-leftVal)*deltaX + 0.5*(leftVal-2*middleVal+rightVal)*deltaX*deltaX
+	return resultVal, float64(currentBin) + deltaX
+}
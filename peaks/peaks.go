@@ -0,0 +1,291 @@
+// Package peaks finds peaks in sampled data such as magnitude spectra, with optional parabolic interpolation of their
+// positions and amplitudes, a minimum distance between peaks and ordering by position or amplitude. It is the peak
+// picking used by the pitch detector, usable on its own e.g. for sinusoidal analysis of spectra.
+package peaks
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type peak struct {
+	position  float64
+	magnitude float64
+}
+
+// OrderBy is the order of detected peaks.
+type OrderBy string
+
+const (
+	OrderByPosition  OrderBy = "position"  // Ascending position.
+	OrderByAmplitude OrderBy = "amplitude" // Descending amplitude, or ascending for valleys.
+)
+
+// Params configure a Detector. Positions are in units of Range, e.g. Hz for a spectrum with Range set to the Nyquist
+// frequency, or samples with Range set to len(input)-1.
+type Params struct {
+	Range             float64       // Position of the last input value, the first one is at zero.
+	MaxPeaks          int           // Maximum number of peaks returned.
+	MaxPosition       float64       // Maximum position of peaks.
+	MinPosition       float64       // Minimum position of peaks.
+	Threshold         float64       // Minimum amplitude of peaks or maximum of valleys, infinite to keep all of them.
+	OrderBy           OrderBy       // Order of the returned peaks.
+	ShouldInterpolate bool          // Whether peak positions and amplitudes are refined by interpolation.
+	Interpolation     Interpolation // Method of ShouldInterpolate, parabolic if empty.
+	MinPeakDistance   float64       // Minimum distance between peaks, weaker peaks closer to a stronger one are dropped.
+	Valleys           bool          // Whether local minima are detected instead of maxima, deepest first by amplitude.
+	// Minimum amplitude of peaks in dB relative to the strongest peak, e.g. -40 keeps peaks within 40 dB of it, for
+	// linear magnitudes such as spectra whose level varies. Disabled if zero, not supported with Valleys.
+	RelativeThreshold float64
+}
+
+// Detector finds peaks in sampled data. A Detector is safe for concurrent use as long as SetPositionRange isn't
+// called concurrently with detection.
+type Detector struct {
+	params Params
+}
+
+// Buffer holds the temporary storage of DetectInto, so repeated detections don't allocate. A Buffer must not be
+// used concurrently.
+type Buffer struct {
+	peaks      []peak
+	positions  []float64
+	amplitudes []float64
+}
+
+// NewBuffer creates a Buffer with room for inputs of up to inputLength values and up to maxPeaks peaks, so even the
+// first detection using it doesn't allocate.
+func NewBuffer(inputLength, maxPeaks int) Buffer {
+	return Buffer{
+		peaks:      make([]peak, 0, inputLength),
+		positions:  make([]float64, 0, maxPeaks),
+		amplitudes: make([]float64, 0, maxPeaks),
+	}
+}
+
+// New creates a Detector with the given params.
+func New(params Params) (*Detector, error) {
+	if params.MinPosition >= params.MaxPosition {
+		return nil, fmt.Errorf("MinPosition must be less than MaxPosition")
+	}
+	if params.MaxPeaks <= 0 {
+		return nil, fmt.Errorf("invalid MaxPeaks value: %d, must be positive", params.MaxPeaks)
+	}
+	if params.OrderBy != OrderByPosition && params.OrderBy != OrderByAmplitude {
+		return nil, fmt.Errorf("invalid OrderBy value: %s, must be one of [%s, %s]", params.OrderBy, OrderByPosition, OrderByAmplitude)
+	}
+	if err := validateInterpolation(params); err != nil {
+		return nil, err
+	}
+	if params.RelativeThreshold > 0 {
+		return nil, fmt.Errorf("invalid RelativeThreshold value: %g dB, must not be positive", params.RelativeThreshold)
+	}
+	if params.RelativeThreshold != 0 && params.Valleys {
+		return nil, fmt.Errorf("RelativeThreshold is not supported for valleys")
+	}
+	return &Detector{params: params}, nil
+}
+
+// SetPositionRange changes the range of positions peaks are searched in.
+func (pd *Detector) SetPositionRange(minPosition, maxPosition float64) error {
+	if minPosition >= maxPosition {
+		return fmt.Errorf("MinPosition must be less than MaxPosition")
+	}
+	pd.params.MinPosition, pd.params.MaxPosition = minPosition, maxPosition
+	return nil
+}
+
+// Detect returns the positions and amplitudes of the peaks of the input, at most MaxPeaks of them, in the configured
+// order. The input must have at least two values.
+func (pd *Detector) Detect(input []float64) (positions []float64, amplitudes []float64, err error) {
+	return pd.DetectInto(input, &Buffer{})
+}
+
+// DetectInto is Detect using the buffer for temporary storage. The returned slices are backed by the buffer
+// and valid until its next use.
+func (pd *Detector) DetectInto(input []float64, buffer *Buffer) (positions []float64, amplitudes []float64, err error) {
+	if len(input) < 2 {
+		return nil, nil, fmt.Errorf("input length should be >= 2")
+	}
+
+	// Valleys are detected as the peaks of the negated input, negating values as they are read.
+	sign := 1.0
+	if pd.params.Valleys {
+		sign = -1
+	}
+	value := func(i int) float64 { return sign * input[i] }
+	threshold := sign * pd.params.Threshold
+
+	scale := pd.params.Range / float64(len(input)-1)
+	peaks := slices.Grow(buffer.peaks[:0], len(input))
+	defer func() { buffer.peaks = peaks[:0] }()
+
+	i := max(0, int(math.Ceil(pd.params.MinPosition/scale)))
+
+	if i+1 < len(input) && value(i) > value(i+1) && value(i) > threshold {
+		peaks = append(peaks, peak{position: float64(i) * scale, magnitude: value(i)})
+	}
+
+	for {
+		for i+1 < len(input)-1 && value(i) >= value(i+1) {
+			i++
+		}
+		for i+1 < len(input)-1 && value(i) < value(i+1) {
+			i++
+		}
+
+		j := i
+		for j+1 < len(input)-1 && value(j) == value(j+1) {
+			j++
+		}
+
+		if j+1 < len(input)-1 && value(j+1) < value(j) && value(j) > threshold {
+			resultVal, resultBin := 0.0, 0.0
+
+			if j != i {
+				resultVal = value(i)
+				if pd.params.ShouldInterpolate {
+					resultBin = float64(i+j) * 0.5
+				} else {
+					resultBin = float64(i)
+				}
+			} else {
+				if pd.params.ShouldInterpolate {
+					resultVal, resultBin = pd.interpolate(value(j-1), value(j), value(j+1), j)
+				} else {
+					resultVal, resultBin = value(j), float64(j)
+				}
+			}
+
+			resultPos := resultBin * scale
+			if resultPos > pd.params.MaxPosition {
+				break
+			}
+			peaks = append(peaks, peak{position: resultPos, magnitude: resultVal})
+		}
+
+		i = j
+
+		if i+1 >= len(input)-1 {
+			if i == len(input)-2 && value(i-1) < value(i) && value(i+1) < value(i) && value(i) > threshold {
+				resultBin, resultVal := 0.0, 0.0
+				if pd.params.ShouldInterpolate {
+					resultVal, resultBin = pd.interpolate(value(i-1), value(i), value(i+1), i)
+				} else {
+					resultVal, resultBin = value(i), float64(i)
+				}
+				peaks = append(peaks, peak{position: resultBin * scale, magnitude: resultVal})
+			}
+			break
+		}
+	}
+
+	pos := pd.params.MaxPosition / scale
+	if float64(len(input)-2) < pos && pos <= float64(len(input)-1) && value(len(input)-1) > value(len(input)-2) && value(len(input)-1) > threshold {
+		peaks = append(peaks, peak{position: float64(len(input)-1) * scale, magnitude: value(len(input) - 1)})
+	}
+
+	if pd.params.RelativeThreshold < 0 && len(peaks) > 1 {
+		strongest := math.Inf(-1)
+		for _, peak := range peaks {
+			strongest = max(strongest, peak.magnitude)
+		}
+		minMagnitude := strongest * math.Pow(10, pd.params.RelativeThreshold/20)
+		peaks = slices.DeleteFunc(peaks, func(peak peak) bool { return peak.magnitude < minMagnitude })
+	}
+
+	if pd.params.MinPeakDistance > 0 && len(peaks) > 1 {
+		sortPeaksByMagnitude(peaks)
+
+		for k := range len(peaks) - 1 {
+			deletedPeaks := make([]int, 0, len(peaks))
+			minPos := peaks[k].position - pd.params.MinPeakDistance
+			maxPos := peaks[k].position + pd.params.MinPeakDistance
+			for l := k + 1; l < len(peaks); l++ {
+				if peaks[l].position > minPos && peaks[l].position < maxPos {
+					deletedPeaks = append(deletedPeaks, l)
+				}
+			}
+			slices.SortFunc(deletedPeaks, func(a, b int) int {
+				return cmp.Compare(b, a)
+			})
+			for _, idx := range deletedPeaks {
+				peaks = slices.Delete(peaks, idx, idx+1)
+			}
+		}
+
+		if pd.params.OrderBy == OrderByPosition {
+			sortPeaksByPosition(peaks)
+		}
+	} else {
+		if pd.params.OrderBy == OrderByAmplitude {
+			sortPeaksByMagnitude(peaks)
+		}
+	}
+
+	wantPeaks := min(pd.params.MaxPeaks, len(peaks))
+	positions, amplitudes = buffer.positions[:0], buffer.amplitudes[:0]
+	for _, peak := range peaks[:wantPeaks] {
+		positions = append(positions, peak.position)
+		amplitudes = append(amplitudes, sign*peak.magnitude)
+	}
+	buffer.positions, buffer.amplitudes = positions, amplitudes
+
+	return positions, amplitudes, nil
+}
+
+/**
+* http://ccrma.stanford.edu/~jos/parshl/Peak_Detection_Steps_3.html
+*
+* Estimating the "true" maximum peak (frequency and magnitude) of the detected local maximum
+* using a parabolic curve-fitting. The idea is that the main-lobe of spectrum of most analysis
+* windows on a dB scale looks like a parabola and therefore the maximum of a parabola fitted
+* through a local maximum bin and it's two neighboring bins will give a good approximation
+* of the actual frequency and magnitude of a sinusoid in the input signal.
+*
+* The parabola f(x) = a(x-n)^2 + b(x-n) + c can be completely described using 3 points;
+* f(n-1) = A1, f(n) = A2 and f(n+1) = A3, where
+* A1 = 20log10(|X(n-1)|), A2 = 20log10(|X(n)|), A3 = 20log10(|X(n+1)|).
+*
+* Solving these equation yields: a = 1/2*A1 - A2 + 1/2*A3, b = 1/2*A3 - 1/2*A1 and
+* c = A2.
+*
+* As the 3 bins are known to be a maxima, solving d/dx f(x) = 0, yields the fractional bin
+* position x of the estimated peak. Substituting delta_x for (x-n) in this equation yields
+* the fractional offset in bins from n where the peak's maximum is.
+*
+* Solving this equation yields: delta_x = 1/2 * (A1 - A3)/(A1 - 2*A2 + A3).
+*
+* Computing f(n+delta_x) will estimate the peak's magnitude (in dB's):
+* f(n+delta_x) = A2 - 1/4*(A1-A3)*delta_x.
+ */
+func interpolate(leftVal, middleVal, rightVal float64, currentBin int) (resultVal, resultBin float64) {
+	deltaX := 0.5 * ((leftVal - rightVal) / (leftVal - 2*middleVal + rightVal))
+	resultVal = middleVal - 0.25*(leftVal-rightVal)*deltaX
+	resultBin = float64(currentBin) + deltaX
+	return
+}
+
+// sortPeaksByMagnitude sorts the peaks slice in place by magnitude in descending order.
+// If magnitudes are equal, it sorts by position in ascending order.
+func sortPeaksByMagnitude(peaks []peak) {
+	slices.SortFunc(peaks, func(a, b peak) int {
+		if a.magnitude != b.magnitude {
+			return cmp.Compare(b.magnitude, a.magnitude)
+		}
+		return cmp.Compare(a.position, b.position)
+	})
+}
+
+// sortPeaksByPosition sorts the peaks slice in place by position in ascending order.
+// If positions are equal, it sorts by magnitude in descending order.
+func sortPeaksByPosition(peaks []peak) {
+	slices.SortFunc(peaks, func(a, b peak) int {
+		if a.position != b.position {
+			return cmp.Compare(a.position, b.position)
+		}
+		return cmp.Compare(b.magnitude, a.magnitude)
+	})
+}
@@ -0,0 +1,328 @@
+package peaks_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft/peaks"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	// Peaks at positions 2 (amplitude 3), 5 (amplitude 5) and 7 (amplitude 4).
+	input := []float64{0, 1, 3, 1, 2, 5, 2, 4, 1, 0}
+	params := peaks.Params{
+		Range:       float64(len(input) - 1),
+		MaxPeaks:    10,
+		MaxPosition: float64(len(input) - 1),
+		Threshold:   math.Inf(-1),
+		OrderBy:     peaks.OrderByPosition,
+	}
+
+	testCases := []struct {
+		name          string
+		modify        func(*peaks.Params)
+		wantPositions []float64
+	}{
+		{name: "by position", modify: func(*peaks.Params) {}, wantPositions: []float64{2, 5, 7}},
+		{
+			name:          "by amplitude",
+			modify:        func(p *peaks.Params) { p.OrderBy = peaks.OrderByAmplitude },
+			wantPositions: []float64{5, 7, 2},
+		},
+		{
+			name:          "max peaks",
+			modify:        func(p *peaks.Params) { p.OrderBy, p.MaxPeaks = peaks.OrderByAmplitude, 1 },
+			wantPositions: []float64{5},
+		},
+		{name: "threshold", modify: func(p *peaks.Params) { p.Threshold = 3.5 }, wantPositions: []float64{5, 7}},
+		{
+			name:          "relative threshold",
+			modify:        func(p *peaks.Params) { p.RelativeThreshold = 20 * math.Log10(3.5/5) },
+			wantPositions: []float64{5, 7},
+		},
+		{name: "position range", modify: func(p *peaks.Params) { p.MinPosition = 3 }, wantPositions: []float64{5, 7}},
+		{name: "min distance", modify: func(p *peaks.Params) { p.MinPeakDistance = 2.5 }, wantPositions: []float64{2, 5}},
+		{
+			name:          "scaled range",
+			modify:        func(p *peaks.Params) { p.Range, p.MaxPosition = 90, 90 },
+			wantPositions: []float64{20, 50, 70},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := params
+			tc.modify(&params)
+			detector, err := peaks.New(params)
+			if err != nil {
+				t.Fatalf("error creating peak detector: %v", err)
+			}
+
+			positions, amplitudes, err := detector.Detect(input)
+			if err != nil {
+				t.Fatalf("error detecting peaks: %v", err)
+			}
+			if !slices.Equal(positions, tc.wantPositions) {
+				t.Errorf("incorrect positions, got %v, want %v", positions, tc.wantPositions)
+			}
+			if len(amplitudes) != len(positions) {
+				t.Errorf("incorrect number of amplitudes, got %d, want %d", len(amplitudes), len(positions))
+			}
+		})
+	}
+}
+
+func TestDetect_Interpolation(t *testing.T) {
+	t.Parallel()
+
+	// Samples of the parabola 4 - (x - 2.25)^2, of a Gaussian and of the magnitude of a sinc peaking at 2.25, whose
+	// positions are exactly recovered by the parabolic, Gaussian and Jain interpolation respectively.
+	parabola, gaussian, sinc := make([]float64, 5), make([]float64, 5), make([]float64, 5)
+	for i := range parabola {
+		offset := float64(i) - 2.25
+		parabola[i], gaussian[i] = 4-offset*offset, 4*math.Exp(-offset*offset)
+		sinc[i] = math.Abs(math.Sin(math.Pi*offset) / (math.Pi * offset))
+	}
+
+	testCases := []struct {
+		name          string
+		interpolation peaks.Interpolation
+		input         []float64
+		wantPosition  float64
+		wantAmplitude float64
+		tolerance     float64 // Of the amplitude, positions are exact.
+	}{
+		{name: "default", input: parabola, wantPosition: 2.25, wantAmplitude: 4, tolerance: 1e-9},
+		{
+			name: "parabolic", interpolation: peaks.InterpolationParabolic, input: parabola,
+			wantPosition: 2.25, wantAmplitude: 4, tolerance: 1e-9,
+		},
+		{
+			name: "gaussian", interpolation: peaks.InterpolationGaussian, input: gaussian,
+			wantPosition: 2.25, wantAmplitude: 4, tolerance: 1e-9,
+		},
+		{
+			name: "jain", interpolation: peaks.InterpolationJain, input: sinc,
+			wantPosition: 2.25, wantAmplitude: 1, tolerance: 0.15,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			detector, err := peaks.New(peaks.Params{
+				Range:             float64(len(tc.input) - 1),
+				MaxPeaks:          1,
+				MaxPosition:       float64(len(tc.input) - 1),
+				Threshold:         math.Inf(-1),
+				OrderBy:           peaks.OrderByAmplitude,
+				ShouldInterpolate: true,
+				Interpolation:     tc.interpolation,
+			})
+			if err != nil {
+				t.Fatalf("error creating peak detector: %v", err)
+			}
+
+			buffer := peaks.NewBuffer(len(tc.input), 1)
+			positions, amplitudes, err := detector.DetectInto(tc.input, &buffer)
+			if err != nil {
+				t.Fatalf("error detecting peaks: %v", err)
+			}
+			if len(positions) != 1 || math.Abs(positions[0]-tc.wantPosition) > 1e-9 ||
+				math.Abs(amplitudes[0]-tc.wantAmplitude) > tc.tolerance {
+				t.Errorf(
+					"incorrect peak, got positions %v and amplitudes %v, want %g and %g",
+					positions, amplitudes, tc.wantPosition, tc.wantAmplitude,
+				)
+			}
+		})
+	}
+}
+
+func TestDetect_Valleys(t *testing.T) {
+	t.Parallel()
+
+	// Valleys at positions 2 (value -3), 5 (value -5) and 7 (value -4), the negated input of TestDetect.
+	input := []float64{0, -1, -3, -1, -2, -5, -2, -4, -1, 0}
+	detector, err := peaks.New(peaks.Params{
+		Range:       float64(len(input) - 1),
+		MaxPeaks:    2,
+		MaxPosition: float64(len(input) - 1),
+		Threshold:   -3.5,
+		OrderBy:     peaks.OrderByAmplitude,
+		Valleys:     true,
+	})
+	if err != nil {
+		t.Fatalf("error creating peak detector: %v", err)
+	}
+
+	positions, amplitudes, err := detector.Detect(input)
+	if err != nil {
+		t.Fatalf("error detecting valleys: %v", err)
+	}
+	if want := []float64{5, 7}; !slices.Equal(positions, want) {
+		t.Errorf("incorrect positions, got %v, want %v", positions, want)
+	}
+	if want := []float64{-5, -4}; !slices.Equal(amplitudes, want) {
+		t.Errorf("incorrect amplitudes, got %v, want %v", amplitudes, want)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	t.Parallel()
+
+	valid := peaks.Params{Range: 1, MaxPeaks: 1, MaxPosition: 1, OrderBy: peaks.OrderByPosition}
+	testCases := []struct {
+		name   string
+		modify func(*peaks.Params)
+	}{
+		{name: "empty position range", modify: func(p *peaks.Params) { p.MinPosition = p.MaxPosition }},
+		{name: "no peaks", modify: func(p *peaks.Params) { p.MaxPeaks = 0 }},
+		{name: "unknown order", modify: func(p *peaks.Params) { p.OrderBy = "frequency" }},
+		{name: "unknown interpolation", modify: func(p *peaks.Params) { p.Interpolation = "cubic" }},
+		{
+			name:   "gaussian interpolation of valleys",
+			modify: func(p *peaks.Params) { p.Interpolation, p.Valleys = peaks.InterpolationGaussian, true },
+		},
+		{name: "positive relative threshold", modify: func(p *peaks.Params) { p.RelativeThreshold = 6 }},
+		{
+			name:   "relative threshold for valleys",
+			modify: func(p *peaks.Params) { p.RelativeThreshold, p.Valleys = -40, true },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := valid
+			tc.modify(&params)
+			if _, err := peaks.New(params); err == nil {
+				t.Errorf("expected error for invalid params")
+			}
+		})
+	}
+
+	if _, err := peaks.New(valid); err != nil {
+		t.Errorf("unexpected error for valid params: %v", err)
+	}
+}
+
+func TestDetectShapes(t *testing.T) {
+	t.Parallel()
+
+	// A strong peak at 3 and a spurious one at 7 riding on its flank.
+	input := []float64{0, 2, 4, 6, 4, 2, 1.5, 1.8, 1, 0}
+	detector, err := peaks.New(peaks.Params{
+		Range:       float64(len(input) - 1),
+		MaxPeaks:    10,
+		MaxPosition: float64(len(input) - 1),
+		Threshold:   math.Inf(-1),
+		OrderBy:     peaks.OrderByPosition,
+	})
+	if err != nil {
+		t.Fatalf("error creating peak detector: %v", err)
+	}
+
+	detected, err := detector.DetectShapes(input)
+	if err != nil {
+		t.Fatalf("error detecting peaks: %v", err)
+	}
+	want := []peaks.Peak{
+		// Half prominence 3 is crossed at 1.5 and 4.5.
+		{Position: 3, Amplitude: 6, Prominence: 6, Width: 3},
+		// Half prominence 1.65 is crossed at 6.5 and 7.1875.
+		{Position: 7, Amplitude: 1.8, Prominence: 0.3, Width: 0.6875},
+	}
+	if len(detected) != len(want) {
+		t.Fatalf("incorrect number of peaks, got %d, want %d", len(detected), len(want))
+	}
+	for i := range want {
+		got := detected[i]
+		if got.Position != want[i].Position || got.Amplitude != want[i].Amplitude ||
+			math.Abs(got.Prominence-want[i].Prominence) > 1e-9 || math.Abs(got.Width-want[i].Width) > 1e-9 {
+			t.Errorf("incorrect peak %d, got %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker, err := peaks.NewTracker(peaks.TrackerParams{MaxJump: 5, MaxGap: 1, MinLength: 3})
+	if err != nil {
+		t.Fatalf("error creating tracker: %v", err)
+	}
+
+	// A partial gliding up from 100, a steady one at 300 missing in frame 2 and a spurious peak in frame 1.
+	frames := [][]float64{
+		{100, 300},
+		{103, 200, 301},
+		{106},
+		{109, 300},
+		{112, 299},
+	}
+	var ended []peaks.Trajectory
+	for i, positions := range frames {
+		finished, err := tracker.Push(positions, positions)
+		if err != nil {
+			t.Fatalf("error pushing frame %d: %v", i, err)
+		}
+		ended = append(ended, finished...)
+	}
+	if len(ended) != 0 {
+		t.Errorf("incorrect ended trajectories, got %+v, want none", ended)
+	}
+	if active := tracker.Active(); len(active) != 2 {
+		t.Errorf("incorrect number of active trajectories, got %d, want 2", len(active))
+	}
+
+	trajectories := tracker.Flush()
+	slices.SortFunc(trajectories, func(a, b peaks.Trajectory) int { return a.ID - b.ID })
+	wantFrames := [][]int{{0, 1, 2, 3, 4}, {0, 1, 3, 4}}
+	wantPositions := [][]float64{{100, 103, 106, 109, 112}, {300, 301, 300, 299}}
+	if len(trajectories) != len(wantFrames) {
+		t.Fatalf("incorrect number of trajectories, got %+v, want %d", trajectories, len(wantFrames))
+	}
+	for i, trajectory := range trajectories {
+		var frames []int
+		var positions []float64
+		for _, point := range trajectory.Points {
+			frames, positions = append(frames, point.Frame), append(positions, point.Position)
+		}
+		if !slices.Equal(frames, wantFrames[i]) || !slices.Equal(positions, wantPositions[i]) {
+			t.Errorf(
+				"incorrect trajectory %d, got frames %v at %v, want frames %v at %v",
+				i, frames, positions, wantFrames[i], wantPositions[i],
+			)
+		}
+	}
+
+	if finished, _ := tracker.Push([]float64{50}, []float64{1}); len(finished) != 0 {
+		t.Errorf("incorrect ended trajectories after flush, got %+v", finished)
+	}
+	if finished, _ := tracker.Push(nil, nil); len(finished) != 0 {
+		t.Errorf("trajectory shorter than MinLength was reported: %+v", finished)
+	}
+}
+
+func TestNewTracker_Validation(t *testing.T) {
+	t.Parallel()
+
+	for _, params := range []peaks.TrackerParams{
+		{},
+		{MaxJump: math.Inf(1)},
+		{MaxJump: 1, MaxGap: -1},
+		{MaxJump: 1, MinLength: -1},
+	} {
+		if _, err := peaks.NewTracker(params); err == nil {
+			t.Errorf("expected error for params %+v", params)
+		}
+	}
+}
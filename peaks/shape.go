@@ -0,0 +1,78 @@
+package peaks
+
+import (
+	"math"
+)
+
+// Peak is a detected peak together with its shape, see DetectShapes.
+type Peak struct {
+	Position   float64 // Position in units of Range.
+	Amplitude  float64 // Amplitude, interpolated if ShouldInterpolate is set.
+	Prominence float64 // Height above the higher of the lowest values between the peak and a higher value on each side.
+	Width      float64 // Width at half prominence in units of Range, linearly interpolated between values.
+}
+
+// DetectShapes is Detect additionally reporting the prominence and width of each peak, like scipy's find_peaks, so
+// spurious peaks in noisy input can be filtered. Shapes are measured around the input value nearest the peak
+// position; for valleys, prominences and widths are those of the negated input.
+func (pd *Detector) DetectShapes(input []float64) ([]Peak, error) {
+	positions, amplitudes, err := pd.Detect(input)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := 1.0
+	if pd.params.Valleys {
+		sign = -1
+	}
+	value := func(i int) float64 { return sign * input[i] }
+
+	scale := pd.params.Range / float64(len(input)-1)
+	detected := make([]Peak, len(positions))
+	for k, position := range positions {
+		index := min(len(input)-1, max(0, int(math.Round(position/scale))))
+		height := value(index)
+
+		// The bases are the lowest values on each side before the input rises above the peak or ends.
+		leftBase := index
+		for i := index - 1; i >= 0 && value(i) <= height; i-- {
+			if value(i) < value(leftBase) {
+				leftBase = i
+			}
+		}
+		rightBase := index
+		for i := index + 1; i < len(input) && value(i) <= height; i++ {
+			if value(i) < value(rightBase) {
+				rightBase = i
+			}
+		}
+		prominence := height - max(value(leftBase), value(rightBase))
+
+		// Crossings of half the prominence within the bases.
+		reference := height - prominence/2
+		leftCrossing := float64(index)
+		for i := index; i > leftBase; i-- {
+			if value(i-1) < reference {
+				leftCrossing = float64(i) - (value(i)-reference)/(value(i)-value(i-1))
+				break
+			}
+			leftCrossing = float64(i - 1)
+		}
+		rightCrossing := float64(index)
+		for i := index; i < rightBase; i++ {
+			if value(i+1) < reference {
+				rightCrossing = float64(i) + (value(i)-reference)/(value(i)-value(i+1))
+				break
+			}
+			rightCrossing = float64(i + 1)
+		}
+
+		detected[k] = Peak{
+			Position:   position,
+			Amplitude:  amplitudes[k],
+			Prominence: prominence,
+			Width:      (rightCrossing - leftCrossing) * scale,
+		}
+	}
+	return detected, nil
+}
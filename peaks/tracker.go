@@ -0,0 +1,159 @@
+package peaks
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+type (
+	// TrackerParams configure a Tracker.
+	TrackerParams struct {
+		MaxJump   float64 // Maximum position change between successive peaks of a trajectory, in units of Range.
+		MaxGap    int     // Number of frames a trajectory may miss peaks before it ends, zero ends it at the first miss.
+		MinLength int     // Minimum number of peaks of reported trajectories, shorter ones are discarded as spurious.
+	}
+	// Point is one peak of a trajectory.
+	Point struct {
+		Frame     int     // Index of the frame the peak was detected in, counted from the first Push.
+		Position  float64 // Position of the peak.
+		Amplitude float64 // Amplitude of the peak.
+	}
+	// Trajectory is a sequence of peaks of successive frames following the same partial.
+	Trajectory struct {
+		ID     int     // Identifier unique within a Tracker, in order of birth.
+		Points []Point // Peaks in frame order, frames of a gap have none.
+	}
+	// Tracker links the peaks of successive frames into trajectories, e.g. the harmonic partials of a tone. A peak
+	// continues the trajectory whose last peak is nearest within MaxJump, matching the closest pairs first; peaks
+	// continuing no trajectory start new ones, and trajectories without a peak for more than MaxGap frames end.
+	Tracker struct {
+		params   TrackerParams
+		frame    int
+		nextID   int
+		active   []*activeTrajectory
+		pairs    []trackerPair
+		matched  []bool
+		finished []Trajectory
+	}
+	activeTrajectory struct {
+		trajectory Trajectory
+		missed     int  // Number of frames since the last peak.
+		continued  bool // Whether a peak of the current frame continued the trajectory.
+	}
+	trackerPair struct {
+		trajectory, peak int
+		distance         float64
+	}
+)
+
+// NewTracker creates a Tracker with the given params.
+func NewTracker(params TrackerParams) (*Tracker, error) {
+	if !(params.MaxJump > 0) || math.IsInf(params.MaxJump, 1) {
+		return nil, fmt.Errorf("invalid MaxJump value: %g, must be positive", params.MaxJump)
+	}
+	if params.MaxGap < 0 {
+		return nil, fmt.Errorf("invalid MaxGap value: %d, must not be negative", params.MaxGap)
+	}
+	if params.MinLength < 0 {
+		return nil, fmt.Errorf("invalid MinLength value: %d, must not be negative", params.MinLength)
+	}
+	return &Tracker{params: params}, nil
+}
+
+// Push links the peaks of the next frame, as returned by Detect, to the trajectories and returns the trajectories
+// that ended with this frame. The returned slice is valid until the next call.
+func (t *Tracker) Push(positions, amplitudes []float64) ([]Trajectory, error) {
+	if len(positions) != len(amplitudes) {
+		return nil, fmt.Errorf("got %d positions but %d amplitudes", len(positions), len(amplitudes))
+	}
+
+	// Candidate continuations, closest first.
+	t.pairs = t.pairs[:0]
+	for i, active := range t.active {
+		last := active.trajectory.Points[len(active.trajectory.Points)-1].Position
+		for j, position := range positions {
+			if distance := math.Abs(position - last); distance <= t.params.MaxJump {
+				t.pairs = append(t.pairs, trackerPair{trajectory: i, peak: j, distance: distance})
+			}
+		}
+		active.continued = false
+	}
+	slices.SortFunc(t.pairs, func(a, b trackerPair) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+
+	t.matched = slices.Grow(t.matched[:0], len(positions))[:len(positions)]
+	clear(t.matched)
+	for _, pair := range t.pairs {
+		active := t.active[pair.trajectory]
+		if active.continued || t.matched[pair.peak] {
+			continue
+		}
+		active.trajectory.Points = append(active.trajectory.Points, Point{
+			Frame: t.frame, Position: positions[pair.peak], Amplitude: amplitudes[pair.peak],
+		})
+		active.continued, active.missed = true, 0
+		t.matched[pair.peak] = true
+	}
+
+	// Trajectories without a continuation age and end after the gap, unmatched peaks are born.
+	t.finished = t.finished[:0]
+	t.active = slices.DeleteFunc(t.active, func(active *activeTrajectory) bool {
+		if active.continued {
+			return false
+		}
+		active.missed++
+		if active.missed <= t.params.MaxGap {
+			return false
+		}
+		t.finish(active)
+		return true
+	})
+	for j, position := range positions {
+		if t.matched[j] {
+			continue
+		}
+		t.active = append(t.active, &activeTrajectory{
+			trajectory: Trajectory{
+				ID:     t.nextID,
+				Points: []Point{{Frame: t.frame, Position: position, Amplitude: amplitudes[j]}},
+			},
+			continued: true,
+		})
+		t.nextID++
+	}
+
+	t.frame++
+	return t.finished, nil
+}
+
+// Active returns the trajectories that haven't ended yet, regardless of MinLength. The trajectories share their
+// points with the tracker, so they must not be modified and are valid until the next call to Push.
+func (t *Tracker) Active() []Trajectory {
+	trajectories := make([]Trajectory, len(t.active))
+	for i, active := range t.active {
+		trajectories[i] = active.trajectory
+	}
+	return trajectories
+}
+
+// Flush ends all trajectories, e.g. at the end of a stream, and returns those of at least MinLength peaks. The tracker
+// can be reused for a new stream afterwards.
+func (t *Tracker) Flush() []Trajectory {
+	t.finished = t.finished[:0]
+	for _, active := range t.active {
+		t.finish(active)
+	}
+	clear(t.active)
+	t.active, t.frame = t.active[:0], 0
+	return slices.Clone(t.finished)
+}
+
+// finish reports the trajectory as finished if it's long enough.
+func (t *Tracker) finish(active *activeTrajectory) {
+	if len(active.trajectory.Points) >= t.params.MinLength {
+		t.finished = append(t.finished, active.trajectory)
+	}
+}
@@ -0,0 +1,129 @@
+// Package piano computes piano tuning targets from measured inharmonic partials, the way aural tuners match beat
+// rates of coincident partials instead of relying on pure equal temperament.
+package piano
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+	"github.com/FreibergVlad/go-yinfft/peaks"
+)
+
+// partialSearchWidth is the half-width of the partial search window relative to the fundamental frequency.
+const partialSearchWidth = 0.25
+
+type (
+	// Model describes a stiff string, whose n-th partial lies at n * F * sqrt(1 + B * n^2).
+	Model struct {
+		F float64 // Frequency of an ideal, perfectly flexible string in Hz.
+		B float64 // Inharmonicity coefficient.
+	}
+	// Interval describes how a note is tuned against an already tuned reference note: the given partials of both
+	// notes must beat at BeatRate Hz. A positive BeatRate means the target partial is above the reference partial.
+	Interval struct {
+		ReferencePartial int     // Partial of the reference note.
+		TargetPartial    int     // Partial of the note being tuned.
+		BeatRate         float64 // Desired beat rate in Hz, zero for a beatless interval.
+	}
+)
+
+var (
+	// Octave2to1 tunes a note an octave above the reference so its fundamental matches the reference 2nd partial.
+	Octave2to1 = Interval{ReferencePartial: 2, TargetPartial: 1}
+	// Octave4to2 tunes a note an octave above the reference matching the 4th and 2nd partials, a wider octave.
+	Octave4to2 = Interval{ReferencePartial: 4, TargetPartial: 2}
+	// Octave6to3 tunes a note an octave above the reference matching the 6th and 3rd partials, used in the bass.
+	Octave6to3 = Interval{ReferencePartial: 6, TargetPartial: 3}
+)
+
+// Partial returns the frequency of the n-th partial of the string.
+func (m Model) Partial(n int) float64 {
+	return float64(n) * m.F * math.Sqrt(1+m.B*float64(n*n))
+}
+
+// FitModel estimates the string model from measured partial frequencies, where partials[i] is the frequency of the
+// (i+1)-th partial and zero marks a partial that wasn't found. At least two partials are required.
+func FitModel(partials []float64) (Model, error) {
+	// (f_n / n)^2 = F^2 + F^2 * B * n^2, a linear function of n^2.
+	var n, sumX, sumY, sumXX, sumXY float64
+	for i, frequency := range partials {
+		if frequency <= 0 {
+			continue
+		}
+		x := float64((i + 1) * (i + 1))
+		y := math.Pow(frequency/float64(i+1), 2)
+		n++
+		sumX += x
+		sumY += y
+		sumXX += x * x
+		sumXY += x * y
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator == 0 {
+		return Model{}, errors.New("at least two partials are required to fit a string model")
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	if intercept <= 0 {
+		return Model{}, errors.New("partials are inconsistent with a stiff string model")
+	}
+
+	return Model{F: math.Sqrt(intercept), B: max(0, slope/intercept)}, nil
+}
+
+// MeasurePartials finds the first n partials of a note with the given fundamental in a magnitude spectrum of
+// len(frame)/2+1 bins. The search for each partial is centered on the position predicted from the partials found so
+// far, so the growing inharmonic offset of upper partials is followed. Partials that cannot be found are zero.
+func MeasurePartials(spectrum []float64, binFrequency, f0 float64, n int) ([]float64, error) {
+	partials := make([]float64, n)
+	model := Model{F: f0}
+
+	for i := range partials {
+		expected := model.Partial(i + 1)
+		minPosition := (expected - partialSearchWidth*f0) / binFrequency
+		maxPosition := (expected + partialSearchWidth*f0) / binFrequency
+		if maxPosition >= float64(len(spectrum)-1) {
+			break
+		}
+
+		peakDetector, err := peaks.New(peaks.Params{
+			Range:             float64(len(spectrum) - 1),
+			MaxPeaks:          1,
+			MinPosition:       max(0, minPosition),
+			MaxPosition:       maxPosition,
+			Threshold:         math.Inf(-1),
+			OrderBy:           peaks.OrderByAmplitude,
+			ShouldInterpolate: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize peak detection algorithm: %w", err)
+		}
+
+		positions, _, err := peakDetector.Detect(spectrum)
+		if err != nil {
+			return nil, fmt.Errorf("peak detection error: %w", err)
+		}
+		if len(positions) == 0 || positions[0] < minPosition {
+			continue
+		}
+		partials[i] = positions[0] * binFrequency
+
+		if fitted, err := FitModel(partials[:i+1]); err == nil {
+			model = fitted
+		}
+	}
+
+	return partials, nil
+}
+
+// MeasureFrame applies the analysis window to a copy of the frame and measures the first n partials of a note with
+// the given fundamental, see MeasurePartials.
+func MeasureFrame(frame []float64, sampleRate, f0 float64, n int) ([]float64, error) {
+	window, _ := internal.Window(internal.HannWindow, len(frame)) // The Hann window is always registered.
+	spectrum := internal.PrepareSpectrum(append([]float64(nil), frame...), window, len(frame), 0)
+	return MeasurePartials(spectrum, sampleRate/float64(len(frame)), f0, n)
+}
@@ -0,0 +1,57 @@
+package piano_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft/piano"
+)
+
+func TestTuner_Target(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := 44100.0, 16384
+	reference := piano.Model{F: 220, B: 0.0004}
+	target := piano.Model{F: 438, B: 0.0006}
+
+	tuner, err := piano.NewTuner(sampleRate, 8)
+	if err != nil {
+		t.Fatalf("error creating tuner: %v", err)
+	}
+
+	for midi, model := range map[int]piano.Model{57: reference, 69: target} {
+		frame := stringTone(model, 8, sampleRate, frameSize)
+		measured, err := tuner.Measure(midi, frame, model.Partial(1))
+		if err != nil {
+			t.Fatalf("error measuring note %d: %v", midi, err)
+		}
+		if math.Abs(measured.B-model.B)/model.B > 0.1 {
+			t.Errorf("incorrect inharmonicity for note %d, got %.6f, want %.6f", midi, measured.B, model.B)
+		}
+	}
+
+	got, err := tuner.Target(69, 57, piano.Octave4to2)
+	if err != nil {
+		t.Fatalf("error computing target: %v", err)
+	}
+
+	// The 2nd partial of the retuned note must match the 4th partial of the reference.
+	retuned := piano.Model{F: got / math.Sqrt(1+target.B), B: target.B}
+	if cents := 1200 * math.Log2(retuned.Partial(2)/reference.Partial(4)); math.Abs(cents) > 1 {
+		t.Errorf("retuned partials differ by %.2f cents, want beatless octave", cents)
+	}
+	if got <= 2*reference.Partial(1) {
+		t.Errorf("stretched octave target %.2f Hz is not wider than a pure octave %.2f Hz", got, 2*reference.Partial(1))
+	}
+}
+
+func stringTone(model piano.Model, partials int, sampleRate float64, length int) []float64 {
+	signal := make([]float64, length)
+	for n := 1; n <= partials; n++ {
+		frequency := model.Partial(n)
+		for i := range signal {
+			signal[i] += math.Sin(2*math.Pi*frequency*float64(i)/sampleRate) / float64(n)
+		}
+	}
+	return signal
+}
@@ -0,0 +1,81 @@
+package piano
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tuner keeps the string models of measured notes and computes tuning targets from them.
+type Tuner struct {
+	SampleRate float64 // Audio sampling rate in Hz.
+	Partials   int     // Number of partials measured per note.
+	models     map[int]Model
+}
+
+// NewTuner creates a Tuner measuring the given number of partials per note.
+func NewTuner(sampleRate float64, partials int) (*Tuner, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("sampleRate must be positive, got %.2f", sampleRate)
+	}
+	if partials < 2 {
+		return nil, fmt.Errorf("at least 2 partials must be measured, got %d", partials)
+	}
+	return &Tuner{SampleRate: sampleRate, Partials: partials, models: map[int]Model{}}, nil
+}
+
+// Measure measures the partials of the given MIDI note from an audio frame, where f0 is the detected fundamental,
+// and stores the fitted string model for later target computations.
+func (t *Tuner) Measure(midi int, frame []float64, f0 float64) (Model, error) {
+	partials, err := MeasureFrame(frame, t.SampleRate, f0, t.Partials)
+	if err != nil {
+		return Model{}, err
+	}
+	model, err := FitModel(partials)
+	if err != nil {
+		return Model{}, fmt.Errorf("failed to measure note %d: %w", midi, err)
+	}
+	t.SetModel(midi, model)
+	return model, nil
+}
+
+// SetModel stores a string model for the given MIDI note, e.g. one measured in a previous session.
+func (t *Tuner) SetModel(midi int, model Model) {
+	t.models[midi] = model
+}
+
+// Model returns the stored string model for the given MIDI note.
+func (t *Tuner) Model(midi int) (Model, bool) {
+	model, ok := t.models[midi]
+	return model, ok
+}
+
+// Target returns the frequency the fundamental of the given MIDI note must be tuned to, so that its partials beat
+// against those of the reference note as described by the interval. Both notes must have been measured, since the
+// inharmonicity of the target string determines where its partials lie once it is retuned.
+func (t *Tuner) Target(midi, referenceMIDI int, interval Interval) (float64, error) {
+	reference, ok := t.models[referenceMIDI]
+	if !ok {
+		return 0, fmt.Errorf("reference note %d has not been measured", referenceMIDI)
+	}
+	target, ok := t.models[midi]
+	if !ok {
+		return 0, fmt.Errorf("note %d has not been measured", midi)
+	}
+	if interval.ReferencePartial < 1 || interval.TargetPartial < 1 {
+		return 0, fmt.Errorf("interval partials must be positive, got %+v", interval)
+	}
+
+	targetPartial := reference.Partial(interval.ReferencePartial) + interval.BeatRate
+	retuned := Model{B: target.B}
+	retuned.F = targetPartial / Model{F: 1, B: target.B}.Partial(interval.TargetPartial)
+	return retuned.Partial(1), nil
+}
+
+// Deviation returns how far the given fundamental frequency of a note is from its target, in cents.
+func (t *Tuner) Deviation(midi int, frequency float64, referenceMIDI int, interval Interval) (float64, error) {
+	target, err := t.Target(midi, referenceMIDI, interval)
+	if err != nil {
+		return 0, err
+	}
+	return 1200 * math.Log2(frequency/target), nil
+}
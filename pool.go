@@ -0,0 +1,80 @@
+package yinfft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type (
+	// DetectorPool hands out ready detectors for services analyzing many simultaneous streams with a few distinct
+	// configurations. Detectors of equal params are cloned from one prototype, so they share its precomputed weights
+	// and window, and are recycled through a sync.Pool together with their scratch buffers. The zero value is ready to
+	// use and a DetectorPool is safe for concurrent use.
+	DetectorPool struct {
+		entries sync.Map // detectorPoolKey to *detectorPoolEntry.
+	}
+	detectorPoolKey struct {
+		params string // JSON encoding of the params.
+		logger logger
+	}
+	detectorPoolEntry struct {
+		detectors sync.Pool // Clones of the entry's prototype.
+	}
+)
+
+// Get returns a detector for the params, recycled if one was put back before. Params with WeightFunc or WindowFunc
+// can't be pooled, as functions can't be compared.
+func (p *DetectorPool) Get(params Params) (*PitchDetector, error) {
+	key, err := newDetectorPoolKey(params)
+	if err != nil {
+		return nil, err
+	}
+	if entry, ok := p.entries.Load(key); ok {
+		return entry.(*detectorPoolEntry).get(), nil
+	}
+
+	prototype, err := New(params)
+	if err != nil {
+		return nil, err
+	}
+	entry, _ := p.entries.LoadOrStore(key, newDetectorPoolEntry(prototype))
+	return entry.(*detectorPoolEntry).get(), nil
+}
+
+// Put returns a detector obtained from Get to the pool. Detectors reconfigured with SetFrequencyRange are recycled
+// for their current params.
+func (p *DetectorPool) Put(detector *PitchDetector) {
+	key, err := newDetectorPoolKey(detector.params)
+	if err != nil {
+		return
+	}
+	entry, _ := p.entries.LoadOrStore(key, newDetectorPoolEntry(detector.Clone()))
+	entry.(*detectorPoolEntry).detectors.Put(detector)
+}
+
+func newDetectorPoolKey(params Params) (detectorPoolKey, error) {
+	if params.WeightFunc != nil || params.WindowFunc != nil {
+		return detectorPoolKey{}, errors.New("params with 'WeightFunc' or 'WindowFunc' can't be pooled")
+	}
+	if params.Logger != nil && !reflect.TypeOf(params.Logger).Comparable() {
+		return detectorPoolKey{}, fmt.Errorf("params with a logger of type %T can't be pooled", params.Logger)
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return detectorPoolKey{}, fmt.Errorf("failed to encode params: %w", err)
+	}
+	return detectorPoolKey{params: string(encoded), logger: params.Logger}, nil
+}
+
+func newDetectorPoolEntry(prototype *PitchDetector) *detectorPoolEntry {
+	entry := &detectorPoolEntry{}
+	entry.detectors.New = func() any { return prototype.Clone() }
+	return entry
+}
+
+func (e *detectorPoolEntry) get() *PitchDetector {
+	return e.detectors.Get().(*PitchDetector)
+}
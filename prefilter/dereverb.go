@@ -0,0 +1,59 @@
+package prefilter
+
+import (
+	"fmt"
+	"math"
+)
+
+type (
+	// DereverbParams configures a Dereverberator.
+	DereverbParams struct {
+		Decay    float64 // Per-frame magnitude decay of the late reverberation in (0, 1), see DecayForRT60.
+		Strength float64 // Fraction of the estimated reverberation subtracted, in (0, 1].
+		Floor    float64 // Minimum per-bin gain in [0, 1), limiting artifacts of over-subtraction.
+	}
+	// Dereverberator suppresses late reverberation by tracking a decaying per-bin envelope of previous frames and
+	// subtracting its prediction from the current frame, which reduces smeared note transitions in reverberant rooms.
+	Dereverberator struct {
+		params   DereverbParams
+		envelope []float64
+	}
+)
+
+// DecayForRT60 returns the per-frame decay of a room with the given reverberation time, the time it takes sound to
+// decay by 60 dB, for frames that are frameDuration seconds apart.
+func DecayForRT60(rt60, frameDuration float64) float64 {
+	return math.Pow(10, -3*frameDuration/rt60)
+}
+
+// NewDereverberator creates a Dereverberator with the given params.
+func NewDereverberator(params DereverbParams) (*Dereverberator, error) {
+	if params.Decay <= 0 || params.Decay >= 1 {
+		return nil, fmt.Errorf("decay must be in range (0, 1), got %.2f", params.Decay)
+	}
+	if params.Strength <= 0 || params.Strength > 1 {
+		return nil, fmt.Errorf("strength must be in range (0, 1], got %.2f", params.Strength)
+	}
+	if params.Floor < 0 || params.Floor >= 1 {
+		return nil, fmt.Errorf("floor must be in range [0, 1), got %.2f", params.Floor)
+	}
+	return &Dereverberator{params: params}, nil
+}
+
+// ProcessSpectrum subtracts the predicted late reverberation from the magnitude spectrum in place.
+func (d *Dereverberator) ProcessSpectrum(spectrum []float64) {
+	if len(d.envelope) != len(spectrum) {
+		d.envelope = make([]float64, len(spectrum))
+	}
+
+	for k, magnitude := range spectrum {
+		late := d.params.Decay * d.envelope[k]
+		d.envelope[k] = max(magnitude, late)
+		spectrum[k] = max(magnitude-d.params.Strength*late, d.params.Floor*magnitude)
+	}
+}
+
+// Reset forgets the reverberation envelope.
+func (d *Dereverberator) Reset() {
+	d.envelope = nil
+}
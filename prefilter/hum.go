@@ -0,0 +1,145 @@
+package prefilter
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	humFrequencyStep = 0.01  // Resolution of the mains frequency search in Hz.
+	humRetuneDelta   = 0.005 // Minimum change of the estimate in Hz that retunes the notch filters.
+)
+
+type (
+	// HumParams configures a HumRemover.
+	HumParams struct {
+		SampleRate     float64 // Audio sampling rate in Hz.
+		MainsFrequency float64 // Nominal mains frequency in Hz, usually 50 or 60.
+		MaxDeviation   float64 // Maximum drift of the actual mains frequency from the nominal one in Hz.
+		Harmonics      int     // Number of removed components, including the fundamental.
+		Q              float64 // Quality factor of the notch filters; higher values give narrower notches.
+	}
+	// HumRemover estimates the actual mains frequency of a stream and removes it along with its harmonics using
+	// notch filters that follow the estimate, so drifting hum doesn't leave residue next to static notches.
+	HumRemover struct {
+		params    HumParams
+		frequency float64
+		analysis  []float64 // Samples collected for the next frequency estimate.
+		notches   []biquad
+	}
+	// biquad is a second-order IIR section in transposed direct form II.
+	biquad struct {
+		b0, b1, b2, a1, a2 float64
+		z1, z2             float64
+	}
+)
+
+// DefaultHumParams removes 50 Hz hum drifting by up to 0.2 Hz and its first 7 harmonics.
+var DefaultHumParams = HumParams{
+	SampleRate:     44100,
+	MainsFrequency: 50,
+	MaxDeviation:   0.2,
+	Harmonics:      8,
+	Q:              30,
+}
+
+// NewHumRemover creates a HumRemover starting at the nominal mains frequency.
+func NewHumRemover(params HumParams) (*HumRemover, error) {
+	if params.SampleRate <= 0 || params.MainsFrequency <= 0 || params.MaxDeviation < 0 || params.Q <= 0 {
+		return nil, fmt.Errorf("invalid hum params: %+v", params)
+	}
+	if params.Harmonics < 1 || float64(params.Harmonics)*(params.MainsFrequency+params.MaxDeviation) >= params.SampleRate/2 {
+		return nil, fmt.Errorf("invalid number of harmonics %d for sample rate %.2f Hz", params.Harmonics, params.SampleRate)
+	}
+
+	remover := &HumRemover{params: params, notches: make([]biquad, params.Harmonics)}
+	remover.Reset()
+	return remover, nil
+}
+
+// Frequency returns the current estimate of the mains frequency in Hz.
+func (h *HumRemover) Frequency() float64 {
+	return h.frequency
+}
+
+// ProcessSamples removes hum from the samples in place. The mains frequency is re-estimated from every second of
+// audio.
+func (h *HumRemover) ProcessSamples(samples []float64) {
+	analysisLength := int(h.params.SampleRate)
+	for i, sample := range samples {
+		h.analysis = append(h.analysis, sample)
+		if len(h.analysis) == analysisLength {
+			h.retune(h.estimate())
+			h.analysis = h.analysis[:0]
+		}
+
+		for j := range h.notches {
+			sample = h.notches[j].process(sample)
+		}
+		samples[i] = sample
+	}
+}
+
+// Reset restarts tracking from the nominal mains frequency and clears the filter state.
+func (h *HumRemover) Reset() {
+	h.analysis = make([]float64, 0, int(h.params.SampleRate))
+	h.frequency = 0
+	h.retune(h.params.MainsFrequency)
+	for i := range h.notches {
+		h.notches[i].z1, h.notches[i].z2 = 0, 0
+	}
+}
+
+// estimate returns the frequency within the allowed deviation maximizing the combined Goertzel power of the
+// fundamental and its harmonics over the analysis buffer.
+func (h *HumRemover) estimate() float64 {
+	best, bestPower := h.frequency, -1.0
+	steps := int(math.Round(h.params.MaxDeviation / humFrequencyStep))
+	for step := -steps; step <= steps; step++ {
+		frequency := h.params.MainsFrequency + float64(step)*humFrequencyStep
+		power := 0.0
+		for harmonic := 1; harmonic <= h.params.Harmonics; harmonic++ {
+			power += goertzelPower(h.analysis, float64(harmonic)*frequency, h.params.SampleRate)
+		}
+		if power > bestPower {
+			best, bestPower = frequency, power
+		}
+	}
+	return best
+}
+
+func (h *HumRemover) retune(frequency float64) {
+	if math.Abs(frequency-h.frequency) < humRetuneDelta {
+		return
+	}
+	h.frequency = frequency
+	for i := range h.notches {
+		h.notches[i].setNotch(float64(i+1)*frequency, h.params.SampleRate, h.params.Q)
+	}
+}
+
+// goertzelPower returns the power of the given frequency in the samples.
+func goertzelPower(samples []float64, frequency, sampleRate float64) float64 {
+	coefficient := 2 * math.Cos(2*math.Pi*frequency/sampleRate)
+	var s1, s2 float64
+	for _, sample := range samples {
+		s1, s2 = sample+coefficient*s1-s2, s1
+	}
+	return s1*s1 + s2*s2 - coefficient*s1*s2
+}
+
+// setNotch updates the coefficients to a notch at the given frequency, keeping the filter state.
+func (b *biquad) setNotch(frequency, sampleRate, q float64) {
+	omega := 2 * math.Pi * frequency / sampleRate
+	alpha := math.Sin(omega) / (2 * q)
+	a0 := 1 + alpha
+	b.b0, b.b1, b.b2 = 1/a0, -2*math.Cos(omega)/a0, 1/a0
+	b.a1, b.a2 = -2*math.Cos(omega)/a0, (1-alpha)/a0
+}
+
+func (b *biquad) process(x float64) float64 {
+	y := b.b0*x + b.z1
+	b.z1 = b.b1*x - b.a1*y + b.z2
+	b.z2 = b.b2*x - b.a2*y
+	return y
+}
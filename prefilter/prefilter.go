@@ -0,0 +1,88 @@
+// Package prefilter provides stateful preprocessing stages applied to an audio stream before pitch detection, such as
+// adaptive hum removal and reverberation suppression.
+package prefilter
+
+import (
+	"fmt"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+type (
+	// Filter is a preprocessing stage. Every filter is a SampleFilter, a SpectrumFilter or both.
+	Filter interface {
+		// Reset clears the state of the filter, e.g. before processing a new stream.
+		Reset()
+	}
+	// SampleFilter processes consecutive blocks of a single audio stream in place.
+	SampleFilter interface {
+		Filter
+		ProcessSamples(samples []float64)
+	}
+	// SpectrumFilter processes magnitude spectra of consecutive frames of a single audio stream in place.
+	SpectrumFilter interface {
+		Filter
+		ProcessSpectrum(spectrum []float64)
+	}
+	// Detector applies filters to consecutive, non-overlapping frames of a single stream before detection: sample
+	// filters to the frame, then spectrum filters to its magnitude spectrum. Filters keep state between frames, so a
+	// Detector must not be shared across streams.
+	Detector struct {
+		detector        *yinfft.PitchDetector
+		filters         []Filter
+		sampleFilters   []SampleFilter
+		spectrumFilters []SpectrumFilter
+	}
+)
+
+var _ yinfft.Detector = (*Detector)(nil)
+
+// NewDetector creates a Detector applying the given filters in order.
+func NewDetector(detector *yinfft.PitchDetector, filters ...Filter) (*Detector, error) {
+	d := &Detector{detector: detector, filters: filters}
+	for i, filter := range filters {
+		sampleFilter, isSampleFilter := filter.(SampleFilter)
+		spectrumFilter, isSpectrumFilter := filter.(SpectrumFilter)
+		if !isSampleFilter && !isSpectrumFilter {
+			return nil, fmt.Errorf("filter %d of type %T is neither a sample nor a spectrum filter", i, filter)
+		}
+		if isSampleFilter {
+			d.sampleFilters = append(d.sampleFilters, sampleFilter)
+		}
+		if isSpectrumFilter {
+			d.spectrumFilters = append(d.spectrumFilters, spectrumFilter)
+		}
+	}
+	return d, nil
+}
+
+// DetectFromFrame filters the frame in place and detects its fundamental frequency.
+func (d *Detector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	for _, filter := range d.sampleFilters {
+		filter.ProcessSamples(frame)
+	}
+	if len(d.spectrumFilters) == 0 {
+		return d.detector.DetectFromFrame(frame)
+	}
+
+	spectrum, err := d.detector.PrepareSpectrum(frame)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.DetectFromSpectrum(spectrum)
+}
+
+// DetectFromSpectrum applies the spectrum filters to the spectrum in place and detects its fundamental frequency.
+func (d *Detector) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	for _, filter := range d.spectrumFilters {
+		filter.ProcessSpectrum(spectrum)
+	}
+	return d.detector.DetectFromSpectrum(spectrum)
+}
+
+// Reset clears the state of all filters, e.g. before processing a new stream.
+func (d *Detector) Reset() {
+	for _, filter := range d.filters {
+		filter.Reset()
+	}
+}
@@ -0,0 +1,157 @@
+package prefilter_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft/dsp"
+	"github.com/FreibergVlad/go-yinfft/prefilter"
+)
+
+func TestHumRemover(t *testing.T) {
+	t.Parallel()
+
+	params := prefilter.DefaultHumParams
+	humFrequency := 50.13
+
+	remover, err := prefilter.NewHumRemover(params)
+	if err != nil {
+		t.Fatalf("error creating hum remover: %v", err)
+	}
+
+	signal := make([]float64, 4*int(params.SampleRate))
+	for i := range signal {
+		time := float64(i) / params.SampleRate
+		signal[i] = 0.5*math.Sin(2*math.Pi*humFrequency*time) + 0.2*math.Sin(2*math.Pi*3*humFrequency*time)
+	}
+
+	for start := 0; start < len(signal); start += 512 {
+		remover.ProcessSamples(signal[start:min(start+512, len(signal))])
+	}
+
+	if math.Abs(remover.Frequency()-humFrequency) > 0.02 {
+		t.Errorf("incorrect mains frequency estimate, got %.2f Hz, want %.2f Hz", remover.Frequency(), humFrequency)
+	}
+
+	residual := 0.0
+	lastSecond := signal[len(signal)-int(params.SampleRate):]
+	for _, sample := range lastSecond {
+		residual += sample * sample
+	}
+	if rms := math.Sqrt(residual / float64(len(lastSecond))); rms > 0.02 {
+		t.Errorf("hum residue is too high, got RMS %.4f, want at most 0.02", rms)
+	}
+}
+
+func TestDereverberator(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate, frameSize, rt60 = 44100.0, 2048, 1.0
+
+	// A3 followed by E4 after half a second, played in a room simulated with four feedback comb filters.
+	dry := make([]float64, sampleRate)
+	for i := range dry {
+		frequency := 220.0
+		if i >= len(dry)/2 {
+			frequency = 330
+		}
+		dry[i] = math.Sin(2 * math.Pi * frequency * float64(i) / sampleRate)
+	}
+	reverberant := slices.Clone(dry)
+	for _, delay := range []int{1116, 1188, 1277, 1356} {
+		gain := math.Pow(10, -3*float64(delay)/(rt60*sampleRate))
+		comb := make([]float64, len(dry))
+		for i := range comb {
+			comb[i] = dry[i]
+			if i >= delay {
+				comb[i] += gain * comb[i-delay]
+			}
+		}
+		for i := range reverberant {
+			reverberant[i] += comb[i] / 4
+		}
+	}
+
+	dereverberator, err := prefilter.NewDereverberator(prefilter.DereverbParams{
+		Decay:    prefilter.DecayForRT60(rt60, frameSize/sampleRate),
+		Strength: 0.9,
+		Floor:    0.05,
+	})
+	if err != nil {
+		t.Fatalf("error creating dereverberator: %v", err)
+	}
+	window, err := dsp.Window("hann", frameSize)
+	if err != nil {
+		t.Fatalf("error creating window: %v", err)
+	}
+
+	oldBin, newBin := int(math.Round(220*frameSize/sampleRate)), int(math.Round(330*frameSize/sampleRate))
+	transition := len(dry) / 2 / frameSize
+	for frame := 0; frame <= transition+6; frame++ {
+		samples := slices.Clone(reverberant[frame*frameSize : (frame+1)*frameSize])
+		spectrum := dsp.PrepareSpectrum(samples, window, frameSize, 0)
+		original := slices.Clone(spectrum)
+		dereverberator.ProcessSpectrum(spectrum)
+
+		switch {
+		case frame == transition+1:
+			// The onset of the new note has no reverberation of its own to subtract.
+			if spectrum[newBin] < 0.5*original[newBin] {
+				t.Errorf("onset of the new note suppressed from %g to %g", original[newBin], spectrum[newBin])
+			}
+			fallthrough
+		case frame > transition+1:
+			// The reverberation of the previous note is suppressed relative to the new note.
+			before := 20 * math.Log10(original[oldBin]/original[newBin])
+			after := 20 * math.Log10(spectrum[oldBin]/spectrum[newBin])
+			if before-after < 6 {
+				t.Errorf("reverberation of the previous note in frame %d suppressed by %.1f dB, want at least 6 dB", frame,
+					before-after)
+			}
+		}
+	}
+
+	// After a reset the envelope is forgotten, so nothing is subtracted from the next frame.
+	dereverberator.Reset()
+	spectrum := []float64{1, 2, 3}
+	dereverberator.ProcessSpectrum(spectrum)
+	if !slices.Equal(spectrum, []float64{1, 2, 3}) {
+		t.Errorf("incorrect spectrum after reset, got %v, want [1 2 3]", spectrum)
+	}
+}
+
+func TestNewDereverberator(t *testing.T) {
+	t.Parallel()
+
+	valid := prefilter.DereverbParams{Decay: 0.5, Strength: 1, Floor: 0}
+	tests := []struct {
+		name    string
+		modify  func(*prefilter.DereverbParams)
+		wantErr bool
+	}{
+		{"valid params", func(*prefilter.DereverbParams) {}, false},
+		{"zero decay", func(p *prefilter.DereverbParams) { p.Decay = 0 }, true},
+		{"unit decay", func(p *prefilter.DereverbParams) { p.Decay = 1 }, true},
+		{"zero strength", func(p *prefilter.DereverbParams) { p.Strength = 0 }, true},
+		{"strength above one", func(p *prefilter.DereverbParams) { p.Strength = 1.5 }, true},
+		{"negative floor", func(p *prefilter.DereverbParams) { p.Floor = -0.1 }, true},
+		{"unit floor", func(p *prefilter.DereverbParams) { p.Floor = 1 }, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := valid
+			test.modify(&params)
+			if _, err := prefilter.NewDereverberator(params); (err != nil) != test.wantErr {
+				t.Errorf("incorrect error, got %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+
+	if decay := prefilter.DecayForRT60(2, 0.1); math.Abs(decay-math.Pow(10, -0.15)) > 1e-12 {
+		t.Errorf("incorrect decay for RT60, got %g, want %g", decay, math.Pow(10, -0.15))
+	}
+}
@@ -0,0 +1,119 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// recommendedPeriods is the number of periods of the lowest frequency a recommended frame spans, for stable
+	// minima of the yin function.
+	recommendedPeriods = 4
+	// minimumPeriods is the number of periods of the lowest frequency a frame must span, as periods up to half the
+	// frame are detected.
+	minimumPeriods = 2
+)
+
+// Instrument presets with frame sizes long enough for a few periods of the lowest note and frequency ranges covering
+// the fundamentals of the instrument, for audio sampled at 44.1 kHz. Scale FrameSize with the sample rate for other
+// rates.
+var (
+	// PresetGuitar covers a guitar in standard tuning, from a dropped D2 to the 24th fret of the high E string.
+	PresetGuitar = Params{
+		FrameSize:         4096,
+		SampleRate:        44100,
+		ShouldInterpolate: true,
+		Tolerance:         1,
+		WeightingType:     WeightingCustom,
+		MinFrequency:      70,
+		MaxFrequency:      1400,
+		OctaveCorrection:  true,
+	}
+	// PresetBassGuitar covers four, five and six string basses, from the low B0.
+	PresetBassGuitar = Params{
+		FrameSize:         8192,
+		SampleRate:        44100,
+		ShouldInterpolate: true,
+		Tolerance:         1,
+		WeightingType:     WeightingCustom,
+		MinFrequency:      28,
+		MaxFrequency:      500,
+		OctaveCorrection:  true,
+	}
+	// PresetViolin covers the violin from the open G3 string to the top of the fingerboard.
+	PresetViolin = Params{
+		FrameSize:         2048,
+		SampleRate:        44100,
+		ShouldInterpolate: true,
+		Tolerance:         1,
+		WeightingType:     WeightingCustom,
+		MinFrequency:      180,
+		MaxFrequency:      3600,
+		OctaveCorrection:  true,
+	}
+	// PresetVoice covers singing and speech from bass to soprano, with A-weighting emphasizing the vocal formants.
+	PresetVoice = Params{
+		FrameSize:         2048,
+		SampleRate:        44100,
+		ShouldInterpolate: true,
+		Tolerance:         1,
+		WeightingType:     WeightingA,
+		MinFrequency:      70,
+		MaxFrequency:      1100,
+		OctaveCorrection:  true,
+	}
+	// PresetWhistle covers whistling, a nearly pure tone for which short frames and no weighting suffice.
+	PresetWhistle = Params{
+		FrameSize:         1024,
+		SampleRate:        44100,
+		ShouldInterpolate: true,
+		Tolerance:         1,
+		WeightingType:     WeightingEmpty,
+		MinFrequency:      500,
+		MaxFrequency:      5000,
+		OctaveCorrection:  true,
+	}
+)
+
+// DefaultParamsLowLatency trades accuracy for latency in real-time tuners, with a 2048 samples frame, about 46 ms at
+// 44.1 kHz, zero-padded to twice its length and detecting down to about 45 Hz.
+var DefaultParamsLowLatency = Params{
+	FrameSize:         2048,
+	SampleRate:        44100,
+	ShouldInterpolate: true,
+	Tolerance:         1,
+	WeightingType:     WeightingCustom,
+	MinFrequency:      45,
+	MaxFrequency:      5000,
+	OctaveCorrection:  true,
+	ZeroPadFactor:     2,
+}
+
+// RecommendFrameSize returns the power of two frame size for detecting frequencies down to minFrequency, preferring
+// frames spanning four periods of minFrequency. If such a frame is longer than maxLatency, the longest frame within
+// maxLatency is returned as long as it spans the two periods needed for detection at all, otherwise an error.
+func RecommendFrameSize(sampleRate, minFrequency float64, maxLatency time.Duration) (int, error) {
+	if !(sampleRate > 0) || !(minFrequency > 0) {
+		return 0, fmt.Errorf("invalid sample rate %g Hz or minimum frequency %g Hz, must be positive", sampleRate, minFrequency)
+	}
+
+	period := sampleRate / minFrequency
+	frameSize := 4
+	for float64(frameSize) < recommendedPeriods*period {
+		frameSize *= 2
+	}
+
+	maxFrameSize := maxLatency.Seconds() * sampleRate
+	for float64(frameSize) > maxFrameSize && float64(frameSize/2) >= minimumPeriods*period {
+		frameSize /= 2
+	}
+	if float64(frameSize) > maxFrameSize {
+		minLatency := time.Duration(math.Exp2(math.Ceil(math.Log2(minimumPeriods*period))) / sampleRate * float64(time.Second))
+		return 0, fmt.Errorf(
+			"no frame size within %v detects %g Hz at %g Hz, at least %v is needed", maxLatency, minFrequency, sampleRate,
+			minLatency,
+		)
+	}
+	return frameSize, nil
+}
@@ -0,0 +1,89 @@
+package yinfft
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+const (
+	// Parameters of the beta distribution of yin thresholds, with a mean of 0.1 as proposed for pYIN.
+	thresholdBetaAlpha = 2
+	thresholdBetaBeta  = 18
+	// Number of thresholds the distribution is discretized to, in steps of 1/thresholdCount.
+	thresholdCount = 100
+	// Fraction of a threshold's probability given to the global minimum when no minimum is below the threshold.
+	globalMinimumPrior = 0.01
+)
+
+// thresholdProbabilities is the discretized beta distribution of yin thresholds.
+var thresholdProbabilities = func() []float64 {
+	probabilities := make([]float64, thresholdCount)
+	sum := 0.0
+	for i := range probabilities {
+		threshold := float64(i+1) / thresholdCount
+		probabilities[i] = math.Pow(threshold, thresholdBetaAlpha-1) * math.Pow(1-threshold, thresholdBetaBeta-1)
+		sum += probabilities[i]
+	}
+	for i := range probabilities {
+		probabilities[i] /= sum
+	}
+	return probabilities
+}()
+
+// DetectProbabilistic returns the period candidates of the frame with their probability of being the fundamental
+// period, as in the front end of the pYIN algorithm: the yin threshold is treated as a random variable with a beta
+// distribution, and each threshold votes for the first minimum below it. Candidates are ordered by probability, most
+// probable first, and the remaining probability, one minus their sum, is that the frame is unvoiced. The output is
+// the usual input of HMM pitch tracking. The frame is modified in place.
+func (pd *PitchDetector) DetectProbabilistic(frame []float64) ([]Candidate, error) {
+	spectrum, err := pd.PrepareSpectrum(frame)
+	if err != nil {
+		return nil, err
+	}
+	return pd.DetectProbabilisticFromSpectrum(spectrum)
+}
+
+// DetectProbabilisticFromSpectrum returns the period candidates of the magnitude spectrum with their probabilities,
+// see DetectProbabilistic.
+func (pd *PitchDetector) DetectProbabilisticFromSpectrum(spectrum []float64) ([]Candidate, error) {
+	if err := pd.checkSpectrum(spectrum); err != nil {
+		return nil, err
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	minima, _ := pd.yinMinima(spectrum, scratch)
+	minima = slices.Clone(minima)
+	pd.scratchPool.Put(scratch)
+	if len(minima) == 0 {
+		return nil, nil
+	}
+
+	best := 0
+	for i, minimum := range minima {
+		if minimum.Yin < minima[best].Yin {
+			best = i
+		}
+	}
+
+	for i, probability := range thresholdProbabilities {
+		threshold := float64(i+1) / thresholdCount
+		voted := false
+		for j := range minima {
+			if minima[j].Yin < threshold {
+				minima[j].Probability += probability
+				voted = true
+				break
+			}
+		}
+		if !voted {
+			minima[best].Probability += globalMinimumPrior * probability
+		}
+	}
+
+	candidates := slices.DeleteFunc(minima, func(c Candidate) bool { return c.Probability == 0 })
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return cmp.Compare(b.Probability, a.Probability)
+	})
+	return candidates, nil
+}
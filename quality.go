@@ -0,0 +1,102 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+)
+
+// shrHarmonics is the maximum number of harmonics and subharmonics compared by the subharmonic-to-harmonic ratio.
+const shrHarmonics = 10
+
+// Quality holds voice quality metrics of a frame, which tell clean pitch from breathy or creaky phonation.
+type Quality struct {
+	// Subharmonic-to-harmonic ratio, the summed magnitudes halfway between the harmonics of the pitch divided by the
+	// summed magnitudes of the harmonics. Near zero for clean pitch, rising with period doubling as in creaky voice.
+	SHR float64
+	// Yin value at the detected period divided by its mean over the lags from half to one and a half periods, from
+	// zero for strictly periodic frames to about one for frames without a distinct period, e.g. breathy voice.
+	Aperiodicity float64
+}
+
+// DetectQuality detects the fundamental frequency of the frame like Detect, additionally returning its voice quality
+// metrics. Metrics are zero for unvoiced frames. The frame is modified in place.
+func (pd *PitchDetector) DetectQuality(frame []float64) (Result, Quality, error) {
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	result, err := pd.detectResult(frame, scratch)
+	if err != nil || !result.Voiced {
+		return result, Quality{}, err
+	}
+	return result, pd.quality(scratch.bins, scratch.yin, result.Frequency), nil
+}
+
+// DetectQualityFromSpectrum is DetectQuality on a magnitude spectrum, see DetectFromSpectrum.
+func (pd *PitchDetector) DetectQualityFromSpectrum(spectrum []float64) (Result, Quality, error) {
+	yinLen := pd.params.FFTSize()/2 + 1
+	if len(spectrum) != yinLen {
+		return Result{}, Quality{}, fmt.Errorf("%w: expected %d, got %d", ErrInvalidSpectrumSize, yinLen, len(spectrum))
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	frequency, confidence, err := pd.detect(spectrum, scratch)
+	if err != nil {
+		return Result{}, Quality{}, err
+	}
+	result := newResult(frequency, confidence, pd.params.SampleRate)
+	if !result.Voiced {
+		return result, Quality{}, nil
+	}
+	return result, pd.quality(spectrum, scratch.yin, frequency), nil
+}
+
+// quality computes the quality metrics of a voiced frame from its spectrum and yin function.
+func (pd *PitchDetector) quality(spectrum, yin []float64, frequency float64) Quality {
+	return Quality{
+		SHR:          pd.subharmonicRatio(spectrum, frequency),
+		Aperiodicity: aperiodicity(yin, pd.params.analysisSampleRate()/frequency),
+	}
+}
+
+// subharmonicRatio returns the subharmonic-to-harmonic ratio of the spectrum for the fundamental frequency f0, taking
+// the largest magnitude within a bin of each expected frequency to allow for interpolation errors.
+func (pd *PitchDetector) subharmonicRatio(spectrum []float64, f0 float64) float64 {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	magnitudeAt := func(frequency float64) float64 {
+		bin := int(math.Round(frequency / binFrequency))
+		magnitude := 0.0
+		for i := max(0, bin-1); i <= min(len(spectrum)-1, bin+1); i++ {
+			magnitude = max(magnitude, spectrum[i])
+		}
+		return magnitude
+	}
+
+	harmonics, subharmonics := 0.0, 0.0
+	for n := 1; n <= shrHarmonics && float64(n)*f0/binFrequency < float64(len(spectrum)-1); n++ {
+		harmonics += magnitudeAt(float64(n) * f0)
+		subharmonics += magnitudeAt((float64(n) - 0.5) * f0)
+	}
+	if harmonics == 0 {
+		return 0
+	}
+	return subharmonics / harmonics
+}
+
+// aperiodicity returns the yin value at the period tau relative to the mean of the yin function from half to one and
+// a half periods.
+func aperiodicity(yin []float64, tau float64) float64 {
+	period := int(math.Round(tau))
+	low, high := max(1, int(math.Round(tau/2))), min(len(yin)-1, int(math.Round(1.5*tau)))
+	if period < low || period > high {
+		return 0
+	}
+	mean := 0.0
+	for _, value := range yin[low : high+1] {
+		mean += value
+	}
+	mean /= float64(high - low + 1)
+	if mean == 0 {
+		return 0
+	}
+	return min(1, yin[period]/mean)
+}
@@ -0,0 +1,119 @@
+package research
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/session"
+)
+
+// MATLAB v5 data types and array classes, see the MAT-File Format specification.
+const (
+	miINT8   = 1
+	miINT32  = 5
+	miUINT32 = 6
+	miDOUBLE = 9
+	miMATRIX = 14
+
+	mxDOUBLE_CLASS = 6
+)
+
+// Variable is a named two-dimensional double matrix stored in a MATLAB .mat file.
+type Variable struct {
+	Name string
+	Rows int
+	Cols int
+	Data []float64 // Row-major values, Rows*Cols of them.
+}
+
+// WriteMAT writes the variables into a MATLAB v5 .mat file, readable with MATLAB's load and scipy.io.loadmat. The
+// header text has no creation date, so the same variables always give the same bytes.
+func WriteMAT(w io.Writer, variables ...Variable) error {
+	header := make([]byte, 128)
+	copy(header[:116], bytes.Repeat([]byte(" "), 116))
+	copy(header[:116], "MATLAB 5.0 MAT-file, Platform: go-yinfft")
+	binary.LittleEndian.PutUint16(header[124:], 0x0100)
+	copy(header[126:], "IM")
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, variable := range variables {
+		if variable.Rows*variable.Cols != len(variable.Data) {
+			return fmt.Errorf("variable %s: shape %dx%d doesn't match data length %d", variable.Name, variable.Rows,
+				variable.Cols, len(variable.Data))
+		}
+		if _, err := w.Write(encodeMatrix(variable)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteAnalysesMAT writes the intermediate buffers of the analyzed frames into a MATLAB .mat file, using the same
+// variable names and shapes as WriteAnalysesNPZ.
+func WriteAnalysesMAT(w io.Writer, analyses []*yinfft.Analysis) error {
+	variables, err := stackAnalyses(analyses)
+	if err != nil {
+		return err
+	}
+	return WriteMAT(w, variables...)
+}
+
+// WriteTrackMAT writes a pitch track into a MATLAB .mat file as the column vectors time, frequency and confidence.
+func WriteTrackMAT(w io.Writer, track []session.Point) error {
+	times := make([]float64, len(track))
+	frequencies := make([]float64, len(track))
+	confidences := make([]float64, len(track))
+	for i, point := range track {
+		times[i], frequencies[i], confidences[i] = point.Time, point.Frequency, point.Confidence
+	}
+
+	return WriteMAT(w,
+		Variable{Name: "time", Rows: len(track), Cols: 1, Data: times},
+		Variable{Name: "frequency", Rows: len(track), Cols: 1, Data: frequencies},
+		Variable{Name: "confidence", Rows: len(track), Cols: 1, Data: confidences},
+	)
+}
+
+// encodeMatrix encodes the variable as a miMATRIX data element, transposing it into MATLAB's column-major order.
+func encodeMatrix(variable Variable) []byte {
+	var body []byte
+
+	flags := make([]byte, 8)
+	flags[0] = mxDOUBLE_CLASS
+	body = appendElement(body, miUINT32, flags)
+
+	dimensions := make([]byte, 8)
+	binary.LittleEndian.PutUint32(dimensions[0:], uint32(variable.Rows))
+	binary.LittleEndian.PutUint32(dimensions[4:], uint32(variable.Cols))
+	body = appendElement(body, miINT32, dimensions)
+
+	body = appendElement(body, miINT8, []byte(variable.Name))
+
+	values := make([]byte, 0, 8*len(variable.Data))
+	for col := range variable.Cols {
+		for row := range variable.Rows {
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(variable.Data[row*variable.Cols+col]))
+		}
+	}
+	body = appendElement(body, miDOUBLE, values)
+
+	return appendElement(nil, miMATRIX, body)
+}
+
+// appendElement appends a data element tag and its data, padded to an 8-byte boundary.
+func appendElement(dst []byte, dataType uint32, data []byte) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, dataType)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(data)))
+	dst = append(dst, data...)
+	if padding := len(data) % 8; padding != 0 {
+		dst = append(dst, make([]byte, 8-padding)...)
+	}
+	return dst
+}
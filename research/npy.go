@@ -0,0 +1,143 @@
+// Package research exports analysis results and intermediate buffers in formats read by NumPy and MATLAB, so the
+// algorithm can be compared against Python and MATLAB reference implementations.
+package research
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+// WriteNPY writes float64 data as a NumPy .npy array of the given shape in C order. Without a shape, the array is
+// one-dimensional.
+func WriteNPY(w io.Writer, data []float64, shape ...int) error {
+	if len(shape) == 0 {
+		shape = []int{len(data)}
+	}
+	size := 1
+	for _, dimension := range shape {
+		size *= dimension
+	}
+	if size != len(data) {
+		return fmt.Errorf("shape %v doesn't match data length %d", shape, len(data))
+	}
+
+	dimensions := make([]string, len(shape))
+	for i, dimension := range shape {
+		dimensions[i] = strconv.Itoa(dimension)
+	}
+	shapeTuple := strings.Join(dimensions, ", ")
+	if len(shape) == 1 {
+		shapeTuple += ","
+	}
+
+	// Magic, version and header length take 10 bytes; the header is padded so the data is 64-byte aligned.
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s), }", shapeTuple)
+	header += strings.Repeat(" ", 63-(10+len(header))%64) + "\n"
+
+	buffer := make([]byte, 0, 10+len(header)+8*len(data))
+	buffer = append(buffer, "\x93NUMPY\x01\x00"...)
+	buffer = binary.LittleEndian.AppendUint16(buffer, uint16(len(header)))
+	buffer = append(buffer, header...)
+	for _, value := range data {
+		buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(value))
+	}
+
+	_, err := w.Write(buffer)
+	return err
+}
+
+// WriteNPZ writes one-dimensional arrays into a NumPy .npz archive, readable with numpy.load.
+func WriteNPZ(w io.Writer, arrays map[string][]float64) error {
+	archive := zip.NewWriter(w)
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		entry, err := archive.Create(name + ".npy")
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry %s: %w", name, err)
+		}
+		if err := WriteNPY(entry, arrays[name]); err != nil {
+			return fmt.Errorf("failed to write array %s: %w", name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// WriteAnalysesNPZ writes the intermediate buffers of the analyzed frames into a NumPy .npz archive, with one row
+// per frame in the arrays windowed_frame, spectrum, weighted_spectrum and yin, and one value per frame in the arrays
+// frequency and confidence.
+func WriteAnalysesNPZ(w io.Writer, analyses []*yinfft.Analysis) error {
+	variables, err := stackAnalyses(analyses)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for i, variable := range variables {
+		entry, err := archive.Create(variable.Name + ".npy")
+		if err != nil {
+			return fmt.Errorf("failed to create archive entry %s: %w", variable.Name, err)
+		}
+		shape := []int{variable.Rows}
+		if !analysisFields[i].scalar {
+			shape = append(shape, variable.Cols)
+		}
+		if err := WriteNPY(entry, variable.Data, shape...); err != nil {
+			return fmt.Errorf("failed to write array %s: %w", variable.Name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// stackAnalyses stacks each analysis buffer across frames into a matrix with one row per frame.
+func stackAnalyses(analyses []*yinfft.Analysis) ([]Variable, error) {
+	if len(analyses) == 0 {
+		return nil, fmt.Errorf("no analyses to write")
+	}
+
+	variables := make([]Variable, 0, len(analysisFields))
+	for _, field := range analysisFields {
+		cols := len(field.values(analyses[0]))
+		data := make([]float64, 0, len(analyses)*cols)
+		for i, analysis := range analyses {
+			values := field.values(analysis)
+			if len(values) != cols {
+				return nil, fmt.Errorf("analysis %d has %d %s values, want %d", i, len(values), field.name, cols)
+			}
+			data = append(data, values...)
+		}
+		variables = append(variables, Variable{Name: field.name, Rows: len(analyses), Cols: cols, Data: data})
+	}
+
+	return variables, nil
+}
+
+// analysisField describes how an analysis buffer is exported.
+type analysisField struct {
+	name   string
+	scalar bool
+	values func(*yinfft.Analysis) []float64
+}
+
+var analysisFields = []analysisField{
+	{"windowed_frame", false, func(a *yinfft.Analysis) []float64 { return a.WindowedFrame }},
+	{"spectrum", false, func(a *yinfft.Analysis) []float64 { return a.Spectrum }},
+	{"weighted_spectrum", false, func(a *yinfft.Analysis) []float64 { return a.WeightedSpectrum }},
+	{"yin", false, func(a *yinfft.Analysis) []float64 { return a.Yin }},
+	{"frequency", true, func(a *yinfft.Analysis) []float64 { return []float64{a.Frequency} }},
+	{"confidence", true, func(a *yinfft.Analysis) []float64 { return []float64{a.Confidence} }},
+}
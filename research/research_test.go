@@ -0,0 +1,118 @@
+package research_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/research"
+	"github.com/FreibergVlad/go-yinfft/session"
+)
+
+func TestWriteNPY(t *testing.T) {
+	t.Parallel()
+
+	data := []float64{1, 2.5, -3, 4, 5, 6}
+	var buffer bytes.Buffer
+	if err := research.WriteNPY(&buffer, data, 2, 3); err != nil {
+		t.Fatalf("error writing npy: %v", err)
+	}
+
+	raw := buffer.Bytes()
+	if !bytes.HasPrefix(raw, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("missing npy magic, got %q", raw[:8])
+	}
+	headerLen := int(binary.LittleEndian.Uint16(raw[8:10]))
+	if (10+headerLen)%64 != 0 {
+		t.Errorf("data not 64-byte aligned, header length %d", headerLen)
+	}
+	if header := string(raw[10 : 10+headerLen]); !strings.Contains(header, "'shape': (2, 3)") {
+		t.Errorf("incorrect header, got %q", header)
+	}
+	for i, want := range data {
+		offset := 10 + headerLen + 8*i
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(raw[offset:])); got != want {
+			t.Errorf("incorrect value at %d, got %v, want %v", i, got, want)
+		}
+	}
+
+	if err := research.WriteNPY(&buffer, data, 4, 2); err == nil {
+		t.Errorf("expected error for mismatched shape")
+	}
+}
+
+func TestWriteAnalysesNPZ(t *testing.T) {
+	t.Parallel()
+
+	pd, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	frameSize := pd.Params().FrameSize
+	var analyses []*yinfft.Analysis
+	for _, frequency := range []float64{220, 440} {
+		frame := make([]float64, frameSize)
+		for i := range frame {
+			frame[i] = math.Sin(2 * math.Pi * frequency * float64(i) / float64(pd.Params().SampleRate))
+		}
+		analysis, err := pd.Analyze(frame)
+		if err != nil {
+			t.Fatalf("error analyzing frame: %v", err)
+		}
+		if math.Abs(analysis.Frequency-frequency) > 1 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", analysis.Frequency, frequency)
+		}
+		analyses = append(analyses, analysis)
+	}
+
+	var buffer bytes.Buffer
+	if err := research.WriteAnalysesNPZ(&buffer, analyses); err != nil {
+		t.Fatalf("error writing npz: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("error reading npz: %v", err)
+	}
+	names := map[string]bool{}
+	for _, file := range archive.File {
+		names[file.Name] = true
+	}
+	for _, want := range []string{"windowed_frame.npy", "spectrum.npy", "weighted_spectrum.npy", "yin.npy", "frequency.npy", "confidence.npy"} {
+		if !names[want] {
+			t.Errorf("missing archive entry %s", want)
+		}
+	}
+}
+
+func TestWriteTrackMAT(t *testing.T) {
+	t.Parallel()
+
+	track := []session.Point{{Time: 0, Frequency: 440, Confidence: 0.9}, {Time: 0.1, Frequency: 441, Confidence: 0.8}}
+	var buffer bytes.Buffer
+	if err := research.WriteTrackMAT(&buffer, track); err != nil {
+		t.Fatalf("error writing mat: %v", err)
+	}
+
+	raw := buffer.Bytes()
+	if !bytes.HasPrefix(raw, []byte("MATLAB 5.0 MAT-file")) || string(raw[126:128]) != "IM" {
+		t.Fatalf("invalid mat header, got %q", raw[:128])
+	}
+	// Header, then 3 matrices of 8 (tag) + 16 (flags) + 16 (dimensions) + 16 or 24 (name) + 24 (2 doubles) bytes.
+	if want := 128 + 80 + 88 + 88; len(raw) != want {
+		t.Fatalf("incorrect file size, got %d, want %d", len(raw), want)
+	}
+	firstFrequency := math.Float64frombits(binary.LittleEndian.Uint64(raw[128+80+72:]))
+	if firstFrequency != 440 {
+		t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", firstFrequency, 440.0)
+	}
+
+	// The header text is fixed, so exports are reproducible.
+	if text, want := string(raw[:116]), fmt.Sprintf("%-116s", "MATLAB 5.0 MAT-file, Platform: go-yinfft"); text != want {
+		t.Errorf("incorrect header text, got %q, want %q", text, want)
+	}
+}
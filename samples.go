@@ -0,0 +1,41 @@
+package yinfft
+
+// pcm16Scale normalizes 16-bit PCM samples to the range [-1, 1).
+const pcm16Scale = 1.0 / 32768
+
+// DetectFromFrame32 is DetectFromFrame for float32 samples, as delivered by most audio APIs. Samples are converted
+// into a pooled float64 frame in a pass of their own before detection, so callers don't need to allocate one; the
+// windowing and transform are computed in float64. The frame isn't modified.
+func (pd *PitchDetector) DetectFromFrame32(frame []float32) (frequency float64, confidence float64, err error) {
+	return detectSamples(pd, frame, 1)
+}
+
+// DetectFromPCM16 is DetectFromFrame for 16-bit PCM samples, the native format of WAV files and most capture APIs.
+// Samples are normalized to [-1, 1) into a pooled float64 frame before detection, so callers don't need to allocate
+// one. The frame isn't modified.
+func (pd *PitchDetector) DetectFromPCM16(samples []int16) (frequency float64, confidence float64, err error) {
+	return detectSamples(pd, samples, pcm16Scale)
+}
+
+// detectSamples converts the samples to float64 into the scratch, scaled by scale, and detects the fundamental
+// frequency of the converted frame.
+func detectSamples[T ~float32 | ~int16](pd *PitchDetector, frame []T, scale float64) (float64, float64, error) {
+	if len(frame) != pd.params.FrameSize {
+		return 0, 0, &FrameSizeError{Want: pd.params.FrameSize, Got: len(frame)}
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	if len(scratch.samples) != len(frame) {
+		scratch.samples = make([]float64, len(frame))
+	}
+	for i, sample := range frame {
+		scratch.samples[i] = float64(sample) * scale
+	}
+
+	if err := pd.checkFrame(scratch.samples); err != nil {
+		return 0, 0, err
+	}
+	frequency, confidence, _, err := pd.detectFrame(scratch.samples, scratch)
+	return frequency, confidence, err
+}
@@ -0,0 +1,93 @@
+package yinfft
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+	"github.com/FreibergVlad/go-yinfft/peaks"
+)
+
+type (
+	// scratch holds the temporary buffers of a single detection. All buffers are slices of one contiguous
+	// allocation, each starting at a cache line boundary, so a detection walks a single compact memory region.
+	// Scratch buffers are pooled per detector, so detection doesn't allocate them on every call while a detector
+	// remains safe for concurrent use.
+	scratch struct {
+		input    []float64    // Windowed, decimated and zero-padded frame of the FFT size.
+		bins     []float64    // Magnitude spectrum of the frame.
+		sqrMag   []float64    // Weighted squared magnitude spectrum, mirrored to the full FFT size.
+		yin      []float64    // Cumulative mean normalized difference function.
+		spectrum []complex128 // Transform of the frame, then the autocorrelation of the squared magnitude spectrum.
+		peaks    peaks.Buffer // Storage of the peak detector, separate from the contiguous buffers.
+		samples  []float64    // Converted input samples of the frame size, allocated on first use.
+		// Minima of the yin function, e.g. the candidates of a PitchTracker, grown on first use.
+		candidates []Candidate
+	}
+	// Scratch holds all temporary memory of a detection, for callers owning it explicitly, see DetectFromFrameInto.
+	// A Scratch must not be used concurrently, while the detector it was created by may be.
+	Scratch struct {
+		scratch *scratch
+	}
+)
+
+// scratchLengths returns the number of float64 values of the scratch buffers for the FFT size, in field order, where
+// each complex value takes two.
+func scratchLengths(fftSize int) []int {
+	bins := fftSize/2 + 1
+	return []int{fftSize, bins, fftSize, bins, 2 * fftSize}
+}
+
+// scratchLength returns the number of float64 values in the contiguous scratch storage for the FFT size.
+func scratchLength(fftSize int) int {
+	total := 0
+	for _, length := range scratchLengths(fftSize) {
+		total += internal.AlignedLength(length)
+	}
+	return total
+}
+
+func newScratch(fftSize int) *scratch {
+	storage := internal.AlignedFloats(scratchLength(fftSize))
+	buffers := make([][]float64, 0, 5)
+	offset := 0
+	for _, length := range scratchLengths(fftSize) {
+		buffers = append(buffers, storage[offset:offset+length:offset+length])
+		offset += internal.AlignedLength(length)
+	}
+	return &scratch{
+		input:    buffers[0],
+		bins:     buffers[1],
+		sqrMag:   buffers[2],
+		yin:      buffers[3],
+		spectrum: unsafe.Slice((*complex128)(unsafe.Pointer(unsafe.SliceData(buffers[4]))), fftSize),
+		peaks:    peaks.NewBuffer(fftSize/2+1, 1), // The detector looks for a single peak of the YIN function.
+	}
+}
+
+// ScratchSize returns the size in bytes of the temporary buffers each concurrent detection holds. Buffers are
+// pooled and reused between calls, so this is the memory cost per simultaneously running detection.
+func (pd *PitchDetector) ScratchSize() int {
+	return scratchLength(pd.params.FFTSize()) * 8
+}
+
+// NewScratch allocates the temporary memory of a detection with the detector, see DetectFromFrameInto.
+func (pd *PitchDetector) NewScratch() *Scratch {
+	return &Scratch{scratch: newScratch(pd.params.FFTSize())}
+}
+
+// DetectFromFrameInto is DetectFromFrame using the caller's scratch memory instead of the detector's pooled buffers,
+// so it doesn't allocate and doesn't contend on the pool when a detector is shared across goroutines. Each goroutine
+// needs its own Scratch.
+func (pd *PitchDetector) DetectFromFrameInto(frame []float64, scratch *Scratch) (float64, float64, error) {
+	if len(scratch.scratch.input) != pd.params.FFTSize() {
+		return 0, 0, fmt.Errorf(
+			"invalid scratch: created for FFT size %d, expected %d", len(scratch.scratch.input), pd.params.FFTSize(),
+		)
+	}
+	if err := pd.checkFrame(frame); err != nil {
+		return 0, 0, err
+	}
+	frequency, confidence, _, err := pd.detectFrame(frame, scratch.scratch)
+	return frequency, confidence, err
+}
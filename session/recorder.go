@@ -0,0 +1,204 @@
+// Package session records detection sessions, capturing the incoming audio together with the time-aligned pitch
+// track and the detector parameters, so problematic detections can be reproduced and debugged offline.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/internal/wavio"
+)
+
+// Names of the files a session directory consists of.
+const (
+	AudioFileName  = "audio.wav"
+	TrackFileName  = "pitch.tsv"
+	ParamsFileName = "params.json"
+)
+
+type (
+	// Point is a single entry of a pitch track.
+	Point struct {
+		Time       float64 // Start of the analyzed frame in seconds.
+		Frequency  float64 // Detected frequency in Hz.
+		Confidence float64 // Detection confidence.
+	}
+	// Recorder writes the incoming audio to a WAVE file while detecting pitch on it and writing the pitch track.
+	Recorder struct {
+		params    yinfft.Params
+		detector  *yinfft.PitchDetector
+		audioFile *os.File
+		audio     *wavio.Writer
+		trackFile *os.File
+		track     *bufio.Writer
+		pending   []float64
+		frame     []float64
+		hopSize   int
+		frames    int
+	}
+)
+
+// NewRecorder creates the session directory and a Recorder writing into it. Params with a custom WindowFunc or
+// WeightFunc are rejected, as functions can't be persisted and the session couldn't be replayed as recorded.
+func NewRecorder(dir string, params yinfft.Params) (*Recorder, error) {
+	if params.WindowFunc != nil || params.WeightFunc != nil {
+		return nil, errors.New("invalid params: custom 'WindowFunc' and 'WeightFunc' can't be persisted")
+	}
+
+	detector, err := yinfft.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize pitch detector: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	encodedParams, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode params: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ParamsFileName), encodedParams, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write params: %w", err)
+	}
+
+	audioFile, err := os.Create(filepath.Join(dir, AudioFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audio file: %w", err)
+	}
+	audio, err := wavio.NewWriter(audioFile, int(params.SampleRate))
+	if err != nil {
+		audioFile.Close()
+		return nil, err
+	}
+
+	trackFile, err := os.Create(filepath.Join(dir, TrackFileName))
+	if err != nil {
+		audioFile.Close()
+		return nil, fmt.Errorf("failed to create pitch track file: %w", err)
+	}
+	track := bufio.NewWriter(trackFile)
+	if _, err := track.WriteString("time\tfrequency\tconfidence\n"); err != nil {
+		audioFile.Close()
+		trackFile.Close()
+		return nil, fmt.Errorf("failed to write pitch track header: %w", err)
+	}
+
+	return &Recorder{
+		params:    params,
+		detector:  detector,
+		audioFile: audioFile,
+		audio:     audio,
+		trackFile: trackFile,
+		track:     track,
+		frame:     make([]float64, params.FrameSize),
+		hopSize:   hopSize(params),
+	}, nil
+}
+
+// Write appends samples to the recorded audio and runs detection for every complete frame, writing the results to
+// the pitch track and returning them. Frames advance by HopSize samples, like in DetectAll, the i-th frame starting at
+// sample i*HopSize. Samples not filling a complete frame are kept for the next call.
+func (r *Recorder) Write(samples []float64) ([]Point, error) {
+	if err := r.audio.Write(samples); err != nil {
+		return nil, fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	r.pending = append(r.pending, samples...)
+
+	var points []Point
+	consumed := 0
+	defer func() {
+		r.pending = append(r.pending[:0], r.pending[consumed:]...)
+	}()
+
+	for len(r.pending)-consumed >= r.params.FrameSize {
+		copy(r.frame, r.pending[consumed:consumed+r.params.FrameSize])
+		consumed += r.hopSize
+
+		frequency, confidence, err := r.detector.DetectFromFrame(r.frame)
+		if err != nil {
+			return points, fmt.Errorf("failed to detect pitch for frame %d: %w", r.frames, err)
+		}
+
+		point := Point{
+			Time:       float64(r.frames*r.hopSize) / r.params.SampleRate,
+			Frequency:  frequency,
+			Confidence: confidence,
+		}
+		if err := r.writePoint(point); err != nil {
+			return points, fmt.Errorf("failed to write pitch track: %w", err)
+		}
+		points = append(points, point)
+		r.frames++
+	}
+
+	return points, nil
+}
+
+// Flush runs detection for the samples not filling a complete frame, zero-padded to the frame size, writing the
+// result to the pitch track and returning it. Returns nil if all pending samples were already covered by a frame.
+func (r *Recorder) Flush() ([]Point, error) {
+	covered := 0
+	if r.frames > 0 {
+		covered = r.params.FrameSize - r.hopSize
+	}
+	if len(r.pending) <= covered {
+		return nil, nil
+	}
+	clear(r.frame)
+	copy(r.frame, r.pending)
+	r.pending = r.pending[:0]
+
+	frequency, confidence, err := r.detector.DetectFromFrame(r.frame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect pitch for frame %d: %w", r.frames, err)
+	}
+	point := Point{
+		Time:       float64(r.frames*r.hopSize) / r.params.SampleRate,
+		Frequency:  frequency,
+		Confidence: confidence,
+	}
+	if err := r.writePoint(point); err != nil {
+		return nil, fmt.Errorf("failed to write pitch track: %w", err)
+	}
+	r.frames++
+
+	return []Point{point}, nil
+}
+
+// Close finalizes the audio file and flushes the pitch track. Samples not filling a complete frame are kept in the
+// audio file but have no entry in the pitch track unless Flush was called.
+func (r *Recorder) Close() error {
+	return errors.Join(
+		r.audio.Close(),
+		r.audioFile.Close(),
+		r.track.Flush(),
+		r.trackFile.Close(),
+	)
+}
+
+func hopSize(params yinfft.Params) int {
+	if params.HopSize == 0 {
+		return params.FrameSize
+	}
+	return params.HopSize
+}
+
+func (r *Recorder) writePoint(point Point) error {
+	line := make([]byte, 0, 64)
+	line = strconv.AppendFloat(line, point.Time, 'f', 6, 64)
+	line = append(line, '\t')
+	line = strconv.AppendFloat(line, point.Frequency, 'f', 4, 64)
+	line = append(line, '\t')
+	line = strconv.AppendFloat(line, point.Confidence, 'f', 6, 64)
+	line = append(line, '\n')
+	_, err := r.track.Write(line)
+	return err
+}
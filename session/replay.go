@@ -0,0 +1,144 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/internal/wavio"
+)
+
+type (
+	// Session is a recorded session loaded from disk.
+	Session struct {
+		Params yinfft.Params // Parameters the session was recorded with.
+		Audio  []float64     // Recorded audio samples.
+		Track  []Point       // Pitch track detected while recording.
+	}
+	// ReplayOptions configures how a session is replayed.
+	ReplayOptions struct {
+		Params *yinfft.Params // Parameters overriding the recorded ones, e.g. to regression-test a change.
+		Speed  float64        // Playback speed relative to realtime; zero replays as fast as possible.
+	}
+)
+
+// Load reads a session recorded by Recorder from the given directory.
+func Load(dir string) (*Session, error) {
+	encodedParams, err := os.ReadFile(filepath.Join(dir, ParamsFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read params: %w", err)
+	}
+	var params yinfft.Params
+	if err := json.Unmarshal(encodedParams, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode params: %w", err)
+	}
+
+	audioFile, err := os.Open(filepath.Join(dir, AudioFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer audioFile.Close()
+
+	audio, err := wavio.Read(bufio.NewReader(audioFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if audio.Channels != 1 {
+		return nil, fmt.Errorf("recorded audio must be mono, got %d channels", audio.Channels)
+	}
+
+	track, err := readTrack(filepath.Join(dir, TrackFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{Params: params, Audio: audio.Data, Track: track}, nil
+}
+
+// Replay runs the recorded audio through a pitch detector frame by frame, advancing by HopSize samples like Recorder,
+// yielding the detected pitch track. When Speed is set, frames are paced to arrive as they would from a live source,
+// played back Speed times faster.
+func (s *Session) Replay(ctx context.Context, options ReplayOptions) iter.Seq2[Point, error] {
+	return func(yield func(Point, error) bool) {
+		params := s.Params
+		if options.Params != nil {
+			params = *options.Params
+		}
+
+		detector, err := yinfft.New(params)
+		if err != nil {
+			yield(Point{}, fmt.Errorf("failed to initialize pitch detector: %w", err))
+			return
+		}
+
+		hopSize := hopSize(params)
+		var ticker *time.Ticker
+		if options.Speed > 0 {
+			frameDuration := float64(hopSize) / params.SampleRate / options.Speed
+			ticker = time.NewTicker(time.Duration(frameDuration * float64(time.Second)))
+			defer ticker.Stop()
+		}
+
+		frame := make([]float64, params.FrameSize)
+		for i := 0; i+params.FrameSize <= len(s.Audio); i += hopSize {
+			if ticker != nil {
+				select {
+				case <-ctx.Done():
+					yield(Point{}, ctx.Err())
+					return
+				case <-ticker.C:
+				}
+			} else if err := ctx.Err(); err != nil {
+				yield(Point{}, err)
+				return
+			}
+
+			copy(frame, s.Audio[i:i+params.FrameSize])
+			frequency, confidence, err := detector.DetectFromFrame(frame)
+			point := Point{Time: float64(i) / params.SampleRate, Frequency: frequency, Confidence: confidence}
+			if !yield(point, err) {
+				return
+			}
+		}
+	}
+}
+
+func readTrack(path string) ([]Point, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pitch track: %w", err)
+	}
+	defer file.Close()
+
+	var track []Point
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if line == 1 {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid pitch track line %d: expected 3 fields, got %d", line, len(fields))
+		}
+		var values [3]float64
+		for i, field := range fields {
+			if values[i], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("invalid pitch track line %d: %w", line, err)
+			}
+		}
+		track = append(track, Point{Time: values[0], Frequency: values[1], Confidence: values[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pitch track: %w", err)
+	}
+
+	return track, nil
+}
@@ -0,0 +1,242 @@
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/session"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "session")
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+
+	recorder, err := session.NewRecorder(dir, params)
+	if err != nil {
+		t.Fatalf("error creating recorder: %v", err)
+	}
+
+	signal := make([]float64, 3*params.FrameSize+100)
+	for i := range signal {
+		signal[i] = 0.5 * math.Sin(2*math.Pi*220*float64(i)/params.SampleRate)
+	}
+
+	// Chunks not aligned with frames are buffered until a frame is complete.
+	var recorded []session.Point
+	for chunk := 0; chunk < len(signal); chunk += 1000 {
+		points, err := recorder.Write(signal[chunk:min(chunk+1000, len(signal))])
+		if err != nil {
+			t.Fatalf("error recording: %v", err)
+		}
+		recorded = append(recorded, points...)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("error closing recorder: %v", err)
+	}
+
+	if len(recorded) != 3 {
+		t.Fatalf("incorrect number of recorded points, got %d, want 3", len(recorded))
+	}
+	for i, point := range recorded {
+		if want := float64(i*params.FrameSize) / params.SampleRate; point.Time != want {
+			t.Errorf("incorrect time of point %d, got %v, want %v", i, point.Time, want)
+		}
+		if cents := 1200 * math.Log2(point.Frequency/220); math.Abs(cents) > 20 {
+			t.Errorf("incorrect frequency of point %d, got %.2f Hz, want 220 Hz", i, point.Frequency)
+		}
+	}
+
+	track, err := os.ReadFile(filepath.Join(dir, session.TrackFileName))
+	if err != nil {
+		t.Fatalf("error reading pitch track: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(track), "\n"), "\n"); len(lines) != 4 ||
+		lines[0] != "time\tfrequency\tconfidence" {
+		t.Errorf("incorrect pitch track:\n%s", track)
+	}
+
+	audio, err := os.Stat(filepath.Join(dir, session.AudioFileName))
+	if err != nil {
+		t.Fatalf("error reading audio file: %v", err)
+	}
+	if want := int64(44 + 4*len(signal)); audio.Size() != want {
+		t.Errorf("incorrect audio file size, got %d, want %d", audio.Size(), want)
+	}
+
+	encodedParams, err := os.ReadFile(filepath.Join(dir, session.ParamsFileName))
+	if err != nil {
+		t.Fatalf("error reading params: %v", err)
+	}
+	var decodedParams yinfft.Params
+	if err := json.Unmarshal(encodedParams, &decodedParams); err != nil {
+		t.Fatalf("error decoding params: %v", err)
+	}
+	if decodedParams.FrameSize != params.FrameSize || decodedParams.SampleRate != params.SampleRate {
+		t.Errorf("incorrect recorded params, got %+v, want %+v", decodedParams, params)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+
+	recorder, err := session.NewRecorder(dir, params)
+	if err != nil {
+		t.Fatalf("error creating recorder: %v", err)
+	}
+
+	signal := make([]float64, 3*params.FrameSize+100)
+	for i := range signal {
+		signal[i] = 0.5 * math.Sin(2*math.Pi*220*float64(i)/params.SampleRate)
+	}
+
+	var recorded []session.Point
+	for chunk := 0; chunk < len(signal); chunk += 1000 {
+		points, err := recorder.Write(signal[chunk:min(chunk+1000, len(signal))])
+		if err != nil {
+			t.Fatalf("error recording: %v", err)
+		}
+		recorded = append(recorded, points...)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("error closing recorder: %v", err)
+	}
+
+	loaded, err := session.Load(dir)
+	if err != nil {
+		t.Fatalf("error loading session: %v", err)
+	}
+	if len(loaded.Audio) != len(signal) {
+		t.Errorf("incorrect recorded audio length, got %d, want %d", len(loaded.Audio), len(signal))
+	}
+	if len(loaded.Track) != len(recorded) || len(recorded) != 3 {
+		t.Fatalf("incorrect pitch track length, got %d loaded and %d recorded, want 3", len(loaded.Track), len(recorded))
+	}
+
+	i := 0
+	for point, err := range loaded.Replay(context.Background(), session.ReplayOptions{}) {
+		if err != nil {
+			t.Fatalf("error replaying session: %v", err)
+		}
+		if math.Abs(point.Frequency-recorded[i].Frequency) > 1e-3 || point.Time != recorded[i].Time {
+			t.Errorf("replayed point %d differs, got %+v, want %+v", i, point, recorded[i])
+		}
+		i++
+	}
+}
+
+func TestRecorder_HopSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	params := yinfft.DefaultParams
+	params.FrameSize, params.HopSize = 2048, 512
+
+	recorder, err := session.NewRecorder(dir, params)
+	if err != nil {
+		t.Fatalf("error creating recorder: %v", err)
+	}
+
+	signal := make([]float64, 3*params.FrameSize+100)
+	for i := range signal {
+		signal[i] = 0.5 * math.Sin(2*math.Pi*220*float64(i)/params.SampleRate)
+	}
+
+	var recorded []session.Point
+	for chunk := 0; chunk < len(signal); chunk += 1000 {
+		points, err := recorder.Write(signal[chunk:min(chunk+1000, len(signal))])
+		if err != nil {
+			t.Fatalf("error recording: %v", err)
+		}
+		recorded = append(recorded, points...)
+	}
+	// The last frame ended past every pending sample but the final 100, which are flushed in a padded frame.
+	flushed, err := recorder.Flush()
+	if err != nil {
+		t.Fatalf("error flushing recorder: %v", err)
+	}
+	if len(flushed) != 1 {
+		t.Errorf("incorrect number of flushed points, got %d, want 1", len(flushed))
+	}
+	if flushed, err := recorder.Flush(); err != nil || flushed != nil {
+		t.Errorf("second flush returned %+v, %v, want nil", flushed, err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("error closing recorder: %v", err)
+	}
+
+	detector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	want, err := detector.DetectAll(signal)
+	if err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+	if len(recorded) != len(want) || len(want) != 9 {
+		t.Fatalf("incorrect number of recorded points, got %d, want %d", len(recorded), len(want))
+	}
+	for i, point := range recorded {
+		if point.Time != want[i].Time || math.Abs(point.Frequency-want[i].Frequency) > 1e-3 {
+			t.Errorf("recorded point %d differs, got %+v, want %+v", i, point, want[i])
+		}
+	}
+
+	loaded, err := session.Load(dir)
+	if err != nil {
+		t.Fatalf("error loading session: %v", err)
+	}
+	i := 0
+	for point, err := range loaded.Replay(context.Background(), session.ReplayOptions{}) {
+		if err != nil {
+			t.Fatalf("error replaying session: %v", err)
+		}
+		if point.Time != recorded[i].Time || math.Abs(point.Frequency-recorded[i].Frequency) > 1e-3 {
+			t.Errorf("replayed point %d differs, got %+v, want %+v", i, point, recorded[i])
+		}
+		i++
+	}
+	if i != len(recorded) {
+		t.Errorf("incorrect number of replayed points, got %d, want %d", i, len(recorded))
+	}
+}
+
+func TestNewRecorder_UnpersistableParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		params func(*yinfft.Params)
+	}{
+		{"window func", func(p *yinfft.Params) { p.WindowFunc = func(i, n int) float64 { return 1 } }},
+		{"weight func", func(p *yinfft.Params) { p.WeightFunc = func(frequency float64) float64 { return 1 } }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join(t.TempDir(), "session")
+			params := yinfft.DefaultParams
+			test.params(&params)
+
+			if _, err := session.NewRecorder(dir, params); err == nil {
+				t.Fatal("expected error for params that can't be persisted")
+			}
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("session directory was created, stat error: %v", err)
+			}
+		})
+	}
+}
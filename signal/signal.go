@@ -0,0 +1,175 @@
+// Package signal generates synthetic test signals with known pitch, such as harmonic tones, chirps and vibrato, and
+// adds colored noise at a given signal-to-noise ratio, for writing accuracy tests of pitch detection.
+package signal
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// NoiseColor defines the power spectrum of noise.
+type NoiseColor string
+
+const (
+	NoiseWhite NoiseColor = "white" // Flat power spectrum.
+	NoisePink  NoiseColor = "pink"  // Power falling by 3 dB per octave, equal power per octave.
+	NoiseBrown NoiseColor = "brown" // Power falling by 6 dB per octave, like a random walk.
+)
+
+// Sine returns n samples of a sine of unit amplitude and the given frequency in Hz, starting at phase zero.
+func Sine(frequency, sampleRate float64, n int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = math.Sin(2 * math.Pi * frequency * float64(i) / sampleRate)
+	}
+	return samples
+}
+
+// Harmonic returns n samples of a harmonic complex tone with the given number of partials at multiples of the
+// fundamental frequency in Hz. Partial k has an amplitude of k^-rolloff, e.g. 0 for equal amplitudes and 1 for a
+// sawtooth-like spectrum, and partials above the Nyquist frequency are left out. The amplitudes are scaled to sum to
+// one, so the tone never exceeds unit amplitude.
+func Harmonic(fundamental, sampleRate float64, n, partials int, rolloff float64) []float64 {
+	samples := make([]float64, n)
+	var total float64
+	for k := 1; k <= partials && float64(k)*fundamental < sampleRate/2; k++ {
+		amplitude := math.Pow(float64(k), -rolloff)
+		total += amplitude
+		step := 2 * math.Pi * float64(k) * fundamental / sampleRate
+		for i := range samples {
+			samples[i] += amplitude * math.Sin(step*float64(i))
+		}
+	}
+	if total > 0 {
+		for i := range samples {
+			samples[i] /= total
+		}
+	}
+	return samples
+}
+
+// LinearChirp returns n samples of a sine of unit amplitude sweeping linearly from the start to the end frequency in
+// Hz. Its frequency at time t in seconds is start + (end-start)*t/T, with T the duration of the signal.
+func LinearChirp(start, end, sampleRate float64, n int) []float64 {
+	duration := float64(n) / sampleRate
+	samples := make([]float64, n)
+	for i := range samples {
+		t := float64(i) / sampleRate
+		samples[i] = math.Sin(2 * math.Pi * (start*t + (end-start)*t*t/(2*duration)))
+	}
+	return samples
+}
+
+// ExponentialChirp returns n samples of a sine of unit amplitude sweeping from the start to the end frequency in Hz
+// at a constant rate in octaves per second, the natural sweep for pitch. Its frequency at time t in seconds is
+// start * (end/start)^(t/T), with T the duration of the signal.
+func ExponentialChirp(start, end, sampleRate float64, n int) []float64 {
+	duration := float64(n) / sampleRate
+	rate := math.Log(end/start) / duration
+	samples := make([]float64, n)
+	for i := range samples {
+		t := float64(i) / sampleRate
+		phase := start * t
+		if rate != 0 {
+			phase = start * math.Expm1(rate*t) / rate
+		}
+		samples[i] = math.Sin(2 * math.Pi * phase)
+	}
+	return samples
+}
+
+// Vibrato returns n samples of a sine of unit amplitude whose frequency oscillates sinusoidally around the center
+// frequency in Hz, rate times per second and depth cents up and down, as in sung and bowed notes. Its frequency at
+// time t in seconds is frequency * 2^(depth/1200 * sin(2*pi*rate*t)).
+func Vibrato(frequency, rate, depth, sampleRate float64, n int) []float64 {
+	samples := make([]float64, n)
+	var phase float64
+	for i := range samples {
+		samples[i] = math.Sin(phase)
+		t := float64(i) / sampleRate
+		instantaneous := frequency * math.Exp2(depth/1200*math.Sin(2*math.Pi*rate*t))
+		phase = math.Mod(phase+2*math.Pi*instantaneous/sampleRate, 2*math.Pi)
+	}
+	return samples
+}
+
+// Noise returns n samples of noise of the given color with unit RMS level, drawn from the random source, or from the
+// global source of math/rand/v2 if nil.
+func Noise(color NoiseColor, n int, random *rand.Rand) ([]float64, error) {
+	normal := rand.NormFloat64
+	if random != nil {
+		normal = random.NormFloat64
+	}
+
+	samples := make([]float64, n)
+	switch color {
+	case NoiseWhite:
+		for i := range samples {
+			samples[i] = normal()
+		}
+	case NoisePink:
+		// Paul Kellet's filter bank approximating a -3 dB per octave slope within 0.05 dB above a tenth of the Nyquist
+		// frequency.
+		var b0, b1, b2, b3, b4, b5, b6 float64
+		for i := range samples {
+			white := normal()
+			b0 = 0.99886*b0 + white*0.0555179
+			b1 = 0.99332*b1 + white*0.0750759
+			b2 = 0.96900*b2 + white*0.1538520
+			b3 = 0.86650*b3 + white*0.3104856
+			b4 = 0.55000*b4 + white*0.5329522
+			b5 = -0.7616*b5 - white*0.0168980
+			samples[i] = b0 + b1 + b2 + b3 + b4 + b5 + b6 + white*0.5362
+			b6 = white * 0.115926
+		}
+	case NoiseBrown:
+		// A slightly leaky integrator, so the walk doesn't drift away at the lowest frequencies.
+		var walk float64
+		for i := range samples {
+			walk = 0.998*walk + normal()
+			samples[i] = walk
+		}
+	default:
+		return nil, fmt.Errorf("invalid noise color: %q", color)
+	}
+
+	if level := rms(samples); level > 0 {
+		scale(samples, 1/level)
+	}
+	return samples, nil
+}
+
+// AddNoise returns the signal with noise of the given color added at the signal-to-noise ratio in dB, relative to
+// the RMS level of the signal, so silence stays silent. The noise is drawn from the random source, or from the
+// global source if nil.
+func AddNoise(signal []float64, color NoiseColor, snr float64, random *rand.Rand) ([]float64, error) {
+	noise, err := Noise(color, len(signal), random)
+	if err != nil {
+		return nil, err
+	}
+	scale(noise, rms(signal)*math.Pow(10, -snr/20))
+	for i, sample := range signal {
+		noise[i] += sample
+	}
+	return noise, nil
+}
+
+// rms returns the root mean square of the samples, zero if there are none.
+func rms(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range samples {
+		sum += sample * sample
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// scale multiplies the samples by the factor in place.
+func scale(samples []float64, factor float64) {
+	for i := range samples {
+		samples[i] *= factor
+	}
+}
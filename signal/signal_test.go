@@ -0,0 +1,142 @@
+package signal_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/signal"
+)
+
+func TestGenerators(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	params := detector.Params()
+	frameSize, sampleRate := params.FrameSize, params.SampleRate
+	duration := float64(4*frameSize) / sampleRate
+
+	tests := []struct {
+		name    string
+		samples []float64
+		// Frequency of the signal at time t in seconds.
+		frequency func(t float64) float64
+	}{
+		{
+			name:      "sine",
+			samples:   signal.Sine(440, sampleRate, 4*frameSize),
+			frequency: func(float64) float64 { return 440 },
+		},
+		{
+			name:      "harmonic tone",
+			samples:   signal.Harmonic(110, sampleRate, 4*frameSize, 10, 1),
+			frequency: func(float64) float64 { return 110 },
+		},
+		{
+			name:      "linear chirp",
+			samples:   signal.LinearChirp(200, 400, sampleRate, 4*frameSize),
+			frequency: func(t float64) float64 { return 200 + 200*t/duration },
+		},
+		{
+			name:      "exponential chirp",
+			samples:   signal.ExponentialChirp(200, 300, sampleRate, 4*frameSize),
+			frequency: func(t float64) float64 { return 200 * math.Pow(1.5, t/duration) },
+		},
+		{
+			name:    "vibrato",
+			samples: signal.Vibrato(330, 1, 50, sampleRate, 4*frameSize),
+			frequency: func(t float64) float64 {
+				return 330 * math.Exp2(50.0/1200*math.Sin(2*math.Pi*t))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for start := 0; start+frameSize <= len(test.samples); start += frameSize {
+				frame := append([]float64(nil), test.samples[start:start+frameSize]...)
+				frequency, _, err := detector.DetectFromFrame(frame)
+				if err != nil {
+					t.Fatalf("error detecting pitch: %v", err)
+				}
+				// A changing pitch is compared with the frequency at the center of the frame.
+				want := test.frequency((float64(start) + float64(frameSize)/2) / sampleRate)
+				if cents := 1200 * math.Log2(frequency/want); math.Abs(cents) > 10 {
+					t.Errorf("incorrect frequency of frame at %d, got %.2f Hz, want %.2f Hz", start, frequency, want)
+				}
+			}
+		})
+	}
+
+	for _, sample := range signal.Harmonic(110, sampleRate, frameSize, 10, 0) {
+		if math.Abs(sample) > 1 {
+			t.Fatalf("harmonic tone exceeds unit amplitude: %g", sample)
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		color                          signal.NoiseColor
+		minCorrelation, maxCorrelation float64 // Range of the correlation of adjacent samples.
+	}{
+		{signal.NoiseWhite, -0.05, 0.05},
+		{signal.NoisePink, 0.5, 0.95},
+		{signal.NoiseBrown, 0.95, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.color), func(t *testing.T) {
+			t.Parallel()
+
+			noise, err := signal.Noise(test.color, 1<<16, rand.New(rand.NewPCG(1, 2)))
+			if err != nil {
+				t.Fatalf("error generating noise: %v", err)
+			}
+			var power, correlation float64
+			for i, sample := range noise {
+				power += sample * sample
+				if i > 0 {
+					correlation += sample * noise[i-1]
+				}
+			}
+			if level := math.Sqrt(power / float64(len(noise))); math.Abs(level-1) > 1e-9 {
+				t.Errorf("incorrect RMS level, got %g, want 1", level)
+			}
+			if correlation /= power; correlation < test.minCorrelation || correlation > test.maxCorrelation {
+				t.Errorf("incorrect correlation of adjacent samples, got %.3f, want [%.2f, %.2f]",
+					correlation, test.minCorrelation, test.maxCorrelation)
+			}
+		})
+	}
+
+	if _, err := signal.Noise("purple", 16, nil); err == nil {
+		t.Error("expected an error for an unknown color")
+	}
+}
+
+func TestAddNoise(t *testing.T) {
+	t.Parallel()
+
+	tone := signal.Sine(440, 44100, 1<<16)
+	noisy, err := signal.AddNoise(tone, signal.NoisePink, 20, rand.New(rand.NewPCG(1, 2)))
+	if err != nil {
+		t.Fatalf("error adding noise: %v", err)
+	}
+	var signalPower, noisePower float64
+	for i := range tone {
+		signalPower += tone[i] * tone[i]
+		noisePower += (noisy[i] - tone[i]) * (noisy[i] - tone[i])
+	}
+	if snr := 10 * math.Log10(signalPower/noisePower); math.Abs(snr-20) > 1e-6 {
+		t.Errorf("incorrect signal-to-noise ratio, got %.3f dB, want 20 dB", snr)
+	}
+}
@@ -0,0 +1,53 @@
+package yinfft
+
+import "math"
+
+// detectFrame detects the fundamental frequency of a checked frame using the given scratch buffers, and returns the
+// mean square of the frame too. The mean square is accumulated in the windowing pass, after which frames below
+// SilenceThresholdDB are gated without being transformed, like the silence gate of aubio; it gives the level of
+// Result too.
+func (pd *PitchDetector) detectFrame(
+	frame []float64, scratch *scratch,
+) (frequency float64, confidence float64, power float64, err error) {
+	spectrum, power := pd.gatedSpectrum(frame, scratch)
+	if spectrum == nil {
+		frequency, confidence, err = pd.noPitch()
+		return frequency, confidence, power, err
+	}
+	frequency, confidence, err = pd.detect(spectrum, scratch)
+	return frequency, confidence, power, err
+}
+
+// detectResult checks the frame and detects its pitch into a Result with its level, using the given scratch buffers.
+func (pd *PitchDetector) detectResult(frame []float64, scratch *scratch) (Result, error) {
+	if err := pd.checkFrame(frame); err != nil {
+		return Result{}, err
+	}
+	frequency, confidence, power, err := pd.detectFrame(frame, scratch)
+	if err != nil {
+		return Result{}, err
+	}
+	result := newResult(frequency, confidence, pd.params.SampleRate)
+	result.Level = decibels(power)
+	return result, nil
+}
+
+// silent reports whether a frame of the given level in dBFS is below SilenceThresholdDB.
+func (pd *PitchDetector) silent(level float64) bool {
+	return pd.params.SilenceThresholdDB != 0 && level < pd.params.SilenceThresholdDB
+}
+
+// meanSquare returns the mean of the squared samples of the frame, its power relative to full scale, for frames that
+// aren't windowed, see windowFrame.
+func meanSquare(frame []float64) float64 {
+	sum := 0.0
+	for _, sample := range frame {
+		sum += sample * sample
+	}
+	return sum / float64(len(frame))
+}
+
+// decibels converts a power relative to full scale to dBFS, negative infinity for zero.
+func decibels(power float64) float64 {
+	return 10 * math.Log10(power)
+}
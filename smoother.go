@@ -0,0 +1,123 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// SmoothingMode defines how a PitchSmoother filters the detected frequencies.
+type SmoothingMode string
+
+const (
+	SmoothingMedian SmoothingMode = "median" // Median of the last Window voiced frames, removing isolated outliers.
+	SmoothingMean   SmoothingMode = "mean"   // Moving average of the last Window voiced frames in the log domain.
+	SmoothingEMA    SmoothingMode = "ema"    // Exponential moving average in the log domain with weight Alpha.
+)
+
+// DefaultSmoothingWindow is the window of median and mean smoothing if not configured.
+const DefaultSmoothingWindow = 5
+
+type (
+	// SmootherParams configure a PitchSmoother.
+	SmootherParams struct {
+		Mode   SmoothingMode // Smoothing filter, SmoothingMedian is used if empty.
+		Window int           // Number of voiced frames of median and mean smoothing, DefaultSmoothingWindow if zero.
+		Alpha  float64       // Weight of the current frame in EMA smoothing, in (0, 1].
+	}
+	// PitchSmoother wraps a detector and filters its output over consecutive frames of a single stream, suppressing
+	// single-frame octave jumps and jitter. Unvoiced frames are passed through and don't enter the filter.
+	PitchSmoother struct {
+		detector Detector
+		params   SmootherParams
+		history  []float64 // Log2 frequencies of the last voiced frames, oldest first.
+		sorted   []float64
+		ema      float64
+	}
+)
+
+var _ Detector = (*PitchSmoother)(nil)
+
+// NewPitchSmoother creates a PitchSmoother filtering the output of the detector.
+func NewPitchSmoother(detector Detector, params SmootherParams) (*PitchSmoother, error) {
+	if params.Mode == "" {
+		params.Mode = SmoothingMedian
+	}
+	if params.Window == 0 {
+		params.Window = DefaultSmoothingWindow
+	}
+	if !slices.Contains([]SmoothingMode{SmoothingMedian, SmoothingMean, SmoothingEMA}, params.Mode) {
+		return nil, fmt.Errorf(
+			"invalid 'mode': %s, must be one of [%s, %s, %s]", params.Mode, SmoothingMedian, SmoothingMean, SmoothingEMA,
+		)
+	}
+	if params.Window < 1 {
+		return nil, fmt.Errorf("invalid 'window': %d, must be positive", params.Window)
+	}
+	if params.Mode == SmoothingEMA && (params.Alpha <= 0 || params.Alpha > 1) {
+		return nil, fmt.Errorf("invalid 'alpha': %g, must be in range (0, 1]", params.Alpha)
+	}
+	return &PitchSmoother{detector: detector, params: params}, nil
+}
+
+// DetectFromFrame detects the fundamental frequency of the next frame of the stream and returns it smoothed.
+func (s *PitchSmoother) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	frequency, confidence, err = s.detector.DetectFromFrame(frame)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.smooth(frequency), confidence, nil
+}
+
+// DetectFromSpectrum detects the fundamental frequency of the spectrum of the next frame of the stream and returns it
+// smoothed.
+func (s *PitchSmoother) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	frequency, confidence, err = s.detector.DetectFromSpectrum(spectrum)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.smooth(frequency), confidence, nil
+}
+
+// Reset clears the filter, e.g. before processing a new stream.
+func (s *PitchSmoother) Reset() {
+	s.history = s.history[:0]
+	s.ema = 0
+}
+
+func (s *PitchSmoother) smooth(frequency float64) float64 {
+	if !(frequency > 0) {
+		return frequency
+	}
+
+	value := math.Log2(frequency)
+	if s.params.Mode == SmoothingEMA {
+		if s.ema == 0 {
+			s.ema = value
+		} else {
+			s.ema += s.params.Alpha * (value - s.ema)
+		}
+		return math.Exp2(s.ema)
+	}
+
+	if len(s.history) == s.params.Window {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+	s.history = append(s.history, value)
+
+	if s.params.Mode == SmoothingMean {
+		sum := 0.0
+		for _, value := range s.history {
+			sum += value
+		}
+		return math.Exp2(sum / float64(len(s.history)))
+	}
+
+	s.sorted = append(s.sorted[:0], s.history...)
+	slices.Sort(s.sorted)
+	middle := len(s.sorted) / 2
+	if len(s.sorted)%2 == 0 {
+		return math.Exp2((s.sorted[middle-1] + s.sorted[middle]) / 2)
+	}
+	return math.Exp2(s.sorted[middle])
+}
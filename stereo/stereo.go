@@ -0,0 +1,164 @@
+// Package stereo reduces multichannel audio to the mono signal analyzed by the pitch detector. Besides plain
+// downmixing, which can cancel the fundamental when channels are out of phase, channels can be selected or weighted
+// by their harmonicity.
+package stereo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+// DefaultAnalysisFrames is the default number of frames per channel analyzed to compute channel weights.
+const DefaultAnalysisFrames = 8
+
+// Mode defines how channels are combined into a mono signal.
+type Mode string
+
+const (
+	ModeDownmix  Mode = "downmix"  // Channels are averaged.
+	ModeBest     Mode = "best"     // The most harmonic channel is used.
+	ModeWeighted Mode = "weighted" // Channels are weighted by their harmonicity.
+	ModeMid      Mode = "mid"      // The mid signal (L+R)/2 of a stereo source, for sources panned center.
+	ModeSide     Mode = "side"     // The side signal (L-R)/2 of a stereo source, for sources in the sides of a mix.
+)
+
+// Mixer combines interleaved multichannel audio of a single stream into mono. For ModeBest and ModeWeighted, the
+// channel weights are computed once from the beginning of the stream.
+type Mixer struct {
+	detector       yinfft.Detector
+	frameSize      int
+	mode           Mode
+	channels       int
+	AnalysisFrames int // Maximum number of frames per channel analyzed to compute the weights.
+	weights        []float64
+}
+
+// NewMixer creates a Mixer for the given number of channels, using the detector to measure channel harmonicity.
+func NewMixer(detector *yinfft.PitchDetector, mode Mode, channels int) (*Mixer, error) {
+	if channels < 1 {
+		return nil, fmt.Errorf("invalid number of channels: %d", channels)
+	}
+	if !slices.Contains([]Mode{ModeDownmix, ModeBest, ModeWeighted, ModeMid, ModeSide}, mode) {
+		return nil, fmt.Errorf(
+			"invalid mode: %s, must be one of [%s, %s, %s, %s, %s]",
+			mode, ModeDownmix, ModeBest, ModeWeighted, ModeMid, ModeSide,
+		)
+	}
+	if (mode == ModeMid || mode == ModeSide) && channels != 2 {
+		return nil, fmt.Errorf("mode %s requires 2 channels, got %d", mode, channels)
+	}
+	return &Mixer{
+		detector:       detector,
+		frameSize:      detector.Params().FrameSize,
+		mode:           mode,
+		channels:       channels,
+		AnalysisFrames: DefaultAnalysisFrames,
+	}, nil
+}
+
+// Mix returns the mono signal of the interleaved samples. Weights are computed from the first call unless Analyze
+// was called before.
+func (m *Mixer) Mix(interleaved []float64) ([]float64, error) {
+	if m.weights == nil {
+		if err := m.Analyze(interleaved); err != nil {
+			return nil, err
+		}
+	}
+
+	mono := make([]float64, len(interleaved)/m.channels)
+	for i := range mono {
+		for channel, weight := range m.weights {
+			mono[i] += weight * interleaved[i*m.channels+channel]
+		}
+	}
+	return mono, nil
+}
+
+// Analyze computes the channel weights from the beginning of the interleaved samples. Channels are weighted equally
+// in ModeDownmix or when the samples are shorter than a frame; ModeMid and ModeSide use fixed weights. Frames without
+// a pitch, e.g. a silent lead-in, count as zero harmonicity, also when the detector reports them with ErrNoPitch.
+func (m *Mixer) Analyze(interleaved []float64) error {
+	if len(interleaved)%m.channels != 0 {
+		return fmt.Errorf("sample count %d is not a multiple of the channel count %d", len(interleaved), m.channels)
+	}
+	if m.AnalysisFrames < 1 {
+		return fmt.Errorf("invalid 'AnalysisFrames': %d, must be positive", m.AnalysisFrames)
+	}
+
+	switch m.mode {
+	case ModeMid:
+		m.weights = []float64{0.5, 0.5}
+		return nil
+	case ModeSide:
+		m.weights = []float64{0.5, -0.5}
+		return nil
+	}
+
+	m.weights = make([]float64, m.channels)
+	harmonicity := make([]float64, m.channels)
+	if m.mode != ModeDownmix && len(interleaved)/m.channels >= m.frameSize {
+		for channel := range m.channels {
+			value, err := m.harmonicity(interleaved, channel)
+			if err != nil {
+				return fmt.Errorf("failed to analyze channel %d: %w", channel, err)
+			}
+			harmonicity[channel] = value
+		}
+	}
+
+	total := 0.0
+	for _, value := range harmonicity {
+		total += value
+	}
+	switch {
+	case total == 0:
+		for channel := range m.weights {
+			m.weights[channel] = 1 / float64(m.channels)
+		}
+	case m.mode == ModeBest:
+		m.weights[slices.Index(harmonicity, slices.Max(harmonicity))] = 1
+	default:
+		for channel, value := range harmonicity {
+			m.weights[channel] = value / total
+		}
+	}
+
+	return nil
+}
+
+// Weights returns the current channel weights, nil if they haven't been computed yet.
+func (m *Mixer) Weights() []float64 {
+	return slices.Clone(m.weights)
+}
+
+// Reset forgets the channel weights, so they are recomputed for the next stream.
+func (m *Mixer) Reset() {
+	m.weights = nil
+}
+
+// harmonicity returns the mean detection confidence of the first analyzed frames of a channel.
+func (m *Mixer) harmonicity(interleaved []float64, channel int) (float64, error) {
+	frames := min(m.AnalysisFrames, len(interleaved)/m.channels/m.frameSize)
+	frame := make([]float64, m.frameSize)
+
+	total := 0.0
+	for i := range frames {
+		offset := i * m.frameSize * m.channels
+		for j := range frame {
+			frame[j] = interleaved[offset+j*m.channels+channel]
+		}
+		_, confidence, err := m.detector.DetectFromFrame(frame)
+		if errors.Is(err, yinfft.ErrNoPitch) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += confidence
+	}
+
+	return total / float64(frames), nil
+}
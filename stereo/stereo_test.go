@@ -0,0 +1,110 @@
+package stereo_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/stereo"
+)
+
+func TestMixer(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+	detector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	random := rand.New(rand.NewPCG(1, 2))
+	interleaved := make([]float64, 2*4*params.FrameSize)
+	for i := 0; i < len(interleaved); i += 2 {
+		tone := math.Sin(2 * math.Pi * 220 * float64(i/2) / params.SampleRate)
+		interleaved[i], interleaved[i+1] = tone, random.Float64()*2-1
+	}
+
+	tests := []struct {
+		mode        stereo.Mode
+		wantWeights []float64
+	}{
+		{stereo.ModeDownmix, []float64{0.5, 0.5}},
+		{stereo.ModeBest, []float64{1, 0}},
+		{stereo.ModeMid, []float64{0.5, 0.5}},
+		{stereo.ModeSide, []float64{0.5, -0.5}},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.mode), func(t *testing.T) {
+			t.Parallel()
+
+			mixer, err := stereo.NewMixer(detector, test.mode, 2)
+			if err != nil {
+				t.Fatalf("error creating mixer: %v", err)
+			}
+			mono, err := mixer.Mix(interleaved)
+			if err != nil {
+				t.Fatalf("error mixing channels: %v", err)
+			}
+
+			if len(mono) != len(interleaved)/2 {
+				t.Errorf("incorrect mono length, got %d, want %d", len(mono), len(interleaved)/2)
+			}
+			if weights := mixer.Weights(); !slices.Equal(weights, test.wantWeights) {
+				t.Errorf("incorrect channel weights, got %v, want %v", weights, test.wantWeights)
+			}
+		})
+	}
+}
+
+func TestMixer_SilentLeadIn(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize, params.OnNoPitch = 2048, yinfft.NoPitchError
+	detector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	// The left channel is silent for its first frame, the right one throughout.
+	interleaved := make([]float64, 2*4*params.FrameSize)
+	for i := 2 * params.FrameSize; i < len(interleaved); i += 2 {
+		interleaved[i] = math.Sin(2 * math.Pi * 220 * float64(i/2) / params.SampleRate)
+	}
+
+	for _, mode := range []stereo.Mode{stereo.ModeBest, stereo.ModeWeighted} {
+		mixer, err := stereo.NewMixer(detector, mode, 2)
+		if err != nil {
+			t.Fatalf("error creating mixer: %v", err)
+		}
+		if _, err := mixer.Mix(interleaved); err != nil {
+			t.Fatalf("error mixing channels in mode %s: %v", mode, err)
+		}
+		if weights := mixer.Weights(); !slices.Equal(weights, []float64{1, 0}) {
+			t.Errorf("incorrect channel weights in mode %s, got %v, want [1 0]", mode, weights)
+		}
+	}
+}
+
+func TestMixer_InvalidAnalysisFrames(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	mixer, err := stereo.NewMixer(detector, stereo.ModeWeighted, 2)
+	if err != nil {
+		t.Fatalf("error creating mixer: %v", err)
+	}
+
+	mixer.AnalysisFrames = 0
+	interleaved := make([]float64, 2*detector.Params().FrameSize)
+	if mono, err := mixer.Mix(interleaved); err == nil {
+		t.Errorf("expected an error for zero analysis frames, got %d samples", len(mono))
+	}
+}
@@ -0,0 +1,98 @@
+package yinfft
+
+import (
+	"fmt"
+)
+
+// StreamDetector detects pitch on a stream of samples written in blocks of arbitrary size, e.g. from a live
+// microphone. Samples are buffered internally in a ring buffer, and a result is produced for every full frame, with
+// consecutive frames advancing by the hop size.
+type StreamDetector struct {
+	detector *PitchDetector
+	hopSize  int
+	ring     []float64 // Buffered samples, a power of two in length.
+	start    int       // Position of the oldest buffered sample in ring.
+	size     int       // Number of buffered samples.
+	frames   int       // Number of frames polled since the start of the stream.
+	frame    []float64
+}
+
+// NewStreamDetector creates a StreamDetector advancing the analysis frame by hopSize samples per result.
+func (pd *PitchDetector) NewStreamDetector(hopSize int) (*StreamDetector, error) {
+	frameSize := pd.params.FrameSize
+	if hopSize <= 0 || hopSize > frameSize {
+		return nil, fmt.Errorf("invalid hop size: %d, must be in range [1, %d]", hopSize, frameSize)
+	}
+
+	capacity := 1
+	for capacity < 2*frameSize {
+		capacity *= 2
+	}
+	return &StreamDetector{
+		detector: pd,
+		hopSize:  hopSize,
+		ring:     make([]float64, capacity),
+		frame:    make([]float64, frameSize),
+	}, nil
+}
+
+// Write appends samples to the stream, growing the internal buffer if results aren't polled fast enough.
+func (s *StreamDetector) Write(samples []float64) {
+	if s.size+len(samples) > len(s.ring) {
+		s.grow(s.size + len(samples))
+	}
+
+	end := (s.start + s.size) & (len(s.ring) - 1)
+	copied := copy(s.ring[end:], samples)
+	copy(s.ring, samples[copied:])
+	s.size += len(samples)
+}
+
+// Poll detects the pitch of the next full frame and advances the stream by one hop. Returns false if not enough
+// samples are buffered for a frame.
+func (s *StreamDetector) Poll() (Result, bool, error) {
+	if s.size < len(s.frame) {
+		return Result{}, false, nil
+	}
+
+	copied := copy(s.frame, s.ring[s.start:])
+	copy(s.frame[copied:], s.ring)
+	s.start = (s.start + s.hopSize) & (len(s.ring) - 1)
+	s.size -= s.hopSize
+
+	index := s.frames
+	s.frames++
+	result, err := s.detector.Detect(s.frame)
+	if err != nil {
+		return Result{}, true, err
+	}
+
+	result.Frame = index
+	result.Time = float64(index*s.hopSize) / s.detector.params.SampleRate
+	return result, true, nil
+}
+
+// Buffered returns the number of buffered samples.
+func (s *StreamDetector) Buffered() int {
+	return s.size
+}
+
+// Reset discards the buffered samples, e.g. before processing a new stream.
+func (s *StreamDetector) Reset() {
+	s.start = 0
+	s.size = 0
+	s.frames = 0
+}
+
+// grow reallocates the ring buffer to fit at least size samples, moving the buffered samples to its start.
+func (s *StreamDetector) grow(size int) {
+	capacity := len(s.ring)
+	for capacity < size {
+		capacity *= 2
+	}
+	ring := make([]float64, capacity)
+	copied := copy(ring[:s.size], s.ring[s.start:])
+	copy(ring[copied:s.size], s.ring)
+	s.ring = ring
+	s.start = 0
+}
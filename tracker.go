@@ -0,0 +1,116 @@
+package yinfft
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultTrackerCandidates is the number of period candidates a PitchTracker considers if not configured.
+const DefaultTrackerCandidates = 5
+
+type (
+	// TrackerParams configure a PitchTracker.
+	TrackerParams struct {
+		Candidates     int     // Number of period candidates per frame, DefaultTrackerCandidates is used if zero.
+		TransitionCost float64 // Penalty added to a candidate's yin value per 100 cents from the previous pitch.
+	}
+	// PitchTracker detects pitch over a stream of consecutive frames, biasing the choice between period candidates
+	// toward the previously detected pitch to avoid spurious jumps during sustained notes. With a zero
+	// TransitionCost it behaves like the wrapped detector.
+	PitchTracker struct {
+		detector *PitchDetector
+		params   TrackerParams
+		previous float64
+	}
+)
+
+var _ Detector = (*PitchTracker)(nil)
+
+// NewPitchTracker creates a PitchTracker using the detector.
+func (pd *PitchDetector) NewPitchTracker(params TrackerParams) (*PitchTracker, error) {
+	if params.Candidates == 0 {
+		params.Candidates = DefaultTrackerCandidates
+	}
+	if params.Candidates < 1 {
+		return nil, fmt.Errorf("invalid 'Candidates': expected positive value, got %d", params.Candidates)
+	}
+	if params.TransitionCost < 0 || math.IsNaN(params.TransitionCost) {
+		return nil, fmt.Errorf("invalid 'TransitionCost': expected non-negative value, got %v", params.TransitionCost)
+	}
+	return &PitchTracker{detector: pd, params: params}, nil
+}
+
+// DetectFromFrame detects the fundamental frequency of the next frame of the stream. The frame is modified in place.
+// Like the wrapped detector's DetectFromFrame, it uses pooled buffers and gates frames below SilenceThresholdDB.
+func (t *PitchTracker) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	pd := t.detector
+	if err := pd.checkFrame(frame); err != nil {
+		return 0, 0, err
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	spectrum, _ := pd.gatedSpectrum(frame, scratch)
+	if spectrum == nil {
+		t.previous = 0
+		return pd.noPitch()
+	}
+	return t.detect(spectrum, scratch)
+}
+
+// DetectFromSpectrum detects the fundamental frequency of the magnitude spectrum of the next frame of the stream.
+func (t *PitchTracker) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	if err := t.detector.checkSpectrum(spectrum); err != nil {
+		return 0, 0, err
+	}
+
+	scratch := t.detector.scratchPool.Get().(*scratch)
+	defer t.detector.scratchPool.Put(scratch)
+	return t.detect(spectrum, scratch)
+}
+
+// detect implements DetectFromSpectrum on a spectrum of the correct size, using the given scratch buffers, and
+// remembers the detected pitch.
+func (t *PitchTracker) detect(spectrum []float64, scratch *scratch) (frequency float64, confidence float64, err error) {
+	if t.params.TransitionCost == 0 || t.previous == 0 {
+		frequency, confidence, err = t.detector.detect(spectrum, scratch)
+	} else {
+		frequency, confidence, err = t.detectNearPrevious(spectrum, scratch)
+	}
+
+	t.previous = 0
+	if frequency > 0 {
+		t.previous = frequency
+	}
+	return frequency, confidence, err
+}
+
+// detectNearPrevious picks the candidate period with the lowest yin value plus transition cost from the previous
+// pitch. Candidates at or above the detector's Tolerance are unvoiced, and the pick goes through the same octave,
+// missing fundamental and HPS corrections as the wrapped detector's best period.
+func (t *PitchTracker) detectNearPrevious(
+	spectrum []float64, scratch *scratch,
+) (frequency float64, confidence float64, err error) {
+	pd := t.detector
+	var best Candidate
+	bestCost := math.Inf(1)
+	for _, candidate := range pd.bestCandidates(spectrum, t.params.Candidates, scratch) {
+		if pd.params.Tolerance < 1.0 && candidate.Yin >= pd.params.Tolerance {
+			continue
+		}
+		cents := 1200 * math.Abs(math.Log2(candidate.Frequency/t.previous))
+		if cost := candidate.Yin + t.params.TransitionCost*cents/100; cost < bestCost {
+			best, bestCost = candidate, cost
+		}
+	}
+	if math.IsInf(bestCost, 1) {
+		return pd.noPitch()
+	}
+
+	return pd.correctPeriod(spectrum, scratch.yin, pd.params.analysisSampleRate()/best.Frequency, best.Yin)
+}
+
+// Reset forgets the previously detected pitch, e.g. before tracking a new stream.
+func (t *PitchTracker) Reset() {
+	t.previous = 0
+}
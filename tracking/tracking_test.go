@@ -0,0 +1,38 @@
+package tracking_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/tracking"
+)
+
+func TestViterbi_Decode(t *testing.T) {
+	t.Parallel()
+
+	viterbi, err := tracking.NewViterbi(tracking.DefaultParams)
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+
+	// A sustained 220 Hz note with a single-frame octave error, followed by silence.
+	note := []yinfft.Candidate{{Frequency: 220, Probability: 0.8}, {Frequency: 110, Probability: 0.1}}
+	octaveError := []yinfft.Candidate{{Frequency: 440, Probability: 0.5}, {Frequency: 220, Probability: 0.4}}
+	frames := [][]yinfft.Candidate{note, note, note, octaveError, note, note, nil, nil, nil}
+	want := []float64{220, 220, 220, 220, 220, 220, 0, 0, 0}
+
+	results := viterbi.Decode(frames)
+	if len(results) != len(frames) {
+		t.Fatalf("incorrect number of results, got %d, want %d", len(results), len(frames))
+	}
+	for i, result := range results {
+		if math.Abs(result.Frequency-want[i]) > 1 || result.Voiced != (want[i] > 0) {
+			t.Errorf("frame %d: incorrect frequency, got %.2f Hz (voiced %t), want %.2f Hz", i, result.Frequency,
+				result.Voiced, want[i])
+		}
+		if result.Frame != i {
+			t.Errorf("frame %d: incorrect frame index, got %d", i, result.Frame)
+		}
+	}
+}
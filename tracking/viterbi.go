@@ -0,0 +1,204 @@
+// Package tracking smooths frame-level pitch candidates into continuous pitch contours.
+package tracking
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+type (
+	// Params configure a Viterbi decoder.
+	Params struct {
+		MinFrequency     float64 // Lowest frequency of the pitch grid in Hz.
+		MaxFrequency     float64 // Highest frequency of the pitch grid in Hz.
+		BinCents         float64 // Resolution of the pitch grid in cents.
+		MaxJumpCents     float64 // Largest pitch change between consecutive frames in cents.
+		SwitchPenalty    float64 // Probability of switching between voiced and unvoiced between frames, in (0, 1).
+		CandidateTrust   float64 // Weight of the candidate probabilities against the unvoiced state, in (0, 1].
+		ProbabilityFloor float64 // Minimum observation probability, keeping every path finite.
+	}
+	// Viterbi decodes the most likely pitch contour from per-frame candidates with a hidden Markov model over a
+	// logarithmic pitch grid, with a voiced and an unvoiced state per pitch. Pitch changes are penalized linearly
+	// with their size up to MaxJumpCents, and voicing changes by SwitchPenalty.
+	Viterbi struct {
+		params      Params
+		bins        int
+		maxJump     int
+		transitions []float64 // Log probability of a pitch change by the index distance, within one voicing.
+	}
+)
+
+// DefaultParams follow the pYIN pitch tracker.
+var DefaultParams = Params{
+	MinFrequency:     55,
+	MaxFrequency:     1760,
+	BinCents:         10,
+	MaxJumpCents:     250,
+	SwitchPenalty:    0.01,
+	CandidateTrust:   0.5,
+	ProbabilityFloor: 1e-9,
+}
+
+// NewViterbi creates a Viterbi decoder with the given params.
+func NewViterbi(params Params) (*Viterbi, error) {
+	if params.MinFrequency <= 0 || params.MaxFrequency <= params.MinFrequency {
+		return nil, fmt.Errorf("invalid frequency range: [%g, %g] Hz", params.MinFrequency, params.MaxFrequency)
+	}
+	if params.BinCents <= 0 {
+		return nil, fmt.Errorf("invalid 'BinCents': expected positive value, got %g", params.BinCents)
+	}
+	if params.MaxJumpCents < 0 {
+		return nil, fmt.Errorf("invalid 'MaxJumpCents': expected non-negative value, got %g", params.MaxJumpCents)
+	}
+	if params.SwitchPenalty <= 0 || params.SwitchPenalty >= 1 {
+		return nil, fmt.Errorf("invalid 'SwitchPenalty': expected value in range (0, 1), got %g", params.SwitchPenalty)
+	}
+	if params.CandidateTrust <= 0 || params.CandidateTrust > 1 {
+		return nil, fmt.Errorf("invalid 'CandidateTrust': expected value in range (0, 1], got %g", params.CandidateTrust)
+	}
+	if params.ProbabilityFloor <= 0 {
+		return nil, fmt.Errorf("invalid 'ProbabilityFloor': expected positive value, got %g", params.ProbabilityFloor)
+	}
+
+	v := &Viterbi{
+		params:  params,
+		bins:    int(math.Floor(1200*math.Log2(params.MaxFrequency/params.MinFrequency)/params.BinCents)) + 1,
+		maxJump: int(math.Round(params.MaxJumpCents / params.BinCents)),
+	}
+
+	// Triangular distribution of pitch changes, normalized to one.
+	v.transitions = make([]float64, v.maxJump+1)
+	sum := 0.0
+	for distance := range v.transitions {
+		weight := float64(v.maxJump + 1 - distance)
+		v.transitions[distance] = weight
+		sum += weight
+		if distance > 0 {
+			sum += weight
+		}
+	}
+	for distance := range v.transitions {
+		v.transitions[distance] = math.Log(v.transitions[distance] / sum)
+	}
+	return v, nil
+}
+
+// Decode returns the most likely pitch contour for the candidates of consecutive frames, as returned by
+// yinfft.PitchDetector.DetectProbabilistic. Unvoiced frames have zero frequency. Voiced frames report the frequency of
+// the most probable candidate in the decoded pitch bin, or the bin center if there is none, and the probability of the
+// candidates in the bin as confidence.
+func (v *Viterbi) Decode(frames [][]yinfft.Candidate) []yinfft.Result {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	// States 0..bins-1 are voiced, bins..2*bins-1 unvoiced with the same pitch.
+	states := 2 * v.bins
+	scores := make([]float64, states)
+	next := make([]float64, states)
+	backpointers := make([][]int32, len(frames))
+	observations := make([]float64, states)
+
+	stay, switchVoicing := math.Log(1-v.params.SwitchPenalty), math.Log(v.params.SwitchPenalty)
+	for t, candidates := range frames {
+		v.observe(candidates, observations)
+		backpointers[t] = make([]int32, states)
+		if t == 0 {
+			for state := range scores {
+				scores[state] = observations[state] - math.Log(float64(states))
+			}
+			continue
+		}
+
+		for state := range next {
+			bin, voiced := state%v.bins, state < v.bins
+			best, bestScore := int32(0), math.Inf(-1)
+			for from := max(0, bin-v.maxJump); from <= min(v.bins-1, bin+v.maxJump); from++ {
+				distance := from - bin
+				if distance < 0 {
+					distance = -distance
+				}
+				same, other := from, from+v.bins
+				if !voiced {
+					same, other = other, same
+				}
+				if score := scores[same] + stay + v.transitions[distance]; score > bestScore {
+					best, bestScore = int32(same), score
+				}
+				if score := scores[other] + switchVoicing + v.transitions[distance]; score > bestScore {
+					best, bestScore = int32(other), score
+				}
+			}
+			next[state] = bestScore + observations[state]
+			backpointers[t][state] = best
+		}
+		scores, next = next, scores
+	}
+
+	state := 0
+	for candidate := range scores {
+		if scores[candidate] > scores[state] {
+			state = candidate
+		}
+	}
+
+	results := make([]yinfft.Result, len(frames))
+	for t := len(frames) - 1; t >= 0; t-- {
+		results[t] = v.result(frames[t], state)
+		results[t].Frame = t
+		state = int(backpointers[t][state])
+	}
+	return results
+}
+
+// observe computes the log observation probabilities of all states for the candidates of a frame.
+func (v *Viterbi) observe(candidates []yinfft.Candidate, observations []float64) {
+	clear(observations[:v.bins])
+	voicedProbability := 0.0
+	for _, candidate := range candidates {
+		if bin, ok := v.bin(candidate.Frequency); ok {
+			probability := candidate.Probability * v.params.CandidateTrust
+			observations[bin] += probability
+			voicedProbability += probability
+		}
+	}
+
+	unvoiced := math.Log(max((1-voicedProbability)/float64(v.bins), v.params.ProbabilityFloor))
+	for bin := range v.bins {
+		observations[bin] = math.Log(max(observations[bin], v.params.ProbabilityFloor))
+		observations[bin+v.bins] = unvoiced
+	}
+}
+
+// result converts a decoded state into a result.
+func (v *Viterbi) result(candidates []yinfft.Candidate, state int) yinfft.Result {
+	if state >= v.bins {
+		return yinfft.Result{}
+	}
+
+	result := yinfft.Result{
+		Frequency: v.params.MinFrequency * math.Pow(2, float64(state)*v.params.BinCents/1200),
+		Voiced:    true,
+	}
+	bestProbability := -1.0
+	for _, candidate := range candidates {
+		if bin, ok := v.bin(candidate.Frequency); ok && bin == state {
+			result.Confidence += candidate.Probability
+			if candidate.Probability > bestProbability {
+				result.Frequency, result.Tau, bestProbability = candidate.Frequency, candidate.Tau, candidate.Probability
+			}
+		}
+	}
+	return result
+}
+
+// bin returns the pitch grid bin of the frequency, or false if it's outside of the grid.
+func (v *Viterbi) bin(frequency float64) (int, bool) {
+	if frequency <= 0 {
+		return 0, false
+	}
+	bin := int(math.Round(1200 * math.Log2(frequency/v.params.MinFrequency) / v.params.BinCents))
+	return bin, bin >= 0 && bin < v.bins
+}
@@ -0,0 +1,138 @@
+// Package transient detects percussive attacks and drum hits in an audio stream and suppresses or down-weights the
+// pitch detected in them, where the detector otherwise reports confident but meaningless frequencies.
+package transient
+
+import (
+	"fmt"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+type (
+	// Params configure a Detector.
+	Params struct {
+		FluxRatio   float64 // Ratio of the spectral flux to its running average above which a frame is an attack.
+		MinFlux     float64 // Minimum normalized spectral flux of an attack, in [0, 1].
+		MinCrest    float64 // Spectral crest factor (peak over mean magnitude) below which a frame is broadband noise.
+		HoldFrames  int     // Number of frames after an attack that are treated as transient too.
+		Attenuation float64 // Factor applied to the confidence of transient frames in [0, 1), 0 suppresses them.
+	}
+	// Detector detects pitch on consecutive frames of a single stream, suppressing or down-weighting the results of
+	// transient frames. It keeps the previous spectrum between frames, so it must not be shared across streams.
+	Detector struct {
+		detector    *yinfft.PitchDetector
+		params      Params
+		previous    []float64
+		averageFlux float64
+		hold        int
+		transient   bool
+	}
+)
+
+// DefaultParams suppress the pitch of attacks with more than three times the average flux and of noisy frames.
+var DefaultParams = Params{
+	FluxRatio:   3,
+	MinFlux:     0.2,
+	MinCrest:    10,
+	HoldFrames:  1,
+	Attenuation: 0,
+}
+
+// fluxSmoothing is the weight of the current frame in the running average of the spectral flux.
+const fluxSmoothing = 0.1
+
+var _ yinfft.Detector = (*Detector)(nil)
+
+// NewDetector creates a Detector with the given params.
+func NewDetector(detector *yinfft.PitchDetector, params Params) (*Detector, error) {
+	if params.FluxRatio <= 1 {
+		return nil, fmt.Errorf("invalid 'FluxRatio': expected value greater than 1, got %.2f", params.FluxRatio)
+	}
+	if params.MinFlux < 0 || params.MinFlux > 1 {
+		return nil, fmt.Errorf("invalid 'MinFlux': expected value in range [0, 1], got %.2f", params.MinFlux)
+	}
+	if params.MinCrest < 0 {
+		return nil, fmt.Errorf("invalid 'MinCrest': expected non-negative value, got %.2f", params.MinCrest)
+	}
+	if params.HoldFrames < 0 {
+		return nil, fmt.Errorf("invalid 'HoldFrames': expected non-negative value, got %d", params.HoldFrames)
+	}
+	if params.Attenuation < 0 || params.Attenuation >= 1 {
+		return nil, fmt.Errorf("invalid 'Attenuation': expected value in range [0, 1), got %.2f", params.Attenuation)
+	}
+	return &Detector{detector: detector, params: params}, nil
+}
+
+// DetectFromFrame detects the fundamental frequency of the next frame of the stream. The frame is modified in place.
+func (d *Detector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	spectrum, err := d.detector.PrepareSpectrum(frame)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.DetectFromSpectrum(spectrum)
+}
+
+// DetectFromSpectrum detects the fundamental frequency of the magnitude spectrum of the next frame of the stream.
+func (d *Detector) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	d.transient = d.update(spectrum)
+
+	frequency, confidence, err = d.detector.DetectFromSpectrum(spectrum)
+	if err != nil || !d.transient {
+		return frequency, confidence, err
+	}
+	if d.params.Attenuation == 0 {
+		return 0, 0, nil
+	}
+	return frequency, confidence * d.params.Attenuation, nil
+}
+
+// Transient reports whether the last detected frame was transient.
+func (d *Detector) Transient() bool {
+	return d.transient
+}
+
+// Reset forgets the previous frames, e.g. before processing a new stream.
+func (d *Detector) Reset() {
+	d.previous = nil
+	d.averageFlux = 0
+	d.hold = 0
+	d.transient = false
+}
+
+// update classifies the spectrum and updates the running state.
+func (d *Detector) update(spectrum []float64) bool {
+	var sum, peak float64
+	for _, magnitude := range spectrum {
+		sum += magnitude
+		peak = max(peak, magnitude)
+	}
+	if sum == 0 {
+		d.previous = nil
+		return false
+	}
+
+	attack := false
+	if d.previous != nil {
+		// Half-wave rectified flux, normalized by the current energy so it's independent of the level.
+		var flux float64
+		for k, magnitude := range spectrum {
+			flux += max(0, magnitude-d.previous[k])
+		}
+		flux /= sum
+		attack = flux >= d.params.MinFlux && flux > d.params.FluxRatio*d.averageFlux
+		d.averageFlux += fluxSmoothing * (flux - d.averageFlux)
+	} else {
+		d.previous = make([]float64, len(spectrum))
+	}
+	copy(d.previous, spectrum)
+
+	if attack {
+		d.hold = d.params.HoldFrames
+	} else if d.hold > 0 {
+		d.hold--
+		attack = true
+	}
+
+	crest := peak * float64(len(spectrum)) / sum
+	return attack || crest < d.params.MinCrest
+}
@@ -0,0 +1,69 @@
+package transient_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/transient"
+)
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	detector, err := transient.NewDetector(pitchDetector, transient.DefaultParams)
+	if err != nil {
+		t.Fatalf("error creating transient detector: %v", err)
+	}
+	params := pitchDetector.Params()
+
+	random := rand.New(rand.NewPCG(1, 2))
+	tone := func(frame int) []float64 {
+		samples := make([]float64, params.FrameSize)
+		for i := range samples {
+			samples[i] = math.Sin(2 * math.Pi * 440 * float64(frame*params.FrameSize+i) / params.SampleRate)
+		}
+		return samples
+	}
+	hit := func(frame int) []float64 {
+		samples := tone(frame)
+		for i := range samples {
+			samples[i] += 20 * (2*random.Float64() - 1) * math.Exp(-float64(i)/float64(params.FrameSize))
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name          string
+		frame         func(int) []float64
+		wantTransient bool
+	}{
+		{"sustained tone", tone, false},
+		{"sustained tone", tone, false},
+		{"sustained tone", tone, false},
+		{"drum hit", hit, true},
+		{"held after hit", tone, true},
+		{"sustained tone after hit", tone, false},
+	}
+
+	for i, test := range tests {
+		frequency, confidence, err := detector.DetectFromFrame(test.frame(i))
+		if err != nil {
+			t.Fatalf("error detecting pitch for frame %d: %v", i, err)
+		}
+		if detector.Transient() != test.wantTransient {
+			t.Errorf("%s: incorrect transient flag, got %t, want %t", test.name, detector.Transient(), test.wantTransient)
+		}
+		if test.wantTransient && (frequency != 0 || confidence != 0) {
+			t.Errorf("%s: expected suppressed output, got %.2f Hz with confidence %.2f", test.name, frequency, confidence)
+		}
+		if !test.wantTransient && math.Abs(frequency-440) > 1 {
+			t.Errorf("%s: incorrect frequency, got %.2f Hz, want %.2f Hz", test.name, frequency, 440.0)
+		}
+	}
+}
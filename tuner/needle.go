@@ -0,0 +1,127 @@
+package tuner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+type (
+	// Params configure a Tuner.
+	Params struct {
+		Mapper        music.NoteMapper // Maps frequencies to target notes, e.g. with a reference A4 or temperament.
+		Attack        float64          // Time constant of the needle smoothing in seconds, zero disables it.
+		Release       float64          // Time in seconds the needle holds the last note after the pitch is lost.
+		Hysteresis    float64          // Cents beyond the midpoint between notes a pitch must reach to change the note.
+		InTuneCents   float64          // Maximum deviation in cents of a needle that is in tune.
+		MinConfidence float64          // Minimum detection confidence of frames moving the needle.
+		HopSize       int              // Samples between successive frames of the stream.
+	}
+	// Needle is the stable reading of a Tuner for display.
+	Needle struct {
+		Note      music.Note // Target note; its Cents are the smoothed deviation.
+		Frequency float64    // Smoothed frequency in Hz.
+		InTune    bool       // Whether the smoothed deviation is within InTuneCents.
+		Active    bool       // Whether a note is shown, false before the first pitch and after the release.
+	}
+	// Tuner turns the frame-by-frame pitch of a stream into a stable tuner needle: the deviation is smoothed
+	// exponentially, the note only changes once the pitch is Hysteresis cents past the midpoint to the next note,
+	// and the last note is held for Release seconds when the pitch is lost, so displays don't flicker between
+	// adjacent notes or blank out between plucks. A Tuner is not safe for concurrent use.
+	Tuner struct {
+		stream *yinfft.StreamDetector
+		params Params
+		hop    float64 // Duration of a hop in seconds.
+		needle Needle
+		silent float64 // Time in seconds since the last voiced frame.
+	}
+)
+
+// DefaultParams are tuner params suited to a guitar tuner with a 2048 sample frame at 44.1 kHz.
+var DefaultParams = Params{
+	Attack:        0.1,
+	Release:       1,
+	Hysteresis:    15,
+	InTuneCents:   3,
+	MinConfidence: 0.8,
+	HopSize:       512,
+}
+
+// NewTuner creates a Tuner analyzing a stream with the detector.
+func NewTuner(detector *yinfft.PitchDetector, params Params) (*Tuner, error) {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"Attack", params.Attack}, {"Release", params.Release}, {"Hysteresis", params.Hysteresis},
+		{"InTuneCents", params.InTuneCents}, {"MinConfidence", params.MinConfidence},
+	}
+	for _, param := range values {
+		if !(param.value >= 0) || math.IsInf(param.value, 1) {
+			return nil, fmt.Errorf("invalid '%s': %g, must not be negative", param.name, param.value)
+		}
+	}
+	stream, err := detector.NewStreamDetector(params.HopSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Tuner{
+		stream: stream,
+		params: params,
+		hop:    float64(params.HopSize) / detector.Params().SampleRate,
+	}, nil
+}
+
+// Write appends samples to the stream, see yinfft.StreamDetector.Write.
+func (t *Tuner) Write(samples []float64) {
+	t.stream.Write(samples)
+}
+
+// Poll analyzes the next frame of the stream and returns the updated needle. Returns false if not enough samples are
+// buffered for a frame.
+func (t *Tuner) Poll() (Needle, bool, error) {
+	result, ok, err := t.stream.Poll()
+	if !ok || err != nil {
+		return t.needle, ok, err
+	}
+	return t.Update(result), true, nil
+}
+
+// Update moves the needle by the detection result of the next frame, one hop after the previous one, and returns it.
+// Poll calls it for the frames of the stream, it is exported to drive the needle from another source of results.
+func (t *Tuner) Update(result yinfft.Result) Needle {
+	if !result.Voiced || result.Confidence < t.params.MinConfidence {
+		t.silent += t.hop
+		if t.silent > t.params.Release {
+			t.needle = Needle{}
+		}
+		return t.needle
+	}
+	t.silent = 0
+
+	note := t.params.Mapper.Note(result.Frequency)
+	switch {
+	case !t.needle.Active:
+		t.needle = Needle{Note: note, Frequency: result.Frequency, Active: true}
+	case note.MIDI != t.needle.Note.MIDI && math.Abs(t.deviation(result.Frequency)) > 50+t.params.Hysteresis:
+		// A new note restarts the smoothing, so the needle doesn't sweep across from the previous one.
+		t.needle.Note, t.needle.Frequency = note, result.Frequency
+	default:
+		weight := 1.0
+		if t.params.Attack > 0 {
+			weight = -math.Expm1(-t.hop / t.params.Attack)
+		}
+		t.needle.Frequency *= math.Pow(result.Frequency/t.needle.Frequency, weight)
+	}
+
+	t.needle.Note.Cents = t.deviation(t.needle.Frequency)
+	t.needle.InTune = math.Abs(t.needle.Note.Cents) <= t.params.InTuneCents
+	return t.needle
+}
+
+// deviation returns the deviation of the frequency from the target of the needle's note in cents.
+func (t *Tuner) deviation(frequency float64) float64 {
+	return 1200 * math.Log2(frequency/t.params.Mapper.Frequency(t.needle.Note.MIDI))
+}
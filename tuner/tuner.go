@@ -0,0 +1,97 @@
+// Package tuner turns detected pitches into tuner readings: a stable needle for single notes, and a polyphonic tuner
+// measuring all strings of a strummed chord at once like modern polyphonic pedal tuners.
+package tuner
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+// DefaultMaxCents is the default maximum deviation of a detected fundamental from the target of its string.
+const DefaultMaxCents = 300.0
+
+type (
+	// Reading is the tuning state of one string.
+	Reading struct {
+		String    int     // Index of the string in the tuning.
+		Target    float64 // Target frequency of the string in Hz.
+		Frequency float64 // Detected fundamental in Hz, zero if the string wasn't detected.
+		Cents     float64 // Deviation of the fundamental from the target in cents, positive if sharp.
+		Detected  bool    // Whether a fundamental was matched to the string.
+	}
+	// PolyTuner matches the simultaneous fundamentals of a frame, see yinfft.PitchDetector.DetectMultiple, against
+	// the strings of a tuning. Each fundamental is assigned to the string of the nearest target within MaxCents,
+	// closest pairs first, so that every string and fundamental is matched at most once.
+	PolyTuner struct {
+		detector *yinfft.PitchDetector
+		targets  []float64
+		MaxCents float64 // Maximum deviation of a fundamental from the target of its string in cents.
+	}
+	tuningPair struct {
+		stringIndex, fundamental int
+		cents                    float64
+	}
+)
+
+// NewPolyTuner creates a PolyTuner for the tuning, using the detector for multi-pitch estimation and the note mapper
+// for the target frequencies, including its reference A4 and stretch curve.
+func NewPolyTuner(detector *yinfft.PitchDetector, tuning Tuning, mapper music.NoteMapper) (*PolyTuner, error) {
+	if len(tuning) == 0 {
+		return nil, errors.New("invalid tuning: no strings")
+	}
+	targets := make([]float64, len(tuning))
+	for i, midi := range tuning {
+		targets[i] = mapper.Frequency(midi)
+	}
+	return &PolyTuner{detector: detector, targets: targets, MaxCents: DefaultMaxCents}, nil
+}
+
+// Tune detects up to one fundamental per string in the frame and returns the readings of all strings in tuning
+// order. The frame is modified in place.
+func (t *PolyTuner) Tune(frame []float64) ([]Reading, error) {
+	results, err := t.detector.DetectMultiple(frame, len(t.targets))
+	if err != nil {
+		return nil, err
+	}
+	return t.Match(results), nil
+}
+
+// Match returns the readings of all strings in tuning order for the given fundamentals, e.g. from
+// DetectMultipleFromSpectrum. Unvoiced results are ignored.
+func (t *PolyTuner) Match(results []yinfft.Result) []Reading {
+	readings := make([]Reading, len(t.targets))
+	for i, target := range t.targets {
+		readings[i] = Reading{String: i, Target: target}
+	}
+
+	var pairs []tuningPair
+	for i, target := range t.targets {
+		for j, result := range results {
+			if !result.Voiced {
+				continue
+			}
+			if cents := 1200 * math.Log2(result.Frequency/target); math.Abs(cents) <= t.MaxCents {
+				pairs = append(pairs, tuningPair{stringIndex: i, fundamental: j, cents: cents})
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(a, b tuningPair) int {
+		return cmp.Compare(math.Abs(a.cents), math.Abs(b.cents))
+	})
+
+	matched := make([]bool, len(results))
+	for _, pair := range pairs {
+		reading := &readings[pair.stringIndex]
+		if reading.Detected || matched[pair.fundamental] {
+			continue
+		}
+		reading.Frequency, reading.Cents, reading.Detected = results[pair.fundamental].Frequency, pair.cents, true
+		matched[pair.fundamental] = true
+	}
+	return readings
+}
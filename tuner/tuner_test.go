@@ -0,0 +1,327 @@
+package tuner_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/music"
+	"github.com/FreibergVlad/go-yinfft/tuner"
+)
+
+func TestPolyTuner(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	params := detector.Params()
+
+	// A strummed guitar in standard tuning, with the low E string 17 cents sharp and the B string 17 cents flat.
+	deviations := []float64{17, 0, 0, 0, -17, 0}
+	mapper := music.NoteMapper{}
+	frame := make([]float64, params.FrameSize)
+	for index, midi := range tuner.StandardTuning {
+		f0 := mapper.Frequency(midi) * math.Exp2(deviations[index]/1200)
+		for harmonic := 1; harmonic <= 8; harmonic++ {
+			for i := range frame {
+				frame[i] += math.Sin(2*math.Pi*f0*float64(harmonic)*float64(i)/params.SampleRate) / float64(harmonic)
+			}
+		}
+	}
+
+	polyTuner, err := tuner.NewPolyTuner(detector, tuner.StandardTuning, mapper)
+	if err != nil {
+		t.Fatalf("error creating tuner: %v", err)
+	}
+	readings, err := polyTuner.Tune(frame)
+	if err != nil {
+		t.Fatalf("error tuning: %v", err)
+	}
+
+	if len(readings) != len(tuner.StandardTuning) {
+		t.Fatalf("incorrect number of readings, got %d, want %d", len(readings), len(tuner.StandardTuning))
+	}
+	for i, reading := range readings {
+		if !reading.Detected {
+			t.Errorf("string %d wasn't detected", i)
+			continue
+		}
+		if math.Abs(reading.Cents-deviations[i]) > 10 {
+			t.Errorf("incorrect deviation of string %d, got %.1f cents, want %.1f cents", i, reading.Cents, deviations[i])
+		}
+	}
+}
+
+func TestPolyTuner_Match(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	polyTuner, err := tuner.NewPolyTuner(detector, tuner.BassTuning, music.NoteMapper{})
+	if err != nil {
+		t.Fatalf("error creating tuner: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		results      []yinfft.Result
+		wantDetected []bool
+	}{
+		{"no fundamentals", nil, []bool{false, false, false, false}},
+		{"a string only", []yinfft.Result{{Frequency: 55.3, Voiced: true}}, []bool{false, true, false, false}},
+		{"unvoiced", []yinfft.Result{{Frequency: 55.3}}, []bool{false, false, false, false}},
+		{"far off", []yinfft.Result{{Frequency: 500, Voiced: true}}, []bool{false, false, false, false}},
+		{"closest pair first", []yinfft.Result{
+			{Frequency: 42, Voiced: true}, {Frequency: 41.2, Voiced: true},
+		}, []bool{true, false, false, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			readings := polyTuner.Match(test.results)
+			for i, reading := range readings {
+				if reading.Detected != test.wantDetected[i] {
+					t.Errorf("incorrect detection of string %d, got %t, want %t", i, reading.Detected, test.wantDetected[i])
+				}
+			}
+			if test.name == "closest pair first" && readings[0].Frequency != 41.2 {
+				t.Errorf("incorrect match of the E string, got %.2f Hz, want 41.20 Hz", readings[0].Frequency)
+			}
+		})
+	}
+}
+
+func TestNewPolyTuner_EmptyTuning(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	if _, err := tuner.NewPolyTuner(detector, tuner.Tuning{}, music.NoteMapper{}); err == nil {
+		t.Error("expected an error for a tuning without strings")
+	}
+}
+
+func TestTuner_Update(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	sampleRate := detector.Params().SampleRate
+	voiced := func(cents float64) yinfft.Result {
+		return yinfft.Result{Frequency: 440 * math.Exp2(cents/1200), Confidence: 1, Voiced: true}
+	}
+
+	type step struct {
+		result     yinfft.Result
+		wantActive bool
+		wantNote   string
+		wantMin    float64 // Bounds of the needle deviation in cents.
+		wantMax    float64
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"first pitch is shown immediately", []step{
+			{voiced(10), true, "A4", 10, 10},
+		}},
+		{"deviation is smoothed", []step{
+			{voiced(0), true, "A4", 0, 0},
+			{voiced(20), true, "A4", 1, 19},
+			{voiced(20), true, "A4", 1, 19},
+		}},
+		{"no flicker at the midpoint between notes", []step{
+			{voiced(45), true, "A4", 45, 45},
+			{voiced(55), true, "A4", 45, 55},
+			{voiced(49), true, "A4", 45, 55},
+			{voiced(60), true, "A4", 45, 60},
+		}},
+		{"note changes past the hysteresis", []step{
+			{voiced(0), true, "A4", 0, 0},
+			{voiced(200), true, "B4", 0, 0},
+		}},
+		{"note is held during the release", []step{
+			{voiced(0), true, "A4", 0, 0},
+			{yinfft.Result{}, true, "A4", 0, 0},
+			{voiced(0), true, "A4", 0, 0},
+		}},
+		{"low confidence doesn't move the needle", []step{
+			{voiced(0), true, "A4", 0, 0},
+			{yinfft.Result{Frequency: 470, Confidence: 0.2, Voiced: true}, true, "A4", 0, 0},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := tuner.DefaultParams
+			params.HopSize, params.Attack, params.Release = 2048, 0.2, 4096/sampleRate
+			noteTuner, err := tuner.NewTuner(detector, params)
+			if err != nil {
+				t.Fatalf("error creating tuner: %v", err)
+			}
+			for i, step := range test.steps {
+				needle := noteTuner.Update(step.result)
+				if needle.Active != step.wantActive || needle.Active && needle.Note.String() != step.wantNote {
+					t.Fatalf("step %d: incorrect needle, got %+v, want note %s", i, needle, step.wantNote)
+				}
+				if needle.Note.Cents < step.wantMin-1e-6 || needle.Note.Cents > step.wantMax+1e-6 {
+					t.Errorf(
+						"step %d: incorrect deviation, got %.2f cents, want within [%g, %g]", i, needle.Note.Cents,
+						step.wantMin, step.wantMax,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestTuner_Release(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	params := tuner.DefaultParams
+	params.Release = 2.5 * float64(params.HopSize) / detector.Params().SampleRate
+	noteTuner, err := tuner.NewTuner(detector, params)
+	if err != nil {
+		t.Fatalf("error creating tuner: %v", err)
+	}
+
+	noteTuner.Update(yinfft.Result{Frequency: 440, Confidence: 1, Voiced: true})
+	for i, wantActive := range []bool{true, true, false} {
+		if needle := noteTuner.Update(yinfft.Result{}); needle.Active != wantActive {
+			t.Errorf("unvoiced frame %d: incorrect activity, got %t, want %t", i, needle.Active, wantActive)
+		}
+	}
+}
+
+func TestTuner_Poll(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+	detector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	noteTuner, err := tuner.NewTuner(detector, tuner.DefaultParams)
+	if err != nil {
+		t.Fatalf("error creating tuner: %v", err)
+	}
+
+	signal := make([]float64, 20*tuner.DefaultParams.HopSize)
+	for i := range signal {
+		signal[i] = math.Sin(2 * math.Pi * 441 * float64(i) / params.SampleRate)
+	}
+	noteTuner.Write(signal)
+
+	var needle tuner.Needle
+	for {
+		next, ok, err := noteTuner.Poll()
+		if err != nil {
+			t.Fatalf("error polling tuner: %v", err)
+		}
+		if !ok {
+			break
+		}
+		needle = next
+	}
+	if !needle.Active || needle.Note.String() != "A4" || math.Abs(needle.Note.Cents-3.93) > 2 {
+		t.Errorf("incorrect needle, got %+v, want A4 at +3.93 cents", needle)
+	}
+}
+
+func TestNewTuner_Validation(t *testing.T) {
+	t.Parallel()
+
+	detector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*tuner.Params)
+	}{
+		{"negative attack", func(p *tuner.Params) { p.Attack = -1 }},
+		{"NaN hysteresis", func(p *tuner.Params) { p.Hysteresis = math.NaN() }},
+		{"zero hop size", func(p *tuner.Params) { p.HopSize = 0 }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := tuner.DefaultParams
+			test.modify(&params)
+			if _, err := tuner.NewTuner(detector, params); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestTuning_Nearest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		tuning     tuner.Tuning
+		mapper     music.NoteMapper
+		frequency  float64
+		wantString int
+		wantCents  float64
+	}{
+		{"low E", tuner.StandardTuning, music.NoteMapper{}, 82.41, 0, 0},
+		{"flat A", tuner.StandardTuning, music.NoteMapper{}, 108.74, 1, -20},
+		{"far below the lowest string", tuner.StandardTuning, music.NoteMapper{}, 41.2, 0, -1200},
+		{"drop D", tuner.DropDTuning, music.NoteMapper{}, 73.42, 0, 0},
+		{"DADGAD second string", tuner.DADGADTuning, music.NoteMapper{}, 220, 4, 0},
+		{"re-entrant G", tuner.UkuleleTuning, music.NoteMapper{}, 392, 0, 0},
+		{"low B of a five-string bass", tuner.FiveStringBassTuning, music.NoteMapper{}, 30.87, 0, 0},
+		{"orchestral pitch", tuner.StandardTuning, music.NoteMapper{A4: music.OrchestralA4}, 331.13, 5, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			match := test.tuning.Nearest(test.frequency, test.mapper)
+			if match.String != test.wantString || math.Abs(match.Cents-test.wantCents) > 0.5 {
+				t.Errorf(
+					"incorrect match, got string %d at %+.1f cents, want string %d at %+.1f cents", match.String,
+					match.Cents, test.wantString, test.wantCents,
+				)
+			}
+		})
+	}
+}
+
+func TestTunings(t *testing.T) {
+	t.Parallel()
+
+	for name, tuning := range tuner.Tunings {
+		if len(tuning) < 4 {
+			t.Errorf("tuning %s has %d strings, want at least 4", name, len(tuning))
+		}
+		for _, midi := range tuning {
+			if midi < 21 || midi > 108 {
+				t.Errorf("tuning %s has MIDI note %d outside the piano range", name, midi)
+			}
+		}
+	}
+}
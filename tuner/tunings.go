@@ -0,0 +1,60 @@
+package tuner
+
+import (
+	"math"
+
+	"github.com/FreibergVlad/go-yinfft/music"
+)
+
+type (
+	// Tuning is the target MIDI notes of the open strings of an instrument, in the order strings are usually named:
+	// from the lowest string, except for the first string of re-entrant tunings such as the ukulele's.
+	Tuning []int
+	// StringMatch is the string of a tuning nearest to a frequency, see Tuning.Nearest.
+	StringMatch struct {
+		String int     // Index of the string in the tuning.
+		Target float64 // Target frequency of the string in Hz.
+		Cents  float64 // Deviation of the frequency from the target in cents, positive if sharp.
+	}
+)
+
+var (
+	StandardTuning        = Tuning{40, 45, 50, 55, 59, 64}     // E2 A2 D3 G3 B3 E4 of a six-string guitar.
+	DropDTuning           = Tuning{38, 45, 50, 55, 59, 64}     // D2 A2 D3 G3 B3 E4 of a six-string guitar.
+	DADGADTuning          = Tuning{38, 45, 50, 55, 57, 62}     // D2 A2 D3 G3 A3 D4 of a six-string guitar.
+	OpenGTuning           = Tuning{38, 43, 50, 55, 59, 62}     // D2 G2 D3 G3 B3 D4 of a six-string guitar.
+	HalfStepDownTuning    = Tuning{39, 44, 49, 54, 58, 63}     // Eb2 Ab2 Db3 Gb3 Bb3 Eb4 of a six-string guitar.
+	SevenStringTuning     = Tuning{35, 40, 45, 50, 55, 59, 64} // B1 E2 A2 D3 G3 B3 E4 of a seven-string guitar.
+	BassTuning            = Tuning{28, 33, 38, 43}             // E1 A1 D2 G2 of a four-string bass.
+	FiveStringBassTuning  = Tuning{23, 28, 33, 38, 43}         // B0 E1 A1 D2 G2 of a five-string bass.
+	UkuleleTuning         = Tuning{67, 60, 64, 69}             // Re-entrant G4 C4 E4 A4 of a soprano or concert ukulele.
+	BaritoneUkuleleTuning = Tuning{50, 55, 59, 64}             // D3 G3 B3 E4 of a baritone ukulele.
+)
+
+// Tunings are the predefined tunings by name, e.g. for selecting an instrument mode by configuration.
+var Tunings = map[string]Tuning{
+	"guitar":                StandardTuning,
+	"guitar-drop-d":         DropDTuning,
+	"guitar-dadgad":         DADGADTuning,
+	"guitar-open-g":         OpenGTuning,
+	"guitar-half-step-down": HalfStepDownTuning,
+	"guitar-7-string":       SevenStringTuning,
+	"bass":                  BassTuning,
+	"bass-5-string":         FiveStringBassTuning,
+	"ukulele":               UkuleleTuning,
+	"ukulele-baritone":      BaritoneUkuleleTuning,
+}
+
+// Nearest returns the string whose target, as given by the note mapper, is nearest to the frequency in cents, for
+// tuner modes showing the string being tuned rather than the nearest chromatic note. The tuning must not be empty.
+func (t Tuning) Nearest(frequency float64, mapper music.NoteMapper) StringMatch {
+	var nearest StringMatch
+	for i, midi := range t {
+		target := mapper.Frequency(midi)
+		cents := 1200 * math.Log2(frequency/target)
+		if i == 0 || math.Abs(cents) < math.Abs(nearest.Cents) {
+			nearest = StringMatch{String: i, Target: target, Cents: cents}
+		}
+	}
+	return nearest
+}
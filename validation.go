@@ -0,0 +1,173 @@
+package yinfft
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// minNormal is the smallest positive normal float64, smaller non-zero magnitudes are denormal.
+const minNormal = 0x1p-1022
+
+// SanitizeMode defines how non-finite samples are handled before detection.
+type SanitizeMode string
+
+const (
+	SanitizeNone  SanitizeMode = ""      // Non-finite samples are left as is.
+	SanitizeZero  SanitizeMode = "zero"  // NaN and infinite samples are replaced with zero.
+	SanitizeClamp SanitizeMode = "clamp" // Infinite samples are clamped to full scale (+-1), NaN samples are zeroed.
+)
+
+// NoPitchMode defines what detection returns for frames without a detectable pitch, e.g. silence or noise.
+type NoPitchMode string
+
+const (
+	NoPitchZero  NoPitchMode = ""      // Zero frequency and confidence are returned.
+	NoPitchNaN   NoPitchMode = "nan"   // NaN frequency and zero confidence are returned.
+	NoPitchError NoPitchMode = "error" // ErrNoPitch is returned.
+)
+
+// Sentinel errors of the public API, to be matched with errors.Is.
+var (
+	ErrInvalidFrameSize     = errors.New("invalid frame size")      // A frame doesn't match FrameSize.
+	ErrInvalidSpectrumSize  = errors.New("invalid spectrum size")   // A spectrum doesn't have FFTSize()/2+1 bins.
+	ErrInvalidWeightingType = errors.New("invalid 'weightingType'") // Params name an unknown weighting curve.
+	ErrNoPitch              = errors.New("no pitch detected")       // No pitch found and OnNoPitch is NoPitchError.
+)
+
+type (
+	// FrameSizeError is returned for a frame whose length doesn't match the configured FrameSize.
+	FrameSizeError struct {
+		Want int // Configured frame size.
+		Got  int // Actual frame length.
+	}
+	// NaNSampleError is returned for a frame containing a NaN sample.
+	NaNSampleError struct {
+		Index int // Index of the first NaN sample.
+	}
+	// InfSampleError is returned for a frame containing an infinite sample.
+	InfSampleError struct {
+		Index int     // Index of the first infinite sample.
+		Value float64 // The infinite sample, +Inf or -Inf.
+	}
+	// DenormalSampleError is returned for a frame containing a denormal sample.
+	DenormalSampleError struct {
+		Index int     // Index of the first denormal sample.
+		Value float64 // The denormal sample.
+	}
+)
+
+func (e *FrameSizeError) Error() string {
+	return fmt.Sprintf("%v: expected %d, got %d", ErrInvalidFrameSize, e.Want, e.Got)
+}
+
+// Is reports whether the target is ErrInvalidFrameSize.
+func (e *FrameSizeError) Is(target error) bool {
+	return target == ErrInvalidFrameSize
+}
+
+func (e *NaNSampleError) Error() string {
+	return fmt.Sprintf("invalid sample at index %d: NaN", e.Index)
+}
+
+func (e *InfSampleError) Error() string {
+	return fmt.Sprintf("invalid sample at index %d: %v", e.Index, e.Value)
+}
+
+func (e *DenormalSampleError) Error() string {
+	return fmt.Sprintf("invalid sample at index %d: denormal value %g", e.Index, e.Value)
+}
+
+// ValidateFrame checks that the frame matches the configured FrameSize and contains only finite, normal or zero
+// samples. Denormal samples are accepted if DenormalThreshold flushes them, i.e. is at least the smallest normal
+// value, as decaying tails are then analyzed as silence. It returns a *FrameSizeError, *NaNSampleError,
+// *InfSampleError or *DenormalSampleError describing the first problem found.
+func (pd *PitchDetector) ValidateFrame(frame []float64) error {
+	if len(frame) != pd.params.FrameSize {
+		return &FrameSizeError{Want: pd.params.FrameSize, Got: len(frame)}
+	}
+	return pd.validateSamples(frame)
+}
+
+// validateSamples checks that the samples are finite, normal or zero, see ValidateFrame.
+func (pd *PitchDetector) validateSamples(samples []float64) error {
+	flushed := pd.params.DenormalThreshold >= minNormal
+	for i, sample := range samples {
+		switch {
+		case math.IsNaN(sample):
+			return &NaNSampleError{Index: i}
+		case math.IsInf(sample, 0):
+			return &InfSampleError{Index: i, Value: sample}
+		case !flushed && sample != 0 && math.Abs(sample) < minNormal:
+			return &DenormalSampleError{Index: i, Value: sample}
+		}
+	}
+	return nil
+}
+
+// checkFrame checks the length of the frame before detection, then sanitizes it and validates its samples with
+// ValidateFrame if ValidateFrames is set. A frame of the wrong length is rejected without being modified.
+func (pd *PitchDetector) checkFrame(frame []float64) error {
+	if len(frame) != pd.params.FrameSize {
+		return &FrameSizeError{Want: pd.params.FrameSize, Got: len(frame)}
+	}
+	return pd.checkSamples(frame)
+}
+
+// checkSpectrum checks that the magnitude spectrum has the FFTSize()/2+1 bins detection expects.
+func (pd *PitchDetector) checkSpectrum(spectrum []float64) error {
+	if bins := pd.params.FFTSize()/2 + 1; len(spectrum) != bins {
+		return fmt.Errorf("%w: expected %d, got %d", ErrInvalidSpectrumSize, bins, len(spectrum))
+	}
+	return nil
+}
+
+// checkSamples sanitizes the samples in place and validates them if ValidateFrames is set, like checkFrame does for
+// a frame, e.g. for a hop of an OverlapAnalyzer.
+func (pd *PitchDetector) checkSamples(samples []float64) error {
+	pd.sanitizeFrame(samples)
+	if pd.params.ValidateFrames {
+		return pd.validateSamples(samples)
+	}
+	return nil
+}
+
+// sanitizeFrame replaces non-finite samples in place according to the configured SanitizeMode and returns the
+// number of replaced samples.
+func (pd *PitchDetector) sanitizeFrame(frame []float64) int {
+	if pd.params.SanitizeMode == SanitizeNone {
+		return 0
+	}
+
+	replaced := 0
+	for i, sample := range frame {
+		switch {
+		case math.IsNaN(sample):
+			frame[i] = 0
+		case math.IsInf(sample, 0) && pd.params.SanitizeMode == SanitizeClamp:
+			frame[i] = math.Copysign(1, sample)
+		case math.IsInf(sample, 0):
+			frame[i] = 0
+		default:
+			continue
+		}
+		replaced++
+	}
+
+	if replaced > 0 && pd.params.Logger != nil {
+		pd.params.Logger.Debug("sanitized non-finite samples", "count", replaced, "mode", pd.params.SanitizeMode)
+	}
+	return replaced
+}
+
+// noPitch returns the detection result for a frame without a detectable pitch according to OnNoPitch.
+func (pd *PitchDetector) noPitch() (frequency float64, confidence float64, err error) {
+	switch pd.params.OnNoPitch {
+	case NoPitchNaN:
+		return math.NaN(), 0, nil
+	case NoPitchError:
+		return 0, 0, ErrNoPitch
+	default:
+		return 0, 0, nil
+	}
+}
@@ -0,0 +1,64 @@
+package yinfft
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WeightingType names the weighting curve applied to the spectrum before detection. Names are case-insensitive.
+type WeightingType string
+
+const (
+	WeightingA      WeightingType = "A"      // A-weighting.
+	WeightingB      WeightingType = "B"      // B-weighting.
+	WeightingC      WeightingType = "C"      // C-weighting.
+	WeightingD      WeightingType = "D"      // D-weighting.
+	WeightingCustom WeightingType = "CUSTOM" // The curve of aubio's yinfft, emphasizing the range of most instruments.
+	WeightingEmpty  WeightingType = "EMPTY"  // No weighting.
+)
+
+// normalized returns the weighting type in the upper case of the WeightingType constants.
+func (w WeightingType) normalized() WeightingType {
+	return WeightingType(strings.ToUpper(string(w)))
+}
+
+// SpectrumWeights returns a copy of the per-bin weights applied to the squared magnitude spectrum, derived from the
+// configured weighting curve at the analysis sample rate and FFT size.
+func (pd *PitchDetector) SpectrumWeights() []float64 {
+	return slices.Clone(pd.weights)
+}
+
+// WriteSpectrumWeightsCSV writes the effective per-bin weighting as CSV with a header and one row per bin: bin index,
+// bin frequency in Hz, linear weight and weight in dB. The dB field is empty for bins with a zero weight, e.g. those
+// notched out with BinWeights.
+func (pd *PitchDetector) WriteSpectrumWeightsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"bin", "frequency_hz", "weight", "weight_db"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	for i, weight := range pd.weights {
+		weightDB := ""
+		if weight > 0 {
+			weightDB = strconv.FormatFloat(20*math.Log10(weight), 'f', 4, 64)
+		}
+		record := []string{
+			strconv.Itoa(i),
+			strconv.FormatFloat(float64(i)*binFrequency, 'f', 4, 64),
+			strconv.FormatFloat(weight, 'g', -1, 64),
+			weightDB,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row %d: %w", i, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,78 @@
+package yinfft
+
+import (
+	"fmt"
+
+	"github.com/FreibergVlad/go-yinfft/internal"
+)
+
+// DefaultKaiserBeta is the shape parameter of the Kaiser window if not configured, with sidelobes similar to the
+// Blackman window.
+const DefaultKaiserBeta = 8.6
+
+// WindowType names the analysis window applied to frames before the FFT.
+type WindowType string
+
+const (
+	WindowHann           WindowType = "hann"            // Hann window, the default.
+	WindowHamming        WindowType = "hamming"         // Hamming window.
+	WindowBlackman       WindowType = "blackman"        // Blackman window.
+	WindowBlackmanHarris WindowType = "blackman-harris" // Four-term Blackman-Harris window.
+	WindowKaiser         WindowType = "kaiser"          // Kaiser window with the shape parameter KaiserBeta.
+	WindowFlatTop        WindowType = "flat-top"        // Flat-top window.
+)
+
+// WindowFunc returns the coefficient of sample i of an analysis window of n samples.
+type WindowFunc func(i, n int) float64
+
+// windowFunction returns the configured analysis window, WindowFunc if set.
+func (p Params) windowFunction() (WindowFunc, error) {
+	if p.WindowFunc != nil {
+		return p.WindowFunc, nil
+	}
+	switch p.Window {
+	case "":
+		p.Window = WindowHann
+	case WindowKaiser:
+		beta := p.KaiserBeta
+		if beta == 0 {
+			beta = DefaultKaiserBeta
+		}
+		return internal.KaiserWindow(beta), nil
+	}
+	function, ok := internal.NamedWindow(string(p.Window))
+	if !ok {
+		return nil, fmt.Errorf(
+			"invalid 'window': %s, must be one of [%s, %s, %s, %s, %s, %s]", p.Window, WindowHann, WindowHamming,
+			WindowBlackman, WindowBlackmanHarris, WindowKaiser, WindowFlatTop,
+		)
+	}
+	return function, nil
+}
+
+// window returns the coefficients of the configured analysis window of the given size. Named windows, including the
+// Kaiser window with DefaultKaiserBeta, are shared through the window cache and must not be modified; WindowFunc and
+// Kaiser windows with other shape parameters are computed for the caller.
+func (p Params) window(size int) ([]float64, error) {
+	function, err := p.windowFunction()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.WindowFunc != nil:
+		return internal.WindowCoefficients(function, size), nil
+	case p.Window == WindowKaiser && (p.KaiserBeta == 0 || p.KaiserBeta == DefaultKaiserBeta):
+		return internal.CachedKaiserWindow(DefaultKaiserBeta, size), nil
+	case p.Window == WindowKaiser:
+		return internal.WindowCoefficients(function, size), nil
+	case p.Window == "":
+		return internal.Window(string(WindowHann), size)
+	default:
+		return internal.Window(string(p.Window), size)
+	}
+}
+
+// isHannWindow reports whether the params select the default Hann window.
+func (p Params) isHannWindow() bool {
+	return p.WindowFunc == nil && (p.Window == "" || p.Window == WindowHann)
+}
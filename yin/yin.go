@@ -0,0 +1,144 @@
+// Package yin implements the original time-domain YIN pitch detection algorithm by de Cheveigné and Kawahara, for
+// comparison with the FFT-based variant of the parent package, e.g. on short frames.
+package yin
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+type (
+	// Params defines configuration options for the YIN pitch detector.
+	Params struct {
+		FrameSize         int     // Length of the input audio frame in samples, the integration window is half of it.
+		SampleRate        float64 // Audio sampling rate in Hz.
+		Threshold         float64 // Absolute threshold of the first dip of the normalized difference function.
+		MinFrequency      float64 // Minimum detectable frequency in Hz.
+		MaxFrequency      float64 // Maximum detectable frequency in Hz.
+		ShouldInterpolate bool    // Whether to refine the period with parabolic interpolation.
+	}
+	// PitchDetector detects pitch with the time-domain YIN algorithm. It is safe for concurrent use.
+	PitchDetector struct {
+		params           Params
+		minPeriodSamples int
+		maxPeriodSamples int
+		scratchPool      sync.Pool
+	}
+)
+
+// DefaultParams are the thresholds recommended by the YIN paper for a 2048 samples frame.
+var DefaultParams = Params{
+	FrameSize:         2048,
+	SampleRate:        44100,
+	Threshold:         0.1,
+	MinFrequency:      50,
+	MaxFrequency:      4000,
+	ShouldInterpolate: true,
+}
+
+// New creates a new PitchDetector instance using the provided Params.
+func New(params Params) (*PitchDetector, error) {
+	if params.FrameSize < 4 {
+		return nil, fmt.Errorf("invalid 'frameSize': %d, must be at least 4", params.FrameSize)
+	}
+	if params.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid 'sampleRate': %g, must be positive", params.SampleRate)
+	}
+	if params.Threshold <= 0 || params.Threshold >= 1 {
+		return nil, fmt.Errorf("invalid 'threshold': %g, must be in range (0, 1)", params.Threshold)
+	}
+	if params.MinFrequency <= 0 || params.MaxFrequency <= params.MinFrequency {
+		return nil, fmt.Errorf("invalid frequency range: [%g, %g] Hz", params.MinFrequency, params.MaxFrequency)
+	}
+
+	maxLag := params.FrameSize/2 - 1
+	maxPeriodSamples := min(int(math.Ceil(params.SampleRate/params.MinFrequency)), maxLag)
+	minPeriodSamples := max(int(math.Floor(params.SampleRate/params.MaxFrequency)), 2)
+	if maxPeriodSamples <= minPeriodSamples {
+		return nil, fmt.Errorf(
+			"maxFrequency <= minFrequency or out of range; min detectable = %.2f Hz", params.SampleRate/float64(maxLag),
+		)
+	}
+
+	pd := &PitchDetector{params: params, minPeriodSamples: minPeriodSamples, maxPeriodSamples: maxPeriodSamples}
+	pd.scratchPool.New = func() any {
+		buffer := make([]float64, maxPeriodSamples+2)
+		return &buffer
+	}
+	return pd, nil
+}
+
+// NewWithDefaultParams creates a new PitchDetector instance using the DefaultParams.
+func NewWithDefaultParams() (*PitchDetector, error) {
+	return New(DefaultParams)
+}
+
+// Params returns the params the detector was created with.
+func (pd *PitchDetector) Params() Params {
+	return pd.params
+}
+
+// DetectFromFrame detects the fundamental frequency of the frame, which must match the configured FrameSize and is not
+// modified. Returns zero frequency and confidence for silent frames.
+func (pd *PitchDetector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	if len(frame) != pd.params.FrameSize {
+		return 0, 0, fmt.Errorf("invalid frame size: expected %d, got %d", pd.params.FrameSize, len(frame))
+	}
+
+	buffer := pd.scratchPool.Get().(*[]float64)
+	defer pd.scratchPool.Put(buffer)
+	yin := *buffer
+
+	// Difference function over an integration window of half the frame, normalized by its cumulative mean.
+	window := pd.params.FrameSize / 2
+	yin[0] = 1
+	cumulative := 0.0
+	for tau := 1; tau < len(yin); tau++ {
+		difference := 0.0
+		for j := range window {
+			delta := frame[j] - frame[j+tau]
+			difference += delta * delta
+		}
+		cumulative += difference
+		if cumulative == 0 {
+			yin[tau] = 1
+		} else {
+			yin[tau] = difference * float64(tau) / cumulative
+		}
+	}
+	if cumulative == 0 {
+		return 0, 0, nil
+	}
+
+	// The first dip below the absolute threshold, or the global minimum if there is none.
+	tau := -1
+	for i := pd.minPeriodSamples; i <= pd.maxPeriodSamples; i++ {
+		if yin[i] < pd.params.Threshold {
+			for i+1 <= pd.maxPeriodSamples && yin[i+1] < yin[i] {
+				i++
+			}
+			tau = i
+			break
+		}
+	}
+	if tau < 0 {
+		tau = pd.minPeriodSamples
+		for i := pd.minPeriodSamples; i <= pd.maxPeriodSamples; i++ {
+			if yin[i] < yin[tau] {
+				tau = i
+			}
+		}
+	}
+
+	period, yinMin := float64(tau), yin[tau]
+	if pd.params.ShouldInterpolate {
+		if curvature := yin[tau-1] - 2*yin[tau] + yin[tau+1]; curvature > 0 {
+			offset := 0.5 * (yin[tau-1] - yin[tau+1]) / curvature
+			period += offset
+			yinMin -= 0.25 * (yin[tau-1] - yin[tau+1]) * offset
+		}
+	}
+
+	return pd.params.SampleRate / period, max(0, 1-yinMin), nil
+}
@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"maps"
 	"math"
+	"runtime"
 	"slices"
-	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/FreibergVlad/go-yinfft/internal"
-	"github.com/FreibergVlad/go-yinfft/internal/peakdetector"
-	"github.com/mjibson/go-dsp/fft"
+	"github.com/FreibergVlad/go-yinfft/peaks"
 )
 
 type logger interface {
@@ -21,27 +22,81 @@ type logger interface {
 type (
 	// Params defines configuration options for the YinFFT pitch detector.
 	Params struct {
-		FrameSize         int     // Length of the input audio frame in samples.
-		SampleRate        float64 // Audio sampling rate in Hz.
-		ShouldInterpolate bool    // Whether to apply interpolation to the detected frequency.
-		Tolerance         float64 // Peak detection tolerance.
-		WeightingType     string  // Type of weighting curve to apply (e.g., "A", "B", "C", "D", "CUSTOM").
-		MinFrequency      float64 // Minimum detectable frequency in Hz.
-		MaxFrequency      float64 // Maximum detectable frequency in Hz.
-		Logger            logger  // Optional logger for debug messages.
-	}
-	// PitchDetector is the main structure for detecting pitch using the YinFFT algorithm.
+		FrameSize          int           `json:"frameSize"`            // Length of the input audio frame in samples, powers of two are fastest.
+		SampleRate         float64       `json:"sampleRate"`           // Audio sampling rate in Hz.
+		ShouldInterpolate  bool          `json:"shouldInterpolate"`    // Whether to apply interpolation to the detected frequency.
+		Tolerance          float64       `json:"tolerance"`            // Peak detection tolerance.
+		WeightingType      WeightingType `json:"weightingType"`        // Type of weighting curve to apply, e.g. WeightingA or WeightingCustom.
+		MinFrequency       float64       `json:"minFrequency"`         // Minimum detectable frequency in Hz.
+		MaxFrequency       float64       `json:"maxFrequency"`         // Maximum detectable frequency in Hz.
+		Logger             logger        `json:"-"`                    // Optional logger for debug messages.
+		MissingFundamental bool          `json:"missingFundamental"`   // Whether to accept a weak or absent fundamental supported by its harmonics 2-5.
+		ValidateFrames     bool          `json:"validateFrames"`       // Whether DetectFromFrame rejects frames with invalid samples, see ValidateFrame.
+		SanitizeMode       SanitizeMode  `json:"sanitizeMode"`         // How DetectFromFrame replaces non-finite samples before validation.
+		DenormalThreshold  float64       `json:"denormalThreshold"`    // Magnitude below which samples and bins are flushed to zero, zero disables it.
+		BinWeights         []float64     `json:"binWeights,omitempty"` // Optional non-negative per-bin multipliers merged with the curve, FFTSize()/2+1 entries.
+		WeightFunc         WeightFunc    `json:"-"`                    // Optional per-bin multiplier by bin frequency, merged with the curve.
+		HopSize            int           `json:"hopSize"`              // Frame advance of DetectAll in samples, FrameSize is used if zero.
+		OctaveCorrection   bool          `json:"octaveCorrection"`     // Whether to prefer half the detected period if it's a deep minimum too.
+		OnNoPitch          NoPitchMode   `json:"onNoPitch"`            // What detection returns for frames without a detectable pitch.
+		ZeroPadFactor      int           `json:"zeroPadFactor"`        // FFT size as a power of two multiple of FrameSize, zero or one disables zero-padding.
+		Decimation         int           `json:"decimation"`           // Factor frames are low-pass filtered and downsampled by before analysis, zero or one disables it.
+		Window             WindowType    `json:"window"`               // Analysis window applied before the FFT, WindowHann if empty.
+		KaiserBeta         float64       `json:"kaiserBeta"`           // Shape parameter of WindowKaiser, DefaultKaiserBeta if zero.
+		WindowFunc         WindowFunc    `json:"-"`                    // Optional custom analysis window, overriding Window.
+		HPSCheck           HPSCheck      `json:"hpsCheck"`             // How the harmonic product spectrum cross-checks the octave of pitches.
+		SilenceThresholdDB float64       `json:"silenceThresholdDB"`   // RMS level in dBFS below which frames are unvoiced without analysis, zero disables it.
+		ReferenceA4        float64       `json:"referenceA4"`          // Reference frequency of A4 in Hz for Chroma, 440 Hz if zero.
+	}
+	// WeightFunc returns a finite, non-negative weighting multiplier for a spectrum bin of the given frequency in Hz.
+	WeightFunc func(frequency float64) float64
+	// Detector is the pitch detection interface implemented by PitchDetector. Downstream code can depend on it to
+	// allow wrappers, decorators and test doubles.
+	Detector interface {
+		DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error)
+		DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error)
+	}
+	// Result is the outcome of detection on a single frame.
+	Result struct {
+		Frequency  float64 // Detected frequency in Hz, zero if no pitch was detected.
+		Confidence float64 // Detection confidence.
+		Tau        float64 // Detected period in samples, zero if no pitch was detected.
+		Voiced     bool    // Whether a pitch was detected.
+		Frame      int     // Index of the frame in the stream or signal, zero for single frames.
+		Time       float64 // Start of the frame in seconds, relative to the start of the stream or signal.
+		Level      float64 // RMS level of the frame in dBFS, negative infinity for digital silence, zero if unknown.
+	}
+	// PitchDetector is the main structure for detecting pitch using the YinFFT algorithm. A PitchDetector is safe for
+	// concurrent use by multiple goroutines: detection only reads its configuration and takes temporary buffers from
+	// a per-detector pool. SetFrequencyRange is the only method that must not run concurrently with others, use Clone
+	// for detectors reconfigured independently.
 	PitchDetector struct {
 		params           Params
 		weights          []float64
 		minPeriodSamples int
 		maxPeriodSamples int
-		peakDetector     *peakdetector.PeakDetector
+		peakDetector     *peaks.Detector
+		decimator        *internal.Decimator
+		window           []float64
+		scratchPool      sync.Pool
 	}
 )
 
+const (
+	missingFundamentalMaxDivisor   = 3    // Largest subharmonic divisor tried for the missing fundamental.
+	missingFundamentalMinSupport   = 0.75 // Minimum fraction of harmonics 2-5 that must be present.
+	missingFundamentalMinMagnitude = 0.05 // Minimum harmonic magnitude relative to the spectrum maximum.
+	missingFundamentalMaxYinDelta  = 0.1  // Maximum allowed increase of the yin minimum for the subharmonic.
+
+	octaveCorrectionMaxYin = 0.1 // Maximum yin value at half the period for the octave correction, as in aubio.
+)
+
+var _ Detector = (*PitchDetector)(nil)
+
+var missingFundamentalHarmonics = []int{2, 3, 4, 5}
+
 var (
-	weightingCurves = map[string]internal.WeightingCurve{
+	weightingCurves = map[WeightingType]internal.WeightingCurve{
 		"EMPTY": {},
 		"CUSTOM": {
 			-75.8, -70.1, -60.8, -52.1, -44.2, -37.5, -31.3, -25.6, -20.9, -16.5, -12.6, -9.6, -7.0, -4.7, -3.0, -1.8,
@@ -70,52 +125,78 @@ var (
 		SampleRate:        44100,
 		ShouldInterpolate: true,
 		Tolerance:         1,
-		WeightingType:     "CUSTOM",
+		WeightingType:     WeightingCustom,
 		MinFrequency:      20,
 		MaxFrequency:      22050,
 	}
 )
 
-// New creates a new PitchDetector instance using the provided Params.
+// New creates a new PitchDetector instance using the provided Params. FFT twiddle factors and plans are prepared
+// here, so the first detection doesn't incur a latency spike in real-time contexts.
 func New(params Params) (*PitchDetector, error) {
-	maxPeriodSamples := int(math.Min(math.Ceil(params.SampleRate/params.MinFrequency), float64(params.FrameSize/2)))
-	minPeriodSamples := int(math.Min(math.Floor(params.SampleRate/params.MaxFrequency), float64(params.FrameSize/2)))
-
-	if maxPeriodSamples <= minPeriodSamples {
-		minDetectable := params.SampleRate / float64(params.FrameSize/2)
-		return nil, fmt.Errorf("maxFrequency <= minFrequency or out of range; min detectable = %.2f Hz", minDetectable)
+	if err := params.Validate(); err != nil {
+		return nil, err
 	}
 
-	curve, ok := weightingCurves[strings.ToUpper(params.WeightingType)]
-	if !ok {
-		return nil, fmt.Errorf(
-			"invalid 'weightingType': %s; available weighting types: %+q",
-			params.WeightingType,
-			availableWeightingTypes,
-		)
-	}
+	minPeriodSamples, maxPeriodSamples := params.periodRange()
+	curve := weightingCurves[params.WeightingType.normalized()]
 
-	peakDetector, err := peakdetector.New(
-		peakdetector.Params{
-			Range:             float64(params.FrameSize)/2 + 1,
+	peakDetector, err := peaks.New(
+		peaks.Params{
+			Range:             float64(params.FFTSize())/2 + 1,
 			MaxPeaks:          1,
 			MaxPosition:       float64(maxPeriodSamples),
 			MinPosition:       float64(minPeriodSamples),
-			Threshold:         math.Inf(-1),
-			OrderBy:           peakdetector.PeakOrderByAmplitude,
+			Threshold:         math.Inf(1),
+			OrderBy:           peaks.OrderByAmplitude,
 			ShouldInterpolate: params.ShouldInterpolate,
+			Valleys:           true,
 		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize peak detection algorithm: %w", err)
 	}
 
+	// Weights are read on every detection next to the scratch buffers, so they get cache-aligned storage as well.
+	weights := internal.AlignedFloats(params.FFTSize()/2 + 1)
+	copy(weights, internal.ComputeSpectrumWeights(params.FFTSize(), params.analysisSampleRate(), curve))
+	for i := range weights {
+		if params.BinWeights != nil {
+			weights[i] *= params.BinWeights[i]
+		}
+		if params.WeightFunc != nil {
+			frequency := float64(i) * params.analysisSampleRate() / float64(params.FFTSize())
+			weight := params.WeightFunc(frequency)
+			if isInvalidWeight(weight) {
+				return nil, fmt.Errorf("invalid 'weightFunc' value at %g Hz: %g, must be finite and not negative",
+					frequency, weight)
+			}
+			weights[i] *= weight
+		}
+	}
+
+	window, err := params.window(params.analysisFrameSize())
+	if err != nil {
+		return nil, err
+	}
+
+	var decimator *internal.Decimator
+	if params.Decimation > 1 {
+		decimator = internal.NewDecimator(params.Decimation)
+	}
+	internal.PlanFFTReal(params.FFTSize())
+
 	return &PitchDetector{
 		params:           params,
-		weights:          internal.ComputeSpectrumWeights(params.FrameSize, params.SampleRate, curve),
+		weights:          weights,
 		minPeriodSamples: minPeriodSamples,
 		maxPeriodSamples: maxPeriodSamples,
 		peakDetector:     peakDetector,
+		decimator:        decimator,
+		window:           window,
+		scratchPool: sync.Pool{
+			New: func() any { return newScratch(params.FFTSize()) },
+		},
 	}, nil
 }
 
@@ -124,66 +205,246 @@ func NewWithDefaultParams() (*PitchDetector, error) {
 	return New(DefaultParams)
 }
 
+// Clone returns a detector with the same configuration, sharing the immutable precomputed weights and window with pd
+// but not its buffers or frequency range, which SetFrequencyRange changes on one without affecting the other.
+func (pd *PitchDetector) Clone() *PitchDetector {
+	peakDetector := *pd.peakDetector
+	return &PitchDetector{
+		params:           pd.params,
+		weights:          pd.weights,
+		minPeriodSamples: pd.minPeriodSamples,
+		maxPeriodSamples: pd.maxPeriodSamples,
+		peakDetector:     &peakDetector,
+		decimator:        pd.decimator,
+		window:           pd.window,
+		scratchPool: sync.Pool{
+			New: func() any { return newScratch(pd.params.FFTSize()) },
+		},
+	}
+}
+
+// Params returns the parameters the detector was created with.
+func (pd *PitchDetector) Params() Params {
+	return pd.params
+}
+
+// Window returns a copy of the window coefficients applied to frames before the FFT, one per analyzed sample.
+func (pd *PitchDetector) Window() []float64 {
+	return slices.Clone(pd.window)
+}
+
 // DetectFromFrame applies windowing and FFT to the input audio frame, then detects the fundamental frequency.
-// The input frame must match the configured FrameSize. Returns the detected frequency, confidence, and any error encountered.
+// The input frame must match the configured FrameSize and is modified in place. Returns the detected frequency,
+// confidence, and any error encountered.
 func (pd *PitchDetector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
-	if len(frame) != pd.params.FrameSize {
-		return 0, 0, fmt.Errorf("invalid frame size: expected %d, got %d", pd.params.FrameSize, len(frame))
+	if err := pd.checkFrame(frame); err != nil {
+		return 0, 0, err
 	}
-	return pd.DetectFromSpectrum(internal.PrepareSpectrum(frame))
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	frequency, confidence, _, err = pd.detectFrame(frame, scratch)
+	return frequency, confidence, err
 }
 
-// DetectFromSpectrum detects the fundamental frequency assuming the input is a magnitude spectrum. The spectrum should
-// be obtained via FFT, windowed with a Hann window and should represent FrameSize/2+1 bins. Returns the detected frequency,
-// confidence, and any error encountered.
-func (pd *PitchDetector) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
-	yinLen := pd.params.FrameSize/2 + 1
-	if len(spectrum) != yinLen {
-		return 0, 0, fmt.Errorf("invalid spectrum size: expected %d, got %d", yinLen, len(spectrum))
+// PrepareSpectrum checks the frame the same way DetectFromFrame does, then windows it in place and returns its
+// magnitude spectrum of FFTSize()/2+1 bins, suitable for DetectFromSpectrum. It allows processing the spectrum, e.g.
+// with spectral prefilters, before detection. With Decimation, a decimated copy of the frame is windowed instead.
+func (pd *PitchDetector) PrepareSpectrum(frame []float64) ([]float64, error) {
+	if err := pd.checkFrame(frame); err != nil {
+		return nil, err
 	}
+	return internal.PrepareSpectrum(pd.decimate(frame), pd.window, pd.params.FFTSize(), pd.params.DenormalThreshold), nil
+}
 
-	sqrMag, sum := make([]float64, pd.params.FrameSize), 0.0
-	sqrMag[0] = math.Pow(float64(spectrum[0]), 2) * pd.weights[0]
-	for i := 1; i < len(spectrum); i++ {
-		sqrMag[i] = math.Pow(float64(spectrum[i]), 2) * pd.weights[i]
-		sqrMag[pd.params.FrameSize-i] = sqrMag[i]
-		sum += sqrMag[i]
+// gatedSpectrum is PrepareSpectrum on a checked frame, using the scratch buffers, gated by SilenceThresholdDB. It
+// returns the spectrum, backed by the scratch, and the mean square of the frame, or no spectrum if the frame is below
+// the threshold, in which case it isn't transformed.
+func (pd *PitchDetector) gatedSpectrum(frame []float64, scratch *scratch) (spectrum []float64, power float64) {
+	windowed, power := pd.windowFrame(frame, scratch.input[:pd.params.analysisFrameSize()])
+	if pd.silent(decibels(power)) {
+		return nil, power
 	}
-	sum *= 2
+	internal.SpectrumInto(scratch.bins, scratch.spectrum, scratch.input, windowed, pd.params.DenormalThreshold)
+	return scratch.bins, power
+}
 
-	if sum == 0 {
-		return 0, 0, nil
+// windowFrame windows a checked frame in place and returns it with its mean square, measured in the windowing pass.
+// With Decimation, the frame is decimated into buffer, or a new buffer if it's nil, which is windowed instead; the
+// mean square is then measured in a pass of its own, as the decimated frame lacks the energy above its Nyquist
+// frequency.
+func (pd *PitchDetector) windowFrame(frame, buffer []float64) (windowed []float64, power float64) {
+	if pd.decimator == nil {
+		squares := internal.WindowFrame(frame, pd.window, pd.params.DenormalThreshold)
+		return frame, squares / float64(len(frame))
 	}
 
-	magnitude, phase := internal.CartesianToPolar(fft.FFTReal(sqrMag))
+	power = meanSquare(frame)
+	if buffer == nil {
+		buffer = make([]float64, pd.params.analysisFrameSize())
+	}
+	windowed = pd.decimator.DecimateInto(buffer, frame)
+	internal.WindowFrame(windowed, pd.window, pd.params.DenormalThreshold)
+	return windowed, power
+}
 
-	yin := make([]float64, yinLen)
-	yin[0] = 1
-	tmp := 0.0
-	for i := 1; i < len(yin); i++ {
-		yin[i] = sum - magnitude[i]*math.Cos(phase[i])
-		tmp += yin[i]
-		yin[i] *= float64(i) / tmp
+// decimate returns the frame low-pass filtered and downsampled by the configured Decimation, or the frame itself if
+// decimation is disabled.
+func (pd *PitchDetector) decimate(frame []float64) []float64 {
+	if pd.decimator == nil {
+		return frame
+	}
+	return pd.decimator.Decimate(frame)
+}
+
+// DetectFromFrames detects the fundamental frequency of many frames at once, e.g. when analyzing a whole file. The
+// frames are windowed and transformed in one batch, amortizing the FFT overhead. All frames must match the configured
+// FrameSize and are modified in place. Returns the detected frequencies and confidences in frame order.
+func (pd *PitchDetector) DetectFromFrames(frames [][]float64) (frequencies []float64, confidences []float64, err error) {
+	frequencies, confidences, _, err = pd.detectFrames(frames)
+	return frequencies, confidences, err
+}
+
+// detectFrames implements DetectFromFrames, returning the mean squares of the frames too, see detectFrame.
+func (pd *PitchDetector) detectFrames(frames [][]float64) (frequencies, confidences, powers []float64, err error) {
+	for i, frame := range frames {
+		if err := pd.checkFrame(frame); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid frame %d: %w", i, err)
+		}
+	}
+
+	// Frames are windowed first, which measures their levels, then only the frames that aren't silent are transformed.
+	frequencies, confidences = make([]float64, len(frames)), make([]float64, len(frames))
+	powers = make([]float64, len(frames))
+	indices, analyzed := make([]int, 0, len(frames)), make([][]float64, 0, len(frames))
+	for i, frame := range frames {
+		var windowed []float64
+		if windowed, powers[i] = pd.windowFrame(frame, nil); !pd.silent(decibels(powers[i])) {
+			indices, analyzed = append(indices, i), append(analyzed, windowed)
+		} else if frequencies[i], confidences[i], err = pd.noPitch(); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to detect pitch for frame %d: %w", i, err)
+		}
+	}
+
+	spectra := internal.Spectra(analyzed, pd.params.FFTSize(), pd.params.DenormalThreshold)
+	for j, spectrum := range spectra {
+		i := indices[j]
+		if frequencies[i], confidences[i], err = pd.DetectFromSpectrum(spectrum); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to detect pitch for frame %d: %w", i, err)
+		}
+	}
+
+	return frequencies, confidences, powers, nil
+}
+
+// DetectAll slides the analysis frame across the signal, advancing it by HopSize samples, and detects the fundamental
+// frequency of every frame fully contained in the signal. The signal is not modified. Returns the results in frame
+// order, the i-th frame starting at sample i*HopSize.
+func (pd *PitchDetector) DetectAll(signal []float64) ([]Result, error) {
+	frameSize, hopSize := pd.params.FrameSize, pd.params.HopSize
+	if hopSize == 0 {
+		hopSize = frameSize
+	}
+	if len(signal) < frameSize {
+		return nil, nil
 	}
 
-	if pd.params.Tolerance < 1.0 && slices.Min(yin) >= pd.params.Tolerance {
-		return 0, 0, nil
+	frames := make([][]float64, (len(signal)-frameSize)/hopSize+1)
+	storage := make([]float64, len(frames)*frameSize)
+	for i := range frames {
+		frames[i] = storage[i*frameSize : (i+1)*frameSize]
+		copy(frames[i], signal[i*hopSize:])
+	}
+
+	frequencies, confidences, powers, err := pd.detectFrames(frames)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(frames))
+	for i := range results {
+		results[i] = newResult(frequencies[i], confidences[i], pd.params.SampleRate)
+		results[i].Level = decibels(powers[i])
+		results[i].Frame = i
+		results[i].Time = float64(i*hopSize) / pd.params.SampleRate
+	}
+	return results, nil
+}
+
+// DetectBatch detects the fundamental frequency of many frames in parallel, e.g. for offline analysis of long
+// recordings, fanning them out to the given number of goroutines sharing the detector, GOMAXPROCS if not positive.
+// All frames must match the configured FrameSize and are modified in place. Returns the results in frame order,
+// timed as if consecutive frames were HopSize samples apart, like DetectAll, or the error of the first failed frame.
+func (pd *PitchDetector) DetectBatch(frames [][]float64, workers int) ([]Result, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	hopSize := pd.params.HopSize
+	if hopSize == 0 {
+		hopSize = pd.params.FrameSize
+	}
+
+	results, errs := make([]Result, len(frames)), make([]error, len(frames))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(frames)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scratch := pd.NewScratch()
+			for i := int(next.Add(1)) - 1; i < len(frames); i = int(next.Add(1)) - 1 {
+				results[i], errs[i] = pd.detectResult(frames[i], scratch.scratch)
+				results[i].Frame = i
+				results[i].Time = float64(i*hopSize) / pd.params.SampleRate
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect pitch for frame %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// DetectFromSpectrum detects the fundamental frequency assuming the input is a magnitude spectrum. The spectrum should
+// be obtained via FFT, windowed with the configured window and should represent FFTSize()/2+1 bins. Returns the detected frequency,
+// confidence, and any error encountered.
+func (pd *PitchDetector) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	if err := pd.checkSpectrum(spectrum); err != nil {
+		return 0, 0, err
+	}
+
+	scratch := pd.scratchPool.Get().(*scratch)
+	defer pd.scratchPool.Put(scratch)
+	return pd.detect(spectrum, scratch)
+}
+
+// detect implements DetectFromSpectrum on a spectrum of the correct size, using the given scratch buffers.
+func (pd *PitchDetector) detect(spectrum []float64, scratch *scratch) (frequency float64, confidence float64, err error) {
+	yin := scratch.yin
+	globalMin, ok := pd.yinFunction(spectrum, scratch)
+	if !ok {
+		return pd.noPitch()
+	}
+
+	if pd.params.Tolerance < 1.0 && globalMin >= pd.params.Tolerance {
+		return pd.noPitch()
 	}
 
 	var tau, yinMin float64
 	if pd.params.ShouldInterpolate {
-		for i := range yin {
-			yin[i] = -yin[i]
-		}
-		positions, amplitudes, err := pd.peakDetector.DetectPeaks(yin)
+		positions, amplitudes, err := pd.peakDetector.DetectInto(yin, &scratch.peaks)
 		if err != nil {
 			return 0, 0, fmt.Errorf("peak detection error: %v", err)
 		}
 		if len(positions) > 0 && len(amplitudes) > 0 {
 			tau = positions[0]
-			yinMin = -amplitudes[0]
+			yinMin = amplitudes[0]
 		} else {
-			return 0, 0, fmt.Errorf("no peaks found by peak detection algorithm")
+			return pd.noPitch()
 		}
 	} else {
 		yinMin = yin[pd.minPeriodSamples]
@@ -195,9 +456,125 @@ func (pd *PitchDetector) DetectFromSpectrum(spectrum []float64) (frequency float
 		}
 	}
 
+	return pd.correctPeriod(spectrum, yin, tau, yinMin)
+}
+
+// correctPeriod applies the configured octave, missing fundamental and HPS corrections to the period tau in analysis
+// samples, a minimum of the yin function of the spectrum, and returns its frequency and confidence.
+func (pd *PitchDetector) correctPeriod(
+	spectrum, yin []float64, tau, yinMin float64,
+) (frequency float64, confidence float64, err error) {
+	if tau != 0 && pd.params.OctaveCorrection {
+		tau, yinMin = pd.correctOctave(yin, tau, yinMin)
+	}
+
+	if tau != 0 && pd.params.MissingFundamental {
+		tau, yinMin = pd.resolveMissingFundamental(spectrum, yin, tau, yinMin)
+	}
+
+	if tau != 0 && pd.params.HPSCheck != HPSCheckNone {
+		tau, yinMin = pd.checkHPS(spectrum, yin, tau, yinMin)
+	}
+
 	if tau != 0 {
-		return pd.params.SampleRate / tau, 1 - yinMin, nil
+		return pd.params.analysisSampleRate() / tau, 1 - yinMin, nil
+	}
+
+	return pd.noPitch()
+}
+
+// yinFunction computes the cumulative mean normalized difference function of the spectrum into scratch.yin and
+// returns its global minimum, or false if the spectrum is silent.
+func (pd *PitchDetector) yinFunction(spectrum []float64, scratch *scratch) (globalMin float64, ok bool) {
+	yinLen := pd.params.FFTSize()/2 + 1
+	sqrMag, yin := scratch.sqrMag, scratch.yin
+
+	// Weighting, squaring and sum accumulation are done in a single vectorized pass over the spectrum, followed by
+	// mirroring. The DC bin doesn't count towards the sum.
+	sqrMag[0] = spectrum[0] * spectrum[0] * pd.weights[0]
+	sum := 2 * internal.WeightedSquares(sqrMag[1:yinLen], spectrum[1:], pd.weights[1:])
+	for i := 1; i < yinLen; i++ {
+		sqrMag[len(sqrMag)-i] = sqrMag[i]
+	}
+
+	if sum == 0 {
+		return 0, false
+	}
+
+	// The transform of the power spectrum is the autocorrelation, real as the power spectrum is symmetric, so the
+	// real part of each bin is used directly.
+	autocorrelation := scratch.spectrum
+	internal.FFTRealInto(autocorrelation, sqrMag)
+
+	// The difference function, its cumulative mean normalization and the global minimum share a single pass.
+	yin[0] = 1
+	cumulative, globalMin := 0.0, 1.0
+	for i := 1; i < yinLen; i++ {
+		difference := sum - real(autocorrelation[i])
+		cumulative += difference
+		yin[i] = difference * float64(i) / cumulative
+		globalMin = min(globalMin, yin[i])
 	}
+	return globalMin, true
+}
 
-	return 0, 0, nil
+// correctOctave checks whether half the detected period is a deep minimum of the yin function too, which means the
+// detected period spans two fundamental periods, a systematic octave-too-low error on tones with strong even
+// harmonics. It follows the candidate refinement of aubio's yinfft.
+func (pd *PitchDetector) correctOctave(yin []float64, tau, yinMin float64) (float64, float64) {
+	half := int(math.Round(tau / 2))
+	if half-1 < max(pd.minPeriodSamples, 1) {
+		return tau, yinMin
+	}
+
+	// The minimum near half the period, which is off by up to a sample for odd or interpolated periods.
+	best := half
+	for _, i := range []int{half - 1, half + 1} {
+		if yin[i] < yin[best] {
+			best = i
+		}
+	}
+	value := yin[best]
+	if value >= octaveCorrectionMaxYin {
+		return tau, yinMin
+	}
+
+	halfTau := float64(best)
+	if pd.params.ShouldInterpolate {
+		previous, next := yin[best-1], yin[best+1]
+		if curvature := previous - 2*value + next; curvature > 0 {
+			offset := 0.5 * (previous - next) / curvature
+			halfTau += offset
+			value -= 0.25 * (previous - next) * offset
+		}
+	}
+	return halfTau, value
+}
+
+// resolveMissingFundamental checks whether a subharmonic of the detected period is the actual fundamental, which
+// happens when the fundamental bin is weak or absent (e.g. telephone speech or small speakers). The subharmonic is
+// accepted when its harmonics 2-5 are present in the spectrum and its yin value is close to the detected minimum.
+func (pd *PitchDetector) resolveMissingFundamental(spectrum, yin []float64, tau, yinMin float64) (float64, float64) {
+	binFrequency := pd.params.analysisSampleRate() / float64(pd.params.FFTSize())
+	for divisor := 2; divisor <= missingFundamentalMaxDivisor; divisor++ {
+		candidateTau := tau * float64(divisor)
+		if candidateTau > float64(pd.maxPeriodSamples) {
+			break
+		}
+		candidateYin := yin[int(math.Round(candidateTau))]
+		if candidateYin > yinMin+missingFundamentalMaxYinDelta {
+			continue
+		}
+		support := internal.HarmonicSupport(
+			spectrum,
+			binFrequency,
+			pd.params.analysisSampleRate()/candidateTau,
+			missingFundamentalHarmonics,
+			missingFundamentalMinMagnitude,
+		)
+		if support >= missingFundamentalMinSupport {
+			return candidateTau, candidateYin
+		}
+	}
+	return tau, yinMin
 }
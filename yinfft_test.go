@@ -1,15 +1,24 @@
 package yinfft_test
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"iter"
 	"log/slog"
 	"math"
+	"math/rand/v2"
 	"os"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/signal"
+	"github.com/FreibergVlad/go-yinfft/yinffttest"
 	"github.com/go-audio/wav"
 )
 
@@ -95,54 +104,2788 @@ func TestDetectFromFrame_SineWaves(t *testing.T) {
 	}
 }
 
-func generateSineWave(freq, sampleRate float64, length int) []float64 {
-	signal := make([]float64, length)
+func TestDetectFromFrame_MissingFundamental(t *testing.T) {
+	t.Parallel()
+
+	// Amplitudes of harmonics 2-5 of the tones.
+	rolloff := []float64{1.0 / 2, 1.0 / 3, 1.0 / 4, 1.0 / 5}
+	// Strong even harmonics with a faint third and no fifth harmonic are periodic at twice the fundamental to the yin
+	// function, but still present in the spectrum.
+	evenHarmonics := []float64{1, 0.06, 1, 0}
+
+	tests := []struct {
+		name               string
+		amplitudes         []float64
+		frequency          float64
+		missingFundamental bool
+		wantFrequency      float64
+	}{
+		{"harmonics 2-5 of A2", rolloff, 110, true, 110},
+		{"harmonics 2-5 of D3", rolloff, 146.83, true, 146.83},
+		{"harmonics 2-5 of G3", rolloff, 196, true, 196},
+		{"even harmonics of A2 detected an octave high", evenHarmonics, 110, false, 220},
+		{"even harmonics of A2 resolved", evenHarmonics, 110, true, 110},
+		{"even harmonics of B2 detected an octave high", evenHarmonics, 123.47, false, 246.94},
+		{"even harmonics of B2 resolved", evenHarmonics, 123.47, true, 123.47},
+		{"even harmonics of C#3 detected an octave high", evenHarmonics, 138.59, false, 277.18},
+		{"even harmonics of C#3 resolved", evenHarmonics, 138.59, true, 138.59},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.MissingFundamental = test.missingFundamental
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frame := make([]float64, params.FrameSize)
+			for i, amplitude := range test.amplitudes {
+				wave := generateSineWave(float64(i+2)*test.frequency, params.SampleRate, params.FrameSize)
+				for j := range frame {
+					frame[j] += amplitude * wave[j]
+				}
+			}
+
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a frame: %v", err)
+			}
+
+			if math.Abs(frequency-test.wantFrequency) >= 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestDetectFromFrame_OctaveCorrection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		octaveCorrection bool
+		frequency        float64
+		wantFrequency    float64
+	}{
+		{"B3 detected an octave low", false, 246.94, 123.47},
+		{"B3 corrected", true, 246.94, 246.94},
+		{"E4 detected an octave low", false, 329.63, 164.82},
+		{"E4 corrected", true, 329.63, 329.63},
+		{"A2 unchanged", true, 110, 110},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.OctaveCorrection = test.octaveCorrection
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			// A rich tone with eight harmonics and a weak subharmonic, like a guitar body resonance.
+			frame := generateSineWave(test.frequency/2, params.SampleRate, params.FrameSize)
+			for i := range frame {
+				frame[i] *= 0.2
+			}
+			for harmonic := 1; harmonic <= 8; harmonic++ {
+				wave := generateSineWave(float64(harmonic)*test.frequency, params.SampleRate, params.FrameSize)
+				for i := range frame {
+					frame[i] += wave[i] / float64(harmonic)
+				}
+			}
+
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a frame: %v", err)
+			}
+			if math.Abs(frequency-test.wantFrequency) > 0.5 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestDetectFromFrames(t *testing.T) {
+	t.Parallel()
+
+	frequencies := []float64{73.42, 82.41, 110, 146.83, 196}
+	pitchDetector := pitchDetector(t)
+
+	frames := make([][]float64, len(frequencies))
+	for i, frequency := range frequencies {
+		frames[i] = generateSineWave(frequency, yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize)
+	}
+
+	gotFrequencies, _, err := pitchDetector.DetectFromFrames(frames)
+	if err != nil {
+		t.Fatalf("error detecting pitch for frames: %v", err)
+	}
+
+	for i, wantFrequency := range frequencies {
+		frame := generateSineWave(wantFrequency, yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize)
+		frequency, _, err := pitchDetector.DetectFromFrame(frame)
+		if err != nil {
+			t.Fatalf("error detecting pitch for a frame: %v", err)
+		}
+		if math.Abs(gotFrequencies[i]-frequency) > 1e-6 {
+			t.Errorf("batched detection differs for frame %d, got %.4f Hz, want %.4f Hz", i, gotFrequencies[i], frequency)
+		}
+	}
+}
+
+func TestOverlapAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize = 2048
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	// A vibrato tone, 330 Hz +- 10 Hz at 5 Hz.
+	signal := make([]float64, 3*params.FrameSize)
+	phase := 0.0
 	for i := range signal {
-		signal[i] = math.Sin(2 * math.Pi * freq * float64(i) / sampleRate)
+		instantFrequency := 330 + 10*math.Sin(2*math.Pi*5*float64(i)/params.SampleRate)
+		phase += 2 * math.Pi * instantFrequency / params.SampleRate
+		signal[i] = math.Sin(phase)
+	}
+
+	testCases := []struct {
+		name    string
+		hopSize int
+	}{
+		{name: "incremental update", hopSize: 16},
+		{name: "transform per hop", hopSize: 256},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			analyzer, err := pitchDetector.NewOverlapAnalyzer(tc.hopSize)
+			if err != nil {
+				t.Fatalf("error creating overlap analyzer: %v", err)
+			}
+
+			for end := tc.hopSize; end <= len(signal); end += tc.hopSize {
+				frequency, _, err := analyzer.Push(signal[end-tc.hopSize : end])
+				if err != nil {
+					t.Fatalf("error pushing hop: %v", err)
+				}
+				if !analyzer.Ready() {
+					continue
+				}
+
+				frame := slices.Clone(signal[end-params.FrameSize : end])
+				wantFrequency, _, err := pitchDetector.DetectFromFrame(frame)
+				if err != nil {
+					t.Fatalf("error detecting pitch for a frame: %v", err)
+				}
+				if math.Abs(frequency-wantFrequency) > 0.5 {
+					t.Errorf("incorrect frequency at sample %d, got %.2f Hz, want %.2f Hz", end, frequency, wantFrequency)
+				}
+			}
+
+			analyzer.Reset()
+			if analyzer.Ready() {
+				t.Errorf("analyzer is ready after reset")
+			}
+		})
+	}
+}
+
+func TestOverlapAnalyzer_Incremental(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		frameSize, hopSize int
+		want               bool
+	}{
+		{2048, 1, true},
+		{2048, 24, true},
+		{2048, 25, false},
+		{8192, 28, true},
+		{8192, 29, false},
+		{8192, 1024, false},
+	}
+
+	for _, test := range tests {
+		params := yinfft.DefaultParams
+		params.FrameSize = test.frameSize
+		pitchDetector, err := yinfft.New(params)
+		if err != nil {
+			t.Fatalf("error creating pitch detector: %v", err)
+		}
+		analyzer, err := pitchDetector.NewOverlapAnalyzer(test.hopSize)
+		if err != nil {
+			t.Fatalf("error creating overlap analyzer: %v", err)
+		}
+		if got := analyzer.Incremental(); got != test.want {
+			t.Errorf("incorrect update mode for a frame of %d and a hop of %d, got incremental %t, want %t",
+				test.frameSize, test.hopSize, got, test.want)
+		}
+	}
+}
+
+func TestOverlapAnalyzer_Checks(t *testing.T) {
+	t.Parallel()
+
+	const frameSize, hopSize = 2048, 16
+	tone := func(amplitude float64) []float64 {
+		signal := make([]float64, 2*frameSize)
+		for i := range signal {
+			signal[i] = amplitude * math.Sin(2*math.Pi*330*float64(i)/yinfft.DefaultParams.SampleRate)
+		}
+		return signal
+	}
+	// push pushes the signal hop by hop, returning the results of the last hop.
+	push := func(t *testing.T, analyzer *yinfft.OverlapAnalyzer, signal []float64) (float64, error) {
+		t.Helper()
+		var frequency float64
+		var err error
+		for end := hopSize; end <= len(signal); end += hopSize {
+			if frequency, _, err = analyzer.Push(signal[end-hopSize : end]); err != nil {
+				return 0, err
+			}
+		}
+		return frequency, nil
+	}
+	newAnalyzer := func(t *testing.T, params yinfft.Params) *yinfft.OverlapAnalyzer {
+		t.Helper()
+		params.FrameSize = frameSize
+		pitchDetector, err := yinfft.New(params)
+		if err != nil {
+			t.Fatalf("error creating pitch detector: %v", err)
+		}
+		analyzer, err := pitchDetector.NewOverlapAnalyzer(hopSize)
+		if err != nil {
+			t.Fatalf("error creating overlap analyzer: %v", err)
+		}
+		return analyzer
+	}
+
+	t.Run("sanitize", func(t *testing.T) {
+		t.Parallel()
+
+		params := yinfft.DefaultParams
+		params.SanitizeMode = yinfft.SanitizeZero
+		analyzer := newAnalyzer(t, params)
+
+		signal := tone(0.5)
+		signal[len(signal)-frameSize/2] = math.NaN()
+		frequency, err := push(t, analyzer, signal)
+		if err != nil {
+			t.Fatalf("error pushing hops: %v", err)
+		}
+		if signal[len(signal)-frameSize/2] != 0 {
+			t.Error("NaN sample of the hop wasn't replaced")
+		}
+		if math.Abs(frequency-330) > 1 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want 330 Hz", frequency)
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		t.Parallel()
+
+		params := yinfft.DefaultParams
+		params.ValidateFrames = true
+		analyzer := newAnalyzer(t, params)
+
+		signal := tone(0.5)
+		if _, err := push(t, analyzer, signal[:frameSize]); err != nil {
+			t.Fatalf("error pushing hops: %v", err)
+		}
+		hop := slices.Clone(signal[frameSize : frameSize+hopSize])
+		hop[5] = math.Inf(1)
+		var infErr *yinfft.InfSampleError
+		if _, _, err := analyzer.Push(hop); !errors.As(err, &infErr) || infErr.Index != 5 {
+			t.Fatalf("incorrect error for an infinite sample, got %v, want *InfSampleError at index 5", err)
+		}
+
+		// The rejected hop isn't appended, so the stream continues with the valid samples.
+		frequency, err := push(t, analyzer, signal[frameSize:])
+		if err != nil {
+			t.Fatalf("error pushing hops: %v", err)
+		}
+		if math.Abs(frequency-330) > 1 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want 330 Hz", frequency)
+		}
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		t.Parallel()
+
+		params := yinfft.DefaultParams
+		params.SilenceThresholdDB, params.OnNoPitch = -40, yinfft.NoPitchNaN
+		analyzer := newAnalyzer(t, params)
+
+		// A -63 dBFS tone is gated, the following -9 dBFS tone isn't once it fills the frame.
+		frequency, err := push(t, analyzer, tone(0.001))
+		if err != nil {
+			t.Fatalf("error pushing hops: %v", err)
+		}
+		if !math.IsNaN(frequency) {
+			t.Errorf("incorrect frequency of a silent frame, got %.2f Hz, want NaN", frequency)
+		}
+		if frequency, err = push(t, analyzer, tone(0.5)); err != nil {
+			t.Fatalf("error pushing hops: %v", err)
+		}
+		if math.Abs(frequency-330) > 1 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want 330 Hz", frequency)
+		}
+	})
+}
+
+func TestValidateFrame(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	frameWith := func(index int, value float64) []float64 {
+		frame := make([]float64, yinfft.DefaultParams.FrameSize)
+		frame[index] = value
+		return frame
+	}
+
+	tests := []struct {
+		name    string
+		frame   []float64
+		wantErr any
+	}{
+		{"valid frame", frameWith(0, 0.5), nil},
+		{"wrong length", make([]float64, 10), new(*yinfft.FrameSizeError)},
+		{"NaN sample", frameWith(3, math.NaN()), new(*yinfft.NaNSampleError)},
+		{"infinite sample", frameWith(5, math.Inf(-1)), new(*yinfft.InfSampleError)},
+		{"denormal sample", frameWith(7, 1e-310), new(*yinfft.DenormalSampleError)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := pitchDetector.ValidateFrame(test.frame)
+			if test.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, test.wantErr) {
+				t.Errorf("incorrect error, got %v, want %T", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDetectFromFrame_DenormalThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		denormalThreshold float64
+		wantFlushed       bool
+	}{
+		{"disabled", 0, false},
+		{"enabled", 1e-30, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.DenormalThreshold = test.denormalThreshold
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			// A tone decaying into denormal values in its last quarter, like the tail of a released note.
+			frame := generateSineWave(196, params.SampleRate, params.FrameSize)
+			tail := frame[3*params.FrameSize/4:]
+			for i := range tail {
+				tail[i] *= 0x1p-1060
+			}
+			analysis, err := pitchDetector.Analyze(frame)
+			if err != nil {
+				t.Fatalf("error analyzing frame: %v", err)
+			}
+
+			denormals := 0
+			for _, sample := range analysis.WindowedFrame[3*params.FrameSize/4:] {
+				if sample != 0 && math.Abs(sample) < 0x1p-1022 {
+					denormals++
+				}
+			}
+			if flushed := denormals == 0; flushed != test.wantFlushed {
+				t.Errorf("incorrect flushing of the decayed tail, got %d denormal samples", denormals)
+			}
+			if math.Abs(analysis.Frequency-196) >= 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", analysis.Frequency, 196.0)
+			}
+
+			// Once the whole frame has decayed below the threshold it's silent.
+			for i := range frame {
+				frame[i] = 0x1p-1060 * math.Sin(float64(i))
+			}
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a frame: %v", err)
+			}
+			if test.wantFlushed && frequency != 0 {
+				t.Errorf("incorrect frequency of a flushed frame, got %.2f Hz, want 0 Hz", frequency)
+			}
+		})
+	}
+}
+
+func TestValidateFrame_DenormalThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		denormalThreshold float64
+		wantErr           bool
+	}{
+		{"without flushing", 0, true},
+		{"flushing denormals", 1e-30, false},
+		{"below the smallest normal value", 0x1p-1074, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.ValidateFrames, params.DenormalThreshold = true, test.denormalThreshold
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			// A tone decaying into denormal values in its last quarter is flushed rather than rejected.
+			frame := generateSineWave(196, params.SampleRate, params.FrameSize)
+			tail := frame[3*params.FrameSize/4:]
+			for i := range tail {
+				tail[i] *= 0x1p-1060
+			}
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			var denormalErr *yinfft.DenormalSampleError
+			if errors.As(err, &denormalErr) != test.wantErr {
+				t.Fatalf("incorrect error, got %v, want a denormal sample error %t", err, test.wantErr)
+			}
+			if !test.wantErr && math.Abs(frequency-196) >= 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 196.0)
+			}
+		})
+	}
+}
+
+func TestDetectFromFrame_SanitizeMode(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []yinfft.SanitizeMode{yinfft.SanitizeZero, yinfft.SanitizeClamp} {
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.ValidateFrames = true
+			params.SanitizeMode = mode
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frame := generateSineWave(196, params.SampleRate, params.FrameSize)
+			frame[100], frame[200] = math.NaN(), math.Inf(1)
+
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a sanitized frame: %v", err)
+			}
+			if math.Abs(frequency-196) >= 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 196.0)
+			}
+
+			// A frame of the wrong length is rejected before sanitization, so the caller's samples are left as is.
+			short := []float64{math.NaN(), math.Inf(1), 0.5}
+			if _, _, err := pitchDetector.DetectFromFrame(short); !errors.Is(err, yinfft.ErrInvalidFrameSize) {
+				t.Fatalf("incorrect error for a short frame, got %v, want %v", err, yinfft.ErrInvalidFrameSize)
+			}
+			if !math.IsNaN(short[0]) || !math.IsInf(short[1], 1) {
+				t.Errorf("a rejected frame was modified: %v", short)
+			}
+		})
+	}
+}
+
+func BenchmarkDetectFromFrame(b *testing.B) {
+	for _, frameSize := range []int{960, 1024, 2048, 4096, 8192, 16384} {
+		b.Run(fmt.Sprintf("frameSize=%d", frameSize), func(b *testing.B) {
+			params := yinfft.DefaultParams
+			params.FrameSize = frameSize
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				b.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			signal := generateSineWave(196, params.SampleRate, frameSize)
+			frame := make([]float64, frameSize)
+
+			b.ReportAllocs()
+			for range b.N {
+				copy(frame, signal)
+				if _, _, err := pitchDetector.DetectFromFrame(frame); err != nil {
+					b.Fatalf("error detecting pitch for a frame: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestDetectFromSpectrum_Allocations isn't parallel, as allocations are counted process-wide.
+func TestDetectFromSpectrum_Allocations(t *testing.T) {
+	for _, frameSize := range []int{2048, 8192} {
+		params := yinfft.DefaultParams
+		params.FrameSize = frameSize
+		pitchDetector, err := yinfft.New(params)
+		if err != nil {
+			t.Fatalf("error creating pitch detector: %v", err)
+		}
+
+		spectrum, err := pitchDetector.PrepareSpectrum(generateSineWave(196, params.SampleRate, frameSize))
+		if err != nil {
+			t.Fatalf("error preparing spectrum: %v", err)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, _, err := pitchDetector.DetectFromSpectrum(spectrum); err != nil {
+				t.Fatalf("error detecting pitch for a spectrum: %v", err)
+			}
+		})
+		if allocs > 0 {
+			t.Errorf("incorrect allocations for frame size %d, got %.0f, want 0", frameSize, allocs)
+		}
+
+		scratch := pitchDetector.NewScratch()
+		frame := make([]float64, frameSize)
+		allocs = testing.AllocsPerRun(100, func() {
+			copy(frame, spectrum)
+			if _, _, err := pitchDetector.DetectFromFrameInto(frame, scratch); err != nil {
+				t.Fatalf("error detecting pitch for a frame: %v", err)
+			}
+		})
+		if allocs > 0 {
+			t.Errorf("incorrect allocations into scratch for frame size %d, got %.0f, want 0", frameSize, allocs)
+		}
+	}
+}
+
+// TestNew_SharedWindow checks that detectors with a named window share its coefficients instead of computing their own.
+// Not parallel, as allocations are counted process-wide.
+func TestNew_SharedWindow(t *testing.T) {
+	// allocated returns the bytes allocated by New once its caches are warm.
+	allocated := func(params yinfft.Params) uint64 {
+		if _, err := yinfft.New(params); err != nil {
+			t.Fatalf("error creating pitch detector: %v", err)
+		}
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		if _, err := yinfft.New(params); err != nil {
+			t.Fatalf("error creating pitch detector: %v", err)
+		}
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+
+	params := yinfft.DefaultParams
+	params.FrameSize = 1 << 16
+	windowBytes := uint64(8 * params.FrameSize)
+
+	// A Kaiser window with a custom shape parameter is computed by every detector.
+	custom := params
+	custom.Window, custom.KaiserBeta = yinfft.WindowKaiser, 5
+	customBytes := allocated(custom)
+
+	for _, window := range []yinfft.WindowType{"", yinfft.WindowBlackmanHarris, yinfft.WindowKaiser} {
+		params := params
+		params.Window = window
+		// Half a window of slack leaves room for small allocations that differ between windows.
+		if bytes := allocated(params); bytes+windowBytes/2 > customBytes {
+			t.Errorf("New with window %q allocates %d bytes, want at least half of the %d bytes of the window less "+
+				"than the %d bytes of a custom window", window, bytes, windowBytes, customBytes)
+		}
+	}
+}
+
+// TestNew_PlansFFT checks that the first detection of a new detector doesn't allocate, i.e. that FFT plans are
+// prepared by New. Not parallel, as allocations are counted process-wide.
+func TestNew_PlansFFT(t *testing.T) {
+	params := yinfft.DefaultParams
+	params.FrameSize, params.SampleRate, params.MinFrequency, params.MaxFrequency = 128, 8000, 250, 2000
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	scratch := pitchDetector.NewScratch()
+	frame := generateSineWave(500, params.SampleRate, params.FrameSize)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, _, err := pitchDetector.DetectFromFrameInto(frame, scratch); err != nil {
+		t.Fatalf("error detecting pitch for a frame: %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if allocs := after.Mallocs - before.Mallocs; allocs > 0 {
+		t.Errorf("incorrect allocations of the first detection, got %d, want 0", allocs)
+	}
+}
+
+func BenchmarkPrepareSpectrum(b *testing.B) {
+	for _, frameSize := range []int{2048, 8192} {
+		b.Run(fmt.Sprintf("frameSize=%d", frameSize), func(b *testing.B) {
+			params := yinfft.DefaultParams
+			params.FrameSize = frameSize
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				b.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			signal := generateSineWave(196, params.SampleRate, frameSize)
+			frame := make([]float64, frameSize)
+
+			b.ReportAllocs()
+			for range b.N {
+				copy(frame, signal)
+				if _, err := pitchDetector.PrepareSpectrum(frame); err != nil {
+					b.Fatalf("error preparing spectrum: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDetectFromSpectrum(b *testing.B) {
+	for _, frameSize := range []int{2048, 4096, 8192, 16384} {
+		b.Run(fmt.Sprintf("frameSize=%d", frameSize), func(b *testing.B) {
+			params := yinfft.DefaultParams
+			params.FrameSize = frameSize
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				b.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			spectrum := make([]float64, frameSize/2+1)
+			for i := range spectrum {
+				spectrum[i] = 1 / float64(1+(i%37))
+			}
+
+			b.ReportAllocs()
+			for range b.N {
+				if _, _, err := pitchDetector.DetectFromSpectrum(spectrum); err != nil {
+					b.Fatalf("error detecting pitch for a spectrum: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func generateSineWave(freq, sampleRate float64, length int) []float64 {
+	return signal.Sine(freq, sampleRate, length)
+}
+
+func framesFromWAV(filename string, chunkLen int) (iter.Seq[[]float64], error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := wav.NewDecoder(file)
+	if !decoder.IsValidFile() {
+		return nil, decoder.Err()
+	}
+
+	buffer, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	// Interleaved channels are averaged, analyzing them as one channel would halve the detected frequencies.
+	channels := buffer.Format.NumChannels
+	samples := make([]float64, len(buffer.Data)/channels)
+	for i := range samples {
+		for channel := range channels {
+			samples[i] += float64(buffer.Data[i*channels+channel])
+		}
+		samples[i] /= float64(channels)
+	}
+
+	return func(yield func([]float64) bool) {
+		for chunk := range slices.Chunk(samples, chunkLen) {
+			if len(chunk) < chunkLen {
+				continue
+			}
+			if !yield(chunk) {
+				return
+			}
+		}
+	}, nil
+}
+
+func pitchDetector(t *testing.T) *yinfft.PitchDetector {
+	t.Helper()
+
+	params := yinfft.DefaultParams
+	params.Logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	return pitchDetector
+}
+
+func TestPitchTracker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		transitionCost   float64
+		octaveCorrection bool
+		want             float64
+	}{
+		{"without continuity prior", 0, false, 220},
+		{"with continuity prior", 1, false, 110},
+		// The prior picks the period of the previous pitch, which octave correction then halves.
+		{"with continuity prior and octave correction", 1, true, 220},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.OctaveCorrection = test.octaveCorrection
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+			tracker, err := pitchDetector.NewPitchTracker(yinfft.TrackerParams{TransitionCost: test.transitionCost})
+			if err != nil {
+				t.Fatalf("error creating pitch tracker: %v", err)
+			}
+
+			// A sustained 110 Hz note establishes the previous pitch, then a frame at its octave follows, which has a
+			// yin minimum at the previous period too.
+			for _, frequency := range []float64{110, 110, 220} {
+				frame := generateSineWave(frequency, params.SampleRate, params.FrameSize)
+				detected, _, err := tracker.DetectFromFrame(frame)
+				if err != nil {
+					t.Fatalf("error detecting pitch: %v", err)
+				}
+				if frequency == 220 && math.Abs(detected-test.want) > 1 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", detected, test.want)
+				}
+			}
+
+			// After a reset, nothing biases the detection toward the previous stream.
+			tracker.Reset()
+			detected, _, err := tracker.DetectFromFrame(generateSineWave(220, params.SampleRate, params.FrameSize))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(detected-220) > 1 {
+				t.Errorf("incorrect frequency after reset, got %.2f Hz, want %.2f Hz", detected, 220.0)
+			}
+		})
+	}
+}
+
+func TestPitchTracker_Silence(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.SilenceThresholdDB, params.OnNoPitch = -60, yinfft.NoPitchNaN
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	tracker, err := pitchDetector.NewPitchTracker(yinfft.TrackerParams{TransitionCost: 1})
+	if err != nil {
+		t.Fatalf("error creating pitch tracker: %v", err)
+	}
+
+	// A -83 dBFS tone following a sustained note is gated like by the wrapped detector, and forgets the note.
+	for _, amplitude := range []float64{0.5, 0.5, 1e-4} {
+		frame := generateSineWave(220, params.SampleRate, params.FrameSize)
+		for i := range frame {
+			frame[i] *= amplitude
+		}
+		want, _, err := pitchDetector.DetectFromFrame(slices.Clone(frame))
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		frequency, _, err := tracker.DetectFromFrame(frame)
+		if err != nil {
+			t.Fatalf("error tracking pitch: %v", err)
+		}
+		if math.IsNaN(frequency) != math.IsNaN(want) || math.Abs(frequency-want) > 0.5 {
+			t.Errorf("incorrect frequency of a tone of amplitude %g, got %.2f Hz, want %.2f Hz", amplitude, frequency, want)
+		}
+	}
+	if frequency, _, _ := tracker.DetectFromFrame(make([]float64, params.FrameSize)); !math.IsNaN(frequency) {
+		t.Errorf("incorrect frequency of a silent frame, got %.2f Hz, want NaN", frequency)
+	}
+}
+
+// TestPitchTracker_Allocations isn't parallel, as allocations are counted process-wide.
+func TestPitchTracker_Allocations(t *testing.T) {
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+	tracker, err := pitchDetector.NewPitchTracker(yinfft.TrackerParams{TransitionCost: 1})
+	if err != nil {
+		t.Fatalf("error creating pitch tracker: %v", err)
+	}
+
+	signal := generateSineWave(220, params.SampleRate, params.FrameSize)
+	frame := make([]float64, params.FrameSize)
+	detect := func() {
+		copy(frame, signal)
+		if _, _, err := tracker.DetectFromFrame(frame); err != nil {
+			t.Fatalf("error tracking pitch: %v", err)
+		}
+	}
+	// The first detections set the previous pitch and grow the pooled candidate buffer.
+	detect()
+	detect()
+	if allocs := testing.AllocsPerRun(10, detect); allocs != 0 {
+		t.Errorf("DetectFromFrame allocates %v times per run, want 0", allocs)
+	}
+}
+
+func TestStreamDetector(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+	hopSize := params.FrameSize / 4
+	stream, err := pitchDetector.NewStreamDetector(hopSize)
+	if err != nil {
+		t.Fatalf("error creating stream detector: %v", err)
+	}
+
+	// Blocks of an odd size exercise wrapping around the ring buffer.
+	signal := generateSineWave(440, params.SampleRate, 5*params.FrameSize)
+	results := 0
+	for start := 0; start < len(signal); start += 1001 {
+		stream.Write(signal[start:min(start+1001, len(signal))])
+		for {
+			result, ok, err := stream.Poll()
+			if err != nil {
+				t.Fatalf("error polling stream: %v", err)
+			}
+			if !ok {
+				break
+			}
+			results++
+			if math.Abs(result.Frequency-440) > 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, 440.0)
+			}
+		}
+	}
+
+	if want := (len(signal)-params.FrameSize)/hopSize + 1; results != want {
+		t.Errorf("incorrect number of results, got %d, want %d", results, want)
+	}
+	if want := params.FrameSize - hopSize; stream.Buffered() != want {
+		t.Errorf("incorrect number of buffered samples, got %d, want %d", stream.Buffered(), want)
+	}
+
+	// Writing more than the buffer holds grows it without losing samples.
+	stream.Reset()
+	stream.Write(signal[:params.FrameSize])
+	stream.Write(signal[params.FrameSize:])
+	if stream.Buffered() != len(signal) {
+		t.Errorf("incorrect number of buffered samples, got %d, want %d", stream.Buffered(), len(signal))
+	}
+	for range (len(signal)-params.FrameSize)/hopSize + 1 {
+		if result, _, _ := stream.Poll(); math.Abs(result.Frequency-440) > 1 {
+			t.Fatalf("incorrect frequency after growing, got %.2f Hz, want %.2f Hz", result.Frequency, 440.0)
+		}
+	}
+}
+
+func TestDetectAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		hopSize     int
+		wantResults int
+	}{
+		{"non-overlapping frames", 0, 4},
+		{"half overlapping frames", 1024, 7},
+		{"quarter hop", 512, 13},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize = 2048
+			params.HopSize = test.hopSize
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			signal := generateSineWave(440, params.SampleRate, 4*params.FrameSize+100)
+			results, err := pitchDetector.DetectAll(signal)
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if len(results) != test.wantResults {
+				t.Fatalf("incorrect number of results, got %d, want %d", len(results), test.wantResults)
+			}
+			hopSize := test.hopSize
+			if hopSize == 0 {
+				hopSize = params.FrameSize
+			}
+			for i, result := range results {
+				if math.Abs(result.Frequency-440) > 1 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, 440.0)
+				}
+				if !result.Voiced || math.Abs(result.Tau-params.SampleRate/440) > 0.1 {
+					t.Errorf("incorrect period, got %.2f samples, voiced %t", result.Tau, result.Voiced)
+				}
+				if wantTime := float64(i*hopSize) / params.SampleRate; result.Frame != i || result.Time != wantTime {
+					t.Errorf("incorrect frame, got %d at %.4f s, want %d at %.4f s", result.Frame, result.Time, i, wantTime)
+				}
+			}
+		})
+	}
+
+	params := yinfft.DefaultParams
+	params.HopSize = params.FrameSize + 1
+	if _, err := yinfft.New(params); err == nil {
+		t.Errorf("expected error for hop size larger than frame size")
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		algorithm yinfft.Algorithm
+		wantErr   bool
+	}{
+		{yinfft.AlgorithmYinFFT, false},
+		{yinfft.AlgorithmYin, false},
+		{yinfft.AlgorithmMPM, false},
+		{"swipe", true},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.algorithm), func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize = 2048
+			params.MaxFrequency = 4000
+			detector, err := yinfft.NewDetector(test.algorithm, params)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected error for algorithm %s", test.algorithm)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			result, err := detector.Detect(generateSineWave(440, params.SampleRate, params.FrameSize))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(result.Frequency-440) > 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, 440.0)
+			}
+		})
+	}
+}
+
+func TestDetectCandidates(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+
+	candidates, err := pitchDetector.DetectCandidates(generateSineWave(220, params.SampleRate, params.FrameSize), 3)
+	if err != nil {
+		t.Fatalf("error detecting candidates: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("incorrect number of candidates, got %d, want %d", len(candidates), 3)
+	}
+	// The period and its multiples are all deep minima of a pure tone.
+	for i, candidate := range candidates {
+		periods := candidate.Tau / (params.SampleRate / 220)
+		if math.Round(periods) < 1 || math.Abs(periods-math.Round(periods)) > 0.01 {
+			t.Errorf("candidate %d is not a multiple of the period, got %.2f periods", i, periods)
+		}
+		if i > 0 && candidate.Yin < candidates[i-1].Yin {
+			t.Errorf("candidates are not ordered by yin value, got %.4f after %.4f", candidate.Yin, candidates[i-1].Yin)
+		}
+	}
+	if math.Abs(candidates[0].Frequency-220) > 1 {
+		t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", candidates[0].Frequency, 220.0)
+	}
+}
+
+func TestHarmonics(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+
+	// Harmonics with amplitudes 1, 0.5, 0 and 0.25, the second slightly sharp.
+	frequencies, amplitudes := []float64{220, 441, 660, 880}, []float64{1, 0.5, 0, 0.25}
+	frame := make([]float64, params.FrameSize)
+	for i := range frame {
+		for j, frequency := range frequencies {
+			frame[i] += amplitudes[j] * math.Sin(2*math.Pi*frequency*float64(i)/params.SampleRate)
+		}
+	}
+	spectrum, err := pitchDetector.PrepareSpectrum(frame)
+	if err != nil {
+		t.Fatalf("error preparing spectrum: %v", err)
+	}
+
+	harmonics, err := pitchDetector.Harmonics(spectrum, 220, len(frequencies))
+	if err != nil {
+		t.Fatalf("error detecting harmonics: %v", err)
+	}
+	if len(harmonics) != len(frequencies) {
+		t.Fatalf("incorrect number of harmonics, got %d, want %d", len(harmonics), len(frequencies))
+	}
+	for i, harmonic := range harmonics {
+		if harmonic.Number != i+1 {
+			t.Errorf("incorrect number of harmonic %d, got %d", i, harmonic.Number)
+		}
+		relativeAmplitude := harmonic.Amplitude / harmonics[0].Amplitude
+		if math.Abs(relativeAmplitude-amplitudes[i]) > 0.02 {
+			t.Errorf("incorrect relative amplitude of harmonic %d, got %.3f, want %.3f", i+1, relativeAmplitude, amplitudes[i])
+		}
+		if amplitudes[i] > 0 && math.Abs(harmonic.Frequency-frequencies[i]) > 0.5 {
+			t.Errorf("incorrect frequency of harmonic %d, got %.2f Hz, want %.2f Hz", i+1, harmonic.Frequency, frequencies[i])
+		}
+	}
+
+	if harmonics, _ := pitchDetector.Harmonics(spectrum, 20000, 3); len(harmonics) != 1 {
+		t.Errorf("incorrect number of harmonics below the Nyquist frequency, got %d, want 1", len(harmonics))
+	}
+	if _, err := pitchDetector.Harmonics(spectrum[1:], 220, 3); !errors.Is(err, yinfft.ErrInvalidSpectrumSize) {
+		t.Errorf("incorrect error for a spectrum of wrong size, got %v", err)
+	}
+}
+
+func TestHPSCheck(t *testing.T) {
+	t.Parallel()
+
+	// Harmonics with weak odd ones, which makes the yin function deepest at half the period.
+	sampleRate := yinfft.DefaultParams.SampleRate
+	octaveError := make([]float64, 4096)
+	for i := range octaveError {
+		for harmonic, amplitude := range []float64{0.1, 1, 0.1, 1, 0.1, 1} {
+			octaveError[i] += amplitude * math.Sin(2*math.Pi*110*float64(harmonic+1)*float64(i)/sampleRate)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		check          yinfft.HPSCheck
+		frame          []float64
+		wantFrequency  float64
+		wantConfidence float64 // Upper bound of the confidence if positive.
+	}{
+		{"no check", yinfft.HPSCheckNone, octaveError, 220, 0},
+		{"flag", yinfft.HPSCheckFlag, octaveError, 220, 0.5},
+		{"correct", yinfft.HPSCheckCorrect, octaveError, 110, 0},
+		{"pure tone", yinfft.HPSCheckCorrect, generateSineWave(220, sampleRate, 4096), 220, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize, params.HPSCheck = 4096, test.check
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frequency, confidence, err := pitchDetector.DetectFromFrame(slices.Clone(test.frame))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(frequency-test.wantFrequency) > 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.wantFrequency)
+			}
+			if test.wantConfidence > 0 && confidence > test.wantConfidence {
+				t.Errorf("incorrect confidence, got %.3f, want at most %.3f", confidence, test.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectQuality(t *testing.T) {
+	t.Parallel()
+
+	sampleRate := yinfft.DefaultParams.SampleRate
+	random := rand.New(rand.NewPCG(1, 2))
+	frameSize := yinfft.DefaultParams.FrameSize
+	clean, creaky, breathy := make([]float64, frameSize), make([]float64, frameSize), make([]float64, frameSize)
+	for i := range clean {
+		for harmonic, amplitude := range []float64{1, 0.5, 0.3} {
+			clean[i] += amplitude * math.Sin(2*math.Pi*200*float64(harmonic+1)*float64(i)/sampleRate)
+			creaky[i] += 0.1 * amplitude * math.Sin(2*math.Pi*200*(float64(harmonic)+0.5)*float64(i)/sampleRate)
+		}
+		creaky[i] += clean[i]
+		breathy[i] = clean[i] + 0.5*random.NormFloat64()
+	}
+
+	tests := []struct {
+		name            string
+		frame           []float64
+		minSHR          float64
+		maxSHR          float64
+		minAperiodicity float64
+		maxAperiodicity float64
+	}{
+		{"clean", clean, 0, 0.01, 0, 0.05},
+		{"creaky", creaky, 0.05, 1, 0, 0.05},
+		{"breathy", breathy, 0, 1, 0.2, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, quality, err := pitchDetector(t).DetectQuality(slices.Clone(test.frame))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(result.Frequency-200) > 2 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want 200 Hz", result.Frequency)
+			}
+			if quality.SHR < test.minSHR || quality.SHR > test.maxSHR {
+				t.Errorf("incorrect SHR, got %.3f, want within [%g, %g]", quality.SHR, test.minSHR, test.maxSHR)
+			}
+			if quality.Aperiodicity < test.minAperiodicity || quality.Aperiodicity > test.maxAperiodicity {
+				t.Errorf(
+					"incorrect aperiodicity, got %.3f, want within [%g, %g]",
+					quality.Aperiodicity, test.minAperiodicity, test.maxAperiodicity,
+				)
+			}
+		})
+	}
+}
+
+func TestSilenceThreshold(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize
+	quiet := generateSineWave(440, sampleRate, frameSize)
+	for i := range quiet {
+		quiet[i] *= 1e-5 // About -103 dBFS.
+	}
+
+	tests := []struct {
+		name          string
+		thresholdDB   float64
+		frame         []float64
+		wantFrequency float64
+	}{
+		{"disabled", 0, quiet, 440},
+		{"below threshold", -90, quiet, 0},
+		{"above threshold", -90, generateSineWave(440, sampleRate, frameSize), 440},
+		{"digital silence", -90, make([]float64, frameSize), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pitchDetector, err := yinfft.NewWithOptions(yinfft.WithSilenceThreshold(test.thresholdDB))
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frequency, _, err := pitchDetector.DetectFromFrame(slices.Clone(test.frame))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(frequency-test.wantFrequency) > 2 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, test.wantFrequency)
+			}
+
+			frequencies, _, err := pitchDetector.DetectFromFrames([][]float64{
+				slices.Clone(test.frame), generateSineWave(440, sampleRate, frameSize),
+			})
+			if err != nil {
+				t.Fatalf("error detecting pitch of frames: %v", err)
+			}
+			if math.Abs(frequencies[0]-test.wantFrequency) > 2 || math.Abs(frequencies[1]-440) > 2 {
+				t.Errorf("incorrect frequencies, got %.2f Hz, want [%.2f 440] Hz", frequencies, test.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestResult_Level(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize
+	scaled := func(amplitude float64) []float64 {
+		frame := generateSineWave(440, sampleRate, frameSize)
+		for i := range frame {
+			frame[i] *= amplitude
+		}
+		return frame
+	}
+
+	tests := []struct {
+		name      string
+		frame     []float64
+		wantLevel float64
+	}{
+		{"full scale", scaled(1), -3.01},
+		{"quiet", scaled(0.01), -43.01},
+		{"silence", make([]float64, frameSize), math.Inf(-1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pitchDetector := pitchDetector(t)
+			result, err := pitchDetector.Detect(slices.Clone(test.frame))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			results, err := pitchDetector.DetectAll(test.frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch of signal: %v", err)
+			}
+
+			for _, level := range []float64{result.Level, results[0].Level} {
+				if math.IsInf(test.wantLevel, -1) && !math.IsInf(level, -1) ||
+					!math.IsInf(test.wantLevel, -1) && math.Abs(level-test.wantLevel) > 0.01 {
+					t.Errorf("incorrect level, got %.2f dBFS, want %.2f dBFS", level, test.wantLevel)
+				}
+			}
+		})
+	}
+
+	// With decimation, the level is that of the frame rather than of the decimated frame that is windowed.
+	params := yinfft.DefaultParams
+	params.Decimation, params.MaxFrequency = 4, 1000
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	frame := generateSineWave(8000, sampleRate, frameSize)
+	if result, err := pitchDetector.Detect(frame); err != nil || math.Abs(result.Level+3.01) > 0.01 {
+		t.Errorf("incorrect level of a decimated frame, got %.2f dBFS, want -3.01 dBFS, error %v", result.Level, err)
+	}
+}
+
+func TestDetectFeatures(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize
+	nyquist := sampleRate / 2
+	random := rand.New(rand.NewPCG(1, 2))
+	noise := make([]float64, frameSize)
+	for i := range noise {
+		noise[i] = 0.1 * random.NormFloat64()
+	}
+
+	tests := []struct {
+		name        string
+		frame       []float64
+		minCentroid float64
+		maxCentroid float64
+		minFlatness float64
+		maxFlatness float64
+		minRolloff  float64
+		maxRolloff  float64
+	}{
+		{"sine", generateSineWave(440, sampleRate, frameSize), 420, 460, 0, 0.01, 430, 450},
+		{"white noise", noise, 0.45 * nyquist, 0.55 * nyquist, 0.4, 0.7, 0.8 * nyquist, 0.9 * nyquist},
+		{"silence", make([]float64, frameSize), 0, 0, 0, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, features, err := pitchDetector(t).DetectFeatures(slices.Clone(test.frame))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if features.Centroid < test.minCentroid || features.Centroid > test.maxCentroid {
+				t.Errorf(
+					"incorrect centroid, got %.2f Hz, want within [%.2f, %.2f] Hz",
+					features.Centroid, test.minCentroid, test.maxCentroid,
+				)
+			}
+			if features.Flatness < test.minFlatness || features.Flatness > test.maxFlatness {
+				t.Errorf(
+					"incorrect flatness, got %.3f, want within [%g, %g]", features.Flatness, test.minFlatness,
+					test.maxFlatness,
+				)
+			}
+			if features.Rolloff < test.minRolloff || features.Rolloff > test.maxRolloff {
+				t.Errorf(
+					"incorrect rolloff, got %.2f Hz, want within [%.2f, %.2f] Hz",
+					features.Rolloff, test.minRolloff, test.maxRolloff,
+				)
+			}
+		})
+	}
+}
+
+func TestChroma(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize
+	chord := func(frequencies ...float64) []float64 {
+		frame := make([]float64, frameSize)
+		for _, frequency := range frequencies {
+			for i, sample := range generateSineWave(frequency, sampleRate, frameSize) {
+				frame[i] += sample
+			}
+		}
+		return frame
+	}
+
+	tests := []struct {
+		name        string
+		referenceA4 float64
+		frame       []float64
+		wantClasses []int // Pitch classes expected to reach half the maximum.
+	}{
+		{"a", 0, chord(440), []int{9}},
+		{"c major", 0, chord(261.63, 329.63, 392), []int{0, 4, 7}},
+		{"baroque tuning", 415.3, chord(415.3), []int{9}},
+		{"silence", 0, make([]float64, frameSize), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pitchDetector, err := yinfft.NewWithOptions(yinfft.WithReferenceA4(test.referenceA4))
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+			spectrum, err := pitchDetector.PrepareSpectrum(test.frame)
+			if err != nil {
+				t.Fatalf("error preparing spectrum: %v", err)
+			}
+
+			chroma := pitchDetector.Chroma(spectrum)
+			var gotClasses []int
+			for pitchClass, value := range chroma {
+				if value >= 0.5 {
+					gotClasses = append(gotClasses, pitchClass)
+				}
+			}
+			if !slices.Equal(gotClasses, test.wantClasses) {
+				t.Errorf("incorrect pitch classes, got %v, want %v (chroma %.2f)", gotClasses, test.wantClasses, chroma)
+			}
+		})
+	}
+}
+
+func TestDetectMultiple(t *testing.T) {
+	t.Parallel()
+
+	sampleRate, frameSize := yinfft.DefaultParams.SampleRate, yinfft.DefaultParams.FrameSize
+	// Harmonic tones with amplitudes falling as 1/h, like plucked strings.
+	tones := func(fundamentals ...float64) []float64 {
+		frame := make([]float64, frameSize)
+		for _, f0 := range fundamentals {
+			for harmonic := 1; harmonic <= 8; harmonic++ {
+				for i, sample := range generateSineWave(f0*float64(harmonic), sampleRate, frameSize) {
+					frame[i] += sample / float64(harmonic)
+				}
+			}
+		}
+		return frame
+	}
+
+	tests := []struct {
+		name            string
+		frame           []float64
+		n               int
+		wantFrequencies []float64
+	}{
+		{"single tone", tones(220), 3, []float64{220}},
+		{"power chord", tones(82.41, 123.47), 3, []float64{82.41, 123.47}},
+		{"major third", tones(220, 277.18), 2, []float64{220, 277.18}},
+		{"limited to one", tones(220, 277.18), 1, []float64{277.18}},
+		{"silence", make([]float64, frameSize), 2, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			results, err := pitchDetector(t).DetectMultiple(slices.Clone(test.frame), test.n)
+			if err != nil {
+				t.Fatalf("error detecting pitches: %v", err)
+			}
+			frequencies, totalConfidence := make([]float64, len(results)), 0.0
+			for i, result := range results {
+				frequencies[i], totalConfidence = result.Frequency, totalConfidence+result.Confidence
+			}
+			slices.Sort(frequencies)
+
+			if len(frequencies) != len(test.wantFrequencies) {
+				t.Fatalf("incorrect frequencies, got %.2f Hz, want %.2f Hz", frequencies, test.wantFrequencies)
+			}
+			for i, frequency := range frequencies {
+				if cents := 1200 * math.Log2(frequency/test.wantFrequencies[i]); math.Abs(cents) > 5 {
+					t.Errorf("incorrect frequencies, got %.2f Hz, want %.2f Hz", frequencies, test.wantFrequencies)
+				}
+			}
+			if totalConfidence > 1 {
+				t.Errorf("incorrect confidences, got a total of %.3f, want at most 1", totalConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectProbabilistic(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+
+	tests := []struct {
+		name           string
+		frame          []float64
+		wantFrequency  float64
+		minProbability float64
+	}{
+		{"pure tone", generateSineWave(330, params.SampleRate, params.FrameSize), 330, 0.9},
+		{"silence", make([]float64, params.FrameSize), 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			candidates, err := pitchDetector.DetectProbabilistic(test.frame)
+			if err != nil {
+				t.Fatalf("error detecting candidates: %v", err)
+			}
+			if test.wantFrequency == 0 {
+				if len(candidates) != 0 {
+					t.Errorf("expected no candidates, got %v", candidates)
+				}
+				return
+			}
+
+			total := 0.0
+			for _, candidate := range candidates {
+				total += candidate.Probability
+			}
+			if total > 1+1e-9 {
+				t.Errorf("probabilities sum to %.4f, want at most 1", total)
+			}
+			if math.Abs(candidates[0].Frequency-test.wantFrequency) > 1 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", candidates[0].Frequency, test.wantFrequency)
+			}
+			if candidates[0].Probability < test.minProbability {
+				t.Errorf("incorrect probability, got %.2f, want >= %.2f", candidates[0].Probability, test.minProbability)
+			}
+		})
+	}
+}
+
+func TestPitchSmoother(t *testing.T) {
+	t.Parallel()
+
+	// A sustained 220 Hz note with a single-frame octave jump and an unvoiced frame.
+	steps := []yinffttest.Step{
+		{Frequency: 220, Confidence: 1}, {Frequency: 221, Confidence: 1}, {Frequency: 440, Confidence: 1},
+		{Frequency: 0}, {Frequency: 219, Confidence: 1}, {Frequency: 220, Confidence: 1},
+	}
+
+	tests := []struct {
+		name      string
+		params    yinfft.SmootherParams
+		maxJitter float64
+	}{
+		{"median", yinfft.SmootherParams{Mode: yinfft.SmoothingMedian, Window: 3}, 1.5},
+		{"mean", yinfft.SmootherParams{Mode: yinfft.SmoothingMean, Window: 5}, 60},
+		{"ema", yinfft.SmootherParams{Mode: yinfft.SmoothingEMA, Alpha: 0.3}, 60},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			smoother, err := yinfft.NewPitchSmoother(yinffttest.NewFakeDetector(steps...), test.params)
+			if err != nil {
+				t.Fatalf("error creating pitch smoother: %v", err)
+			}
+			for i, step := range steps {
+				frequency, _, err := smoother.DetectFromFrame(nil)
+				if err != nil {
+					t.Fatalf("error detecting pitch: %v", err)
+				}
+				if step.Frequency == 0 {
+					if frequency != 0 {
+						t.Errorf("frame %d: expected unvoiced frame, got %.2f Hz", i, frequency)
+					}
+					continue
+				}
+				if math.Abs(frequency-220) > test.maxJitter {
+					t.Errorf("frame %d: incorrect frequency, got %.2f Hz, want %.2f +- %.2f Hz", i, frequency, 220.0,
+						test.maxJitter)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsembleDetector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		votes         []float64
+		wantFrequency float64
+	}{
+		{"unanimous", []float64{440, 441, 439}, 440},
+		{"majority outvotes an octave error", []float64{440, 220, 441}, 440.5},
+		{"no majority", []float64{440, 220, 0}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var algorithms []yinfft.PitchAlgorithm
+			for _, frequency := range test.votes {
+				algorithms = append(algorithms, yinffttest.NewFakeDetector(yinffttest.Step{Frequency: frequency, Confidence: 0.9}))
+			}
+			ensemble, err := yinfft.NewEnsembleDetector(yinfft.EnsembleParams{}, algorithms...)
+			if err != nil {
+				t.Fatalf("error creating ensemble detector: %v", err)
+			}
+
+			result, err := ensemble.Detect(make([]float64, 16))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(result.Frequency-test.wantFrequency) > 0.5 || result.Voiced != (test.wantFrequency > 0) {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, test.wantFrequency)
+			}
+		})
+	}
+}
+
+func TestEnsembleDetector_Abstention(t *testing.T) {
+	t.Parallel()
+
+	// The agreeing algorithms vote in both ensembles.
+	agreeing := []yinfft.PitchAlgorithm{
+		yinffttest.NewLoopingFakeDetector(yinffttest.Step{Frequency: 440, Confidence: 0.9}),
+		yinffttest.NewLoopingFakeDetector(yinffttest.Step{Frequency: 441, Confidence: 0.9}),
+	}
+
+	abstaining := yinffttest.NewFakeDetector(yinffttest.Step{Err: yinfft.ErrNoPitch})
+	ensemble, err := yinfft.NewEnsembleDetector(yinfft.EnsembleParams{}, append(agreeing, abstaining)...)
+	if err != nil {
+		t.Fatalf("error creating ensemble detector: %v", err)
+	}
+	result, err := ensemble.Detect(make([]float64, 16))
+	if err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+	if !result.Voiced || math.Abs(result.Frequency-440.5) > 0.5 {
+		t.Errorf("incorrect consensus with an abstention, got %+v, want 440.50 Hz", result)
+	}
+
+	errFailed := errors.New("algorithm failed")
+	failing := yinffttest.NewFakeDetector(yinffttest.Step{Err: errFailed})
+	ensemble, err = yinfft.NewEnsembleDetector(yinfft.EnsembleParams{}, append(agreeing, failing)...)
+	if err != nil {
+		t.Fatalf("error creating ensemble detector: %v", err)
+	}
+	if _, err := ensemble.Detect(make([]float64, 16)); !errors.Is(err, errFailed) {
+		t.Errorf("incorrect error of a failing algorithm, got %v, want %v", err, errFailed)
+	}
+}
+
+func TestDetectFromFrame_OnNoPitch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mode    yinfft.NoPitchMode
+		wantNaN bool
+		wantErr error
+	}{
+		{"zero", yinfft.NoPitchZero, false, nil},
+		{"NaN", yinfft.NoPitchNaN, true, nil},
+		{"error", yinfft.NoPitchError, false, yinfft.ErrNoPitch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.OnNoPitch = test.mode
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frequency, confidence, err := pitchDetector.DetectFromFrame(make([]float64, params.FrameSize))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("incorrect error, got %v, want %v", err, test.wantErr)
+			}
+			if math.IsNaN(frequency) != test.wantNaN || (!test.wantNaN && frequency != 0) || confidence != 0 {
+				t.Errorf("incorrect result, got %.2f Hz with confidence %.2f", frequency, confidence)
+			}
+		})
+	}
+
+	params := yinfft.DefaultParams
+	params.OnNoPitch = "skip"
+	if _, err := yinfft.New(params); err == nil {
+		t.Errorf("expected error for invalid no pitch mode")
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector := pitchDetector(t)
+	params := pitchDetector.Params()
+	invalidWeighting := params
+	invalidWeighting.WeightingType = "Z"
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"frame size", func() error {
+			_, _, err := pitchDetector.DetectFromFrame(make([]float64, params.FrameSize-1))
+			return err
+		}, yinfft.ErrInvalidFrameSize},
+		{"frame size in batch", func() error {
+			_, _, err := pitchDetector.DetectFromFrames([][]float64{make([]float64, 3)})
+			return err
+		}, yinfft.ErrInvalidFrameSize},
+		{"spectrum size", func() error {
+			_, _, err := pitchDetector.DetectFromSpectrum(make([]float64, 3))
+			return err
+		}, yinfft.ErrInvalidSpectrumSize},
+		{"candidates spectrum size", func() error {
+			_, err := pitchDetector.DetectCandidatesFromSpectrum(make([]float64, 3), 1)
+			return err
+		}, yinfft.ErrInvalidSpectrumSize},
+		{"weighting type", func() error {
+			_, err := yinfft.New(invalidWeighting)
+			return err
+		}, yinfft.ErrInvalidWeightingType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := test.call(); !errors.Is(err, test.wantErr) {
+				t.Errorf("incorrect error, got %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParams_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		modify     func(*yinfft.Params)
+		wantErrors int
+	}{
+		{"default params", func(*yinfft.Params) {}, 0},
+		{"non power of two frame size", func(p *yinfft.Params) { p.FrameSize = 960 }, 0},
+		{"too small frame size", func(p *yinfft.Params) { p.FrameSize = 2 }, 1},
+		{"zero sample rate", func(p *yinfft.Params) { p.SampleRate = 0 }, 1},
+		{"inverted frequency range", func(p *yinfft.Params) { p.MinFrequency, p.MaxFrequency = 500, 100 }, 1},
+		{"zero tolerance", func(p *yinfft.Params) { p.Tolerance = 0 }, 1},
+		{"unknown hps check", func(p *yinfft.Params) { p.HPSCheck = "vote" }, 1},
+		{"positive silence threshold", func(p *yinfft.Params) { p.SilenceThresholdDB = 6 }, 1},
+		{"negative reference a4", func(p *yinfft.Params) { p.ReferenceA4 = -440 }, 1},
+		{"negative denormal threshold", func(p *yinfft.Params) { p.DenormalThreshold = -1e-30 }, 1},
+		{"zero value params", func(p *yinfft.Params) { *p = yinfft.Params{} }, 5},
+		{"several violations", func(p *yinfft.Params) {
+			p.WeightingType, p.SanitizeMode, p.HopSize, p.DenormalThreshold = "Z", "drop", -1, -1
+		}, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			test.modify(&params)
+			err := params.Validate()
+
+			gotErrors := 0
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				gotErrors = len(joined.Unwrap())
+			}
+			if gotErrors != test.wantErrors {
+				t.Errorf("incorrect number of violations, got %d, want %d: %v", gotErrors, test.wantErrors, err)
+			}
+			if _, newErr := yinfft.New(params); (newErr != nil) != (test.wantErrors > 0) {
+				t.Errorf("New and Validate disagree, got %v", newErr)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector, err := yinfft.NewWithOptions(
+		yinfft.WithFrameSize(2048),
+		yinfft.WithSampleRate(48000),
+		yinfft.WithWeighting("A"),
+		yinfft.WithFrequencyRange(60, 2000),
+	)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	params := pitchDetector.Params()
+	if params.FrameSize != 2048 || params.SampleRate != 48000 || params.WeightingType != "A" ||
+		params.MinFrequency != 60 || params.MaxFrequency != 2000 {
+		t.Errorf("options not applied, got %+v", params)
+	}
+	if params.Tolerance != yinfft.DefaultParams.Tolerance || !params.ShouldInterpolate {
+		t.Errorf("defaults not kept for unspecified options, got %+v", params)
+	}
+
+	frequency, _, err := pitchDetector.DetectFromFrame(generateSineWave(440, 48000, 2048))
+	if err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+	if math.Abs(frequency-440) > 1 {
+		t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 440.0)
+	}
+
+	if _, err := yinfft.NewWithOptions(yinfft.WithFrameSize(0)); err == nil {
+		t.Errorf("expected error for invalid frame size")
+	}
+}
+
+func TestLoadParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    func(yinfft.Params) bool
+		wantErr bool
+	}{
+		{
+			"partial config keeps defaults",
+			`{"frameSize": 2048, "weightingType": "A", "minFrequency": 60, "maxFrequency": 2000}`,
+			func(p yinfft.Params) bool {
+				return p.FrameSize == 2048 && p.WeightingType == "A" && p.MinFrequency == 60 &&
+					p.SampleRate == yinfft.DefaultParams.SampleRate
+			},
+			false,
+		},
+		{"unknown field", `{"frameSise": 2048}`, nil, true},
+		{"invalid frequency range", `{"minFrequency": 500, "maxFrequency": 100}`, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := yinfft.LoadParams(strings.NewReader(test.input))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("incorrect error, got %v, want error %t", err, test.wantErr)
+			}
+			if test.want != nil && !test.want(params) {
+				t.Errorf("incorrect params, got %+v", params)
+			}
+		})
+	}
+}
+
+func TestParams_YAML(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.WeightingType = yinfft.WeightingA
+	params.OnNoPitch = yinfft.NoPitchNaN
+
+	marshaled, err := params.MarshalYAML()
+	if err != nil {
+		t.Fatalf("error marshaling params: %v", err)
+	}
+	if fields := marshaled.(map[string]any); fields["weightingType"] != "A" || fields["frameSize"] != 8192.0 {
+		t.Errorf("incorrect marshaled fields, got %v", fields)
+	}
+
+	// The module doesn't depend on a YAML library, so none is exercised here. The documents are given as a YAML
+	// library decodes them into a map, with integers as int, and passed to UnmarshalYAML through its callback.
+	tests := []struct {
+		name     string
+		document map[string]any
+		want     yinfft.Params
+		wantErr  bool
+	}{
+		{
+			name:     "round trip",
+			document: marshaled.(map[string]any),
+			want:     params,
+		},
+		{
+			name: "partial document",
+			document: map[string]any{
+				"frameSize": 4096, "weightingType": "c", "minFrequency": 50, "maxFrequency": 1000.5,
+			},
+			want: func() yinfft.Params {
+				params := yinfft.DefaultParams
+				params.FrameSize, params.WeightingType = 4096, "c"
+				params.MinFrequency, params.MaxFrequency = 50, 1000.5
+				return params
+			}(),
+		},
+		{
+			name:     "unknown key",
+			document: map[string]any{"frameSize": 4096, "minFrequncy": 50},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid params",
+			document: map[string]any{"minFrequency": 500, "maxFrequency": 100},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown weighting type",
+			document: map[string]any{"weightingType": "Z"},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoded := yinfft.DefaultParams
+			err := decoded.UnmarshalYAML(func(v any) error {
+				*v.(*map[string]any) = test.document
+				return nil
+			})
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if decoded.FrameSize != yinfft.DefaultParams.FrameSize {
+					t.Errorf("rejected document changed the params, got %+v", decoded)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error unmarshaling params: %v", err)
+			}
+			if decoded.WeightingType != test.want.WeightingType || decoded.OnNoPitch != test.want.OnNoPitch ||
+				decoded.FrameSize != test.want.FrameSize || decoded.MinFrequency != test.want.MinFrequency ||
+				decoded.MaxFrequency != test.want.MaxFrequency {
+				t.Errorf("incorrect params, got %+v, want %+v", decoded, test.want)
+			}
+		})
+	}
+}
+
+func TestSetFrequencyRange(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.MinFrequency, params.MaxFrequency = 300, 2000
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	detect := func() float64 {
+		frequency, _, err := pitchDetector.DetectFromFrame(generateSineWave(110, params.SampleRate, params.FrameSize))
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		return frequency
+	}
+
+	if frequency := detect(); math.Abs(frequency-110) < 1 {
+		t.Errorf("detected %.2f Hz outside of the frequency range", frequency)
+	}
+	if err := pitchDetector.SetFrequencyRange(40, 400); err != nil {
+		t.Fatalf("error setting frequency range: %v", err)
+	}
+	if frequency := detect(); math.Abs(frequency-110) > 1 {
+		t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 110.0)
+	}
+	if got := pitchDetector.Params(); got.MinFrequency != 40 || got.MaxFrequency != 400 {
+		t.Errorf("incorrect frequency range, got [%.2f, %.2f] Hz", got.MinFrequency, got.MaxFrequency)
+	}
+
+	if err := pitchDetector.SetFrequencyRange(400, 40); err == nil {
+		t.Errorf("expected error for inverted frequency range")
+	}
+	if got := pitchDetector.Params(); got.MinFrequency != 40 || got.MaxFrequency != 400 {
+		t.Errorf("frequency range changed by a failed update, got [%.2f, %.2f] Hz", got.MinFrequency, got.MaxFrequency)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		preset      yinfft.Params
+		recordings  map[string]float64
+		harmonics   int
+		frequencies []float64
+	}{
+		{
+			"guitar",
+			yinfft.PresetGuitar,
+			map[string]float64{
+				"testdata/Alesis-Fusion-Clean-Guitar-C3.wav": 130.81,
+				"testdata/Yamaha-TG500-GT-Nylon-E2.wav":      82.41,
+			},
+			6,
+			[]float64{73.42, 329.63, 1318.51},
+		},
+		{"bass guitar", yinfft.PresetBassGuitar, nil, 6, []float64{30.87, 41.2, 98}},
+		{"violin", yinfft.PresetViolin, nil, 6, []float64{196, 659.26, 2637.02}},
+		{"voice", yinfft.PresetVoice, nil, 6, []float64{87.31, 220, 1046.5}},
+		{"whistle", yinfft.PresetWhistle, nil, 1, []float64{880, 1760, 3520}},
+		{"low latency", yinfft.DefaultParamsLowLatency, nil, 6, []float64{110, 440, 1760}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pitchDetector, err := yinfft.New(test.preset)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			for filename, wantFrequency := range test.recordings {
+				frames, err := framesFromWAV(filename, pitchDetector.Params().FrameSize)
+				if err != nil {
+					t.Fatalf("error reading .wav file %s: %v", filename, err)
+				}
+				found := false
+				for frame := range frames {
+					frequency, confidence, err := pitchDetector.DetectFromFrame(frame)
+					if err != nil {
+						t.Fatalf("error detecting pitch for a frame: %v", err)
+					}
+					found = found || math.Abs(frequency-wantFrequency) < 1 && confidence >= 0.85
+				}
+				if !found {
+					t.Errorf("no confident detection of %.2f Hz in %s", wantFrequency, filename)
+				}
+			}
+
+			// Tones spanning the range of the instrument, with harmonic amplitudes falling as 1/n.
+			for _, wantFrequency := range test.frequencies {
+				frame := make([]float64, test.preset.FrameSize)
+				for harmonic := 1; harmonic <= test.harmonics; harmonic++ {
+					wave := generateSineWave(float64(harmonic)*wantFrequency, test.preset.SampleRate, len(frame))
+					for i := range frame {
+						frame[i] += wave[i] / float64(harmonic)
+					}
+				}
+				frequency, _, err := pitchDetector.DetectFromFrame(frame)
+				if err != nil {
+					t.Fatalf("error detecting pitch for a frame: %v", err)
+				}
+				if math.Abs(frequency-wantFrequency) > wantFrequency*0.005 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, wantFrequency)
+				}
+			}
+		})
+	}
+}
+
+func TestRecommendFrameSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		sampleRate   float64
+		minFrequency float64
+		maxLatency   time.Duration
+		want         int
+		wantErr      bool
+	}{
+		{"guitar without latency limit", 44100, 82.41, time.Second, 4096, false},
+		{"guitar within 50 ms", 44100, 82.41, 50 * time.Millisecond, 2048, false},
+		{"bass within 50 ms", 48000, 41.2, 50 * time.Millisecond, 0, true},
+		{"violin within 20 ms", 48000, 196, 20 * time.Millisecond, 512, false},
+		{"invalid sample rate", 0, 100, time.Second, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			frameSize, err := yinfft.RecommendFrameSize(test.sampleRate, test.minFrequency, test.maxLatency)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("incorrect error, got %v, want error %t", err, test.wantErr)
+			}
+			if frameSize != test.want {
+				t.Errorf("incorrect frame size, got %d, want %d", frameSize, test.want)
+			}
+		})
+	}
+
+}
+
+func TestZeroPadFactor(t *testing.T) {
+	t.Parallel()
+
+	for _, factor := range []int{0, 1, 2, 4} {
+		t.Run(strconv.Itoa(factor), func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize, params.MinFrequency, params.ZeroPadFactor = 1024, 100, factor
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			wantBins := 1024*max(1, factor)/2 + 1
+			spectrum, err := pitchDetector.PrepareSpectrum(generateSineWave(440, params.SampleRate, params.FrameSize))
+			if err != nil {
+				t.Fatalf("error preparing spectrum: %v", err)
+			}
+			if len(spectrum) != wantBins {
+				t.Errorf("incorrect spectrum size, got %d, want %d", len(spectrum), wantBins)
+			}
+
+			frequency, _, err := pitchDetector.DetectFromSpectrum(spectrum)
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(frequency-440) > 4 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 440.0)
+			}
+
+			results, err := pitchDetector.DetectAll(generateSineWave(440, params.SampleRate, 3*params.FrameSize))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			for _, result := range results {
+				if math.Abs(result.Frequency-440) > 4 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, 440.0)
+				}
+			}
+		})
+	}
+
+	params := yinfft.DefaultParams
+	params.ZeroPadFactor = 3
+	if _, err := yinfft.New(params); err == nil {
+		t.Error("expected an error for a zero-pad factor of 3")
+	}
+
+	params.ZeroPadFactor = 2
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	if _, err := pitchDetector.NewOverlapAnalyzer(256); err == nil {
+		t.Error("expected an error creating an overlap analyzer with zero-padding")
+	}
+}
+
+func TestNonPowerOfTwoFrameSize(t *testing.T) {
+	t.Parallel()
+
+	for _, frameSize := range []int{441, 480, 960, 1000} {
+		t.Run(strconv.Itoa(frameSize), func(t *testing.T) {
+			t.Parallel()
+
+			params := yinfft.DefaultParams
+			params.FrameSize, params.SampleRate, params.MinFrequency = frameSize, 48000, 200
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			frequency, confidence, err := pitchDetector.DetectFromFrame(generateSineWave(880, 48000, frameSize))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			if math.Abs(frequency-880) > 10 || confidence < 0.9 {
+				t.Errorf("incorrect detection, got %.2f Hz with confidence %.2f, want %.2f Hz", frequency, confidence, 880.0)
+			}
+
+			results, err := pitchDetector.DetectAll(generateSineWave(880, 48000, 4*frameSize))
+			if err != nil {
+				t.Fatalf("error detecting pitch: %v", err)
+			}
+			for _, result := range results {
+				if math.Abs(result.Frequency-880) > 10 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", result.Frequency, 880.0)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiResolutionDetector(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.PresetBassGuitar
+	params.MaxFrequency = 2000
+	detector, err := yinfft.NewMultiResolutionDetector(yinfft.MultiResolutionParams{Params: params})
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		frequency     float64
+		silentSamples int
+	}{
+		{"low E string", 41.2, 0},
+		{"high note", 880, 0},
+		{"high note onset", 880, params.FrameSize - params.FrameSize/4},
+	}
+
+	for _, test := range tests {
+		frame := make([]float64, params.FrameSize)
+		for harmonic := 1; harmonic <= 6; harmonic++ {
+			wave := generateSineWave(float64(harmonic)*test.frequency, params.SampleRate, params.FrameSize)
+			for i := test.silentSamples; i < len(frame); i++ {
+				frame[i] += wave[i] / float64(harmonic)
+			}
+		}
+
+		result, err := detector.Detect(frame)
+		if err != nil {
+			t.Fatalf("%s: error detecting pitch: %v", test.name, err)
+		}
+		if !result.Voiced || math.Abs(1200*math.Log2(result.Frequency/test.frequency)) > 10 {
+			t.Errorf("%s: incorrect frequency, got %.2f Hz, want %.2f Hz", test.name, result.Frequency, test.frequency)
+		}
+	}
+
+	for _, invalid := range []yinfft.MultiResolutionParams{
+		{Params: params, ShortFrameSize: params.FrameSize},
+		{Params: params, Crossover: params.MaxFrequency},
+		{Params: params, ShortFrameSize: 64, Crossover: 100},
+	} {
+		if _, err := yinfft.NewMultiResolutionDetector(invalid); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
 	}
-	return signal
 }
 
-func framesFromWAV(filename string, chunkLen int) (iter.Seq[[]float64], error) {
-	file, err := os.Open(filename)
+func TestDecimation(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.PresetBassGuitar
+	params.FrameSize, params.Decimation, params.MaxFrequency = 8192, 4, 1000
+	pitchDetector, err := yinfft.New(params)
 	if err != nil {
-		return nil, err
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	if fftSize := pitchDetector.Params().FFTSize(); fftSize != 2048 {
+		t.Errorf("incorrect FFT size, got %d, want %d", fftSize, 2048)
 	}
-	defer file.Close()
 
-	decoder := wav.NewDecoder(file)
-	if !decoder.IsValidFile() {
-		return nil, decoder.Err()
+	for _, wantFrequency := range []float64{30.87, 41.2, 55, 98, 392} {
+		frame := make([]float64, params.FrameSize)
+		for harmonic := 1; harmonic <= 6; harmonic++ {
+			wave := generateSineWave(float64(harmonic)*wantFrequency, params.SampleRate, params.FrameSize)
+			for i := range frame {
+				frame[i] += wave[i] / float64(harmonic)
+			}
+		}
+
+		candidates, err := pitchDetector.DetectCandidates(slices.Clone(frame), 1)
+		if err != nil {
+			t.Fatalf("error detecting candidates: %v", err)
+		}
+		frequency, _, err := pitchDetector.DetectFromFrame(frame)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		if cents := math.Abs(1200 * math.Log2(frequency/wantFrequency)); cents > 5 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, wantFrequency)
+		}
+		if len(candidates) == 0 || math.Abs(candidates[0].Tau-params.SampleRate/wantFrequency) > 4 {
+			t.Errorf("incorrect candidates for %.2f Hz, got %+v", wantFrequency, candidates)
+		}
 	}
 
-	buffer, err := decoder.FullPCMBuffer()
+	params.MaxFrequency = 5000
+	if _, err := yinfft.New(params); err == nil {
+		t.Error("expected an error for a maximum frequency above the decimation cutoff")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		option yinfft.Option
+	}{
+		{"default", yinfft.WithWindow("")},
+		{"hann", yinfft.WithWindow(yinfft.WindowHann)},
+		{"hamming", yinfft.WithWindow(yinfft.WindowHamming)},
+		{"blackman", yinfft.WithWindow(yinfft.WindowBlackman)},
+		{"blackman-harris", yinfft.WithWindow(yinfft.WindowBlackmanHarris)},
+		{"kaiser", yinfft.WithWindow(yinfft.WindowKaiser)},
+		{"flat-top", yinfft.WithWindow(yinfft.WindowFlatTop)},
+		{"custom", yinfft.WithWindowFunc(func(i, n int) float64 {
+			return math.Sin(math.Pi * float64(i) / float64(n-1))
+		})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pitchDetector, err := yinfft.NewWithOptions(yinfft.WithFrameSize(4096), test.option)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			for _, wantFrequency := range []float64{110, 440, 1760} {
+				frame := make([]float64, 4096)
+				for harmonic := 1; harmonic <= 6; harmonic++ {
+					wave := generateSineWave(float64(harmonic)*wantFrequency, 44100, len(frame))
+					for i := range frame {
+						frame[i] += wave[i] / float64(harmonic)
+					}
+				}
+
+				frequency, _, err := pitchDetector.DetectFromFrame(frame)
+				if err != nil {
+					t.Fatalf("error detecting pitch: %v", err)
+				}
+				if cents := math.Abs(1200 * math.Log2(frequency/wantFrequency)); cents > 10 {
+					t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, wantFrequency)
+				}
+			}
+		})
+	}
+
+	for _, option := range []yinfft.Option{yinfft.WithWindow("triangle"), func(p *yinfft.Params) {
+		p.Window, p.KaiserBeta = yinfft.WindowKaiser, -1
+	}} {
+		if _, err := yinfft.NewWithOptions(option); err == nil {
+			t.Error("expected an error for an invalid window")
+		}
+	}
+}
+
+func TestWindow_Coefficients(t *testing.T) {
+	t.Parallel()
+
+	window := func(i, n int) float64 { return float64(i+1) / float64(n) }
+	pitchDetector, err := yinfft.NewWithOptions(yinfft.WithFrameSize(1024), yinfft.WithWindowFunc(window))
 	if err != nil {
-		return nil, err
+		t.Fatalf("error creating pitch detector: %v", err)
 	}
 
-	return func(yield func([]float64) bool) {
-		for chunk := range slices.Chunk(buffer.AsFloatBuffer().Data, chunkLen) {
-			if len(chunk) < chunkLen {
-				continue
+	for range 2 {
+		frame := make([]float64, 1024)
+		for i := range frame {
+			frame[i] = 2
+		}
+		analysis, err := pitchDetector.Analyze(frame)
+		if err != nil {
+			t.Fatalf("error analyzing frame: %v", err)
+		}
+		for i, sample := range analysis.WindowedFrame {
+			if want := 2 * window(i, len(frame)); sample != want {
+				t.Fatalf("incorrect windowed sample %d, got %g, want %g", i, sample, want)
 			}
-			if !yield(chunk) {
-				return
+		}
+	}
+}
+
+func TestDetectFromFrameInto(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize, params.Decimation, params.MaxFrequency = 4096, 2, 4000
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	frequencies := []float64{110, 220, 440, 880}
+	errs := make(chan error, len(frequencies))
+	for _, wantFrequency := range frequencies {
+		go func() {
+			scratch := pitchDetector.NewScratch()
+			for range 10 {
+				frame := generateSineWave(wantFrequency, params.SampleRate, params.FrameSize)
+				want, _, err := pitchDetector.DetectFromFrame(slices.Clone(frame))
+				if err != nil {
+					errs <- err
+					return
+				}
+				frequency, _, err := pitchDetector.DetectFromFrameInto(frame, scratch)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if frequency != want || math.Abs(frequency-wantFrequency) > 0.02*wantFrequency {
+					errs <- fmt.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, want)
+					return
+				}
 			}
+			errs <- nil
+		}()
+	}
+	for range frequencies {
+		if err := <-errs; err != nil {
+			t.Error(err)
 		}
-	}, nil
+	}
+
+	otherDetector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	frame := make([]float64, params.FrameSize)
+	if _, _, err := pitchDetector.DetectFromFrameInto(frame, otherDetector.NewScratch()); err == nil {
+		t.Error("expected an error for a scratch of another detector")
+	}
 }
 
-func pitchDetector(t *testing.T) *yinfft.PitchDetector {
-	t.Helper()
+func TestPitchDetector_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector, err := yinfft.New(yinfft.PresetGuitar)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	clone := pitchDetector.Clone()
+	if err := clone.SetFrequencyRange(300, 1400); err != nil {
+		t.Fatalf("error setting frequency range: %v", err)
+	}
+	if params := pitchDetector.Params(); params.MinFrequency != yinfft.PresetGuitar.MinFrequency {
+		t.Errorf("incorrect minimum frequency of the original, got %.2f Hz, want %.2f Hz", params.MinFrequency, 70.0)
+	}
+
+	frequencies := []float64{82.41, 110, 146.83, 196, 246.94, 329.63}
+	want := make([]float64, len(frequencies))
+	for i, frequency := range frequencies {
+		if want[i], _, err = pitchDetector.DetectFromFrame(generateSineWave(frequency, 44100, 4096)); err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+	}
+
+	errs := make(chan error, 8)
+	for worker := range cap(errs) {
+		go func() {
+			for i := range 20 {
+				j := (worker + i) % len(frequencies)
+				frame := generateSineWave(frequencies[j], 44100, 4096)
+				candidates, err := pitchDetector.DetectCandidates(slices.Clone(frame), 1)
+				if err != nil {
+					errs <- err
+					return
+				}
+				frequency, _, err := pitchDetector.DetectFromFrame(frame)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if frequency != want[j] || len(candidates) == 0 {
+					errs <- fmt.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, want[j])
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range cap(errs) {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestDetectorPool(t *testing.T) {
+	t.Parallel()
+
+	var pool yinfft.DetectorPool
+	presets := []yinfft.Params{yinfft.PresetGuitar, yinfft.PresetViolin, yinfft.PresetVoice}
+
+	errs := make(chan error, 8)
+	for worker := range cap(errs) {
+		go func() {
+			for i := range 20 {
+				params := presets[(worker+i)%len(presets)]
+				pitchDetector, err := pool.Get(params)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if got := pitchDetector.Params(); got.FrameSize != params.FrameSize || got.MinFrequency != params.MinFrequency {
+					errs <- fmt.Errorf("incorrect params, got %+v, want %+v", got, params)
+					return
+				}
+				frequency, _, err := pitchDetector.DetectFromFrame(generateSineWave(440, params.SampleRate, params.FrameSize))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if math.Abs(frequency-440) > 1 {
+					errs <- fmt.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, 440.0)
+					return
+				}
+				pool.Put(pitchDetector)
+			}
+			errs <- nil
+		}()
+	}
+	for range cap(errs) {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	params := yinfft.PresetGuitar
+	params.WeightFunc = func(float64) float64 { return 1 }
+	if _, err := pool.Get(params); err == nil {
+		t.Error("expected an error pooling params with a weight function")
+	}
+	if _, err := pool.Get(yinfft.Params{}); err == nil {
+		t.Error("expected an error for invalid params")
+	}
+}
+
+func TestDetectBatch(t *testing.T) {
+	t.Parallel()
 
 	params := yinfft.DefaultParams
-	params.Logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
+	params.FrameSize = 2048
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
 
+	frequencies := make([]float64, 50)
+	frames := make([][]float64, len(frequencies))
+	for i := range frames {
+		frequencies[i] = 440 * math.Exp2(float64(i%24)/12)
+		frames[i] = generateSineWave(frequencies[i], params.SampleRate, params.FrameSize)
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		batch := make([][]float64, len(frames))
+		for i, frame := range frames {
+			batch[i] = slices.Clone(frame)
+		}
+		results, err := pitchDetector.DetectBatch(batch, workers)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		if len(results) != len(frames) {
+			t.Fatalf("incorrect number of results, got %d, want %d", len(results), len(frames))
+		}
+		for i, result := range results {
+			if math.Abs(result.Frequency-frequencies[i]) > 0.01*frequencies[i] || result.Frame != i {
+				t.Errorf("incorrect result %d, got %+v, want %.2f Hz", i, result, frequencies[i])
+			}
+			if wantTime := float64(i*params.FrameSize) / params.SampleRate; result.Time != wantTime {
+				t.Errorf("incorrect time, got %.3f s, want %.3f s", result.Time, wantTime)
+			}
+		}
+	}
+
+	frames[7] = frames[7][:100]
+	if _, err := pitchDetector.DetectBatch(frames, 4); !errors.Is(err, yinfft.ErrInvalidFrameSize) {
+		t.Errorf("incorrect error, got %v, want %v", err, yinfft.ErrInvalidFrameSize)
+	}
+}
+
+func TestDetectFromFrame32(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	params.FrameSize, params.SanitizeMode = 4096, yinfft.SanitizeZero
 	pitchDetector, err := yinfft.New(params)
 	if err != nil {
 		t.Fatalf("error creating pitch detector: %v", err)
 	}
-	return pitchDetector
+
+	for _, wantFrequency := range []float64{110, 440, 1760} {
+		frame := generateSineWave(wantFrequency, params.SampleRate, params.FrameSize)
+		frame32 := make([]float32, len(frame))
+		for i, sample := range frame {
+			frame32[i] = float32(sample)
+		}
+		frame32[100] = float32(math.NaN())
+		frame[100] = math.NaN()
+
+		want, _, err := pitchDetector.DetectFromFrame(frame)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		frequency, _, err := pitchDetector.DetectFromFrame32(frame32)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		if math.Abs(frequency-want) > 0.01 {
+			t.Errorf("incorrect frequency, got %.2f Hz, want %.2f Hz", frequency, want)
+		}
+	}
+
+	if _, _, err := pitchDetector.DetectFromFrame32(make([]float32, 100)); !errors.Is(err, yinfft.ErrInvalidFrameSize) {
+		t.Errorf("incorrect error, got %v, want %v", err, yinfft.ErrInvalidFrameSize)
+	}
+}
+
+func TestDetectFromPCM16(t *testing.T) {
+	t.Parallel()
+
+	pitchDetector, err := yinfft.NewWithOptions(yinfft.WithFrameSize(4096))
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+
+	for _, wantFrequency := range []float64{110, 440, 1760} {
+		frame := generateSineWave(wantFrequency, 44100, 4096)
+		samples := make([]int16, len(frame))
+		for i, sample := range frame {
+			samples[i] = int16(math.Round(sample * 0.5 * 32767))
+		}
+
+		want, _, err := pitchDetector.DetectFromFrame(frame)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		frequency, confidence, err := pitchDetector.DetectFromPCM16(samples)
+		if err != nil {
+			t.Fatalf("error detecting pitch: %v", err)
+		}
+		if math.Abs(frequency-want) > 0.05 || confidence < 0.9 {
+			t.Errorf("incorrect frequency, got %.2f Hz with confidence %.2f, want %.2f Hz", frequency, confidence, want)
+		}
+	}
+
+	if _, _, err := pitchDetector.DetectFromPCM16(make([]int16, 100)); !errors.Is(err, yinfft.ErrInvalidFrameSize) {
+		t.Errorf("incorrect error, got %v, want %v", err, yinfft.ErrInvalidFrameSize)
+	}
+}
+
+func TestSpectrumWeights(t *testing.T) {
+	t.Parallel()
+
+	curveDetector, err := yinfft.NewWithDefaultParams()
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	curve := curveDetector.SpectrumWeights()
+	if want := yinfft.DefaultParams.FFTSize()/2 + 1; len(curve) != want {
+		t.Fatalf("incorrect number of weights, got %d, want %d", len(curve), want)
+	}
+	curve[1] = -1
+	if curveDetector.SpectrumWeights()[1] == -1 {
+		t.Error("modifying the returned weights changed the detector's weights")
+	}
+
+	// Bins 0-9 are notched out and bin 20 is boosted on top of the weighting curve.
+	params := yinfft.DefaultParams
+	params.BinWeights = make([]float64, params.FFTSize()/2+1)
+	for i := range params.BinWeights {
+		params.BinWeights[i] = 1
+		if i < 10 {
+			params.BinWeights[i] = 0
+		}
+	}
+	params.BinWeights[20] = 4
+	pitchDetector, err := yinfft.New(params)
+	if err != nil {
+		t.Fatalf("error creating pitch detector: %v", err)
+	}
+	curve = curveDetector.SpectrumWeights()
+	for i, weight := range pitchDetector.SpectrumWeights() {
+		if want := curve[i] * params.BinWeights[i]; weight != want {
+			t.Errorf("incorrect weight of bin %d, got %g, want %g", i, weight, want)
+		}
+	}
+
+	var buffer strings.Builder
+	if err := pitchDetector.WriteSpectrumWeightsCSV(&buffer); err != nil {
+		t.Fatalf("error writing weights: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(buffer.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading weights CSV: %v", err)
+	}
+	if len(records) != len(curve)+1 || !slices.Equal(records[0], []string{"bin", "frequency_hz", "weight", "weight_db"}) {
+		t.Fatalf("incorrect weights CSV header or length: %v, %d records", records[0], len(records))
+	}
+	binFrequency := params.SampleRate / float64(params.FFTSize())
+	for i, record := range records[1:] {
+		weight := curve[i] * params.BinWeights[i]
+		wantDB := ""
+		if weight > 0 {
+			wantDB = strconv.FormatFloat(20*math.Log10(weight), 'f', 4, 64)
+		}
+		want := []string{
+			strconv.Itoa(i),
+			strconv.FormatFloat(float64(i)*binFrequency, 'f', 4, 64),
+			strconv.FormatFloat(weight, 'g', -1, 64),
+			wantDB,
+		}
+		if !slices.Equal(record, want) {
+			t.Errorf("incorrect record of bin %d, got %v, want %v", i, record, want)
+		}
+	}
+	if records[5][3] != "" {
+		t.Errorf("incorrect dB value of a notched bin, got %q, want an empty field", records[5][3])
+	}
+}
+
+func TestDetectFromFrame_BandWeighting(t *testing.T) {
+	t.Parallel()
+
+	params := yinfft.DefaultParams
+	binFrequency := params.SampleRate / float64(params.FFTSize())
+	// bandWeights returns bin weights with the given gain in the band of A4 and unit gain elsewhere.
+	bandWeights := func(gain float64) []float64 {
+		weights := make([]float64, params.FFTSize()/2+1)
+		for i := range weights {
+			weights[i] = 1
+			if frequency := float64(i) * binFrequency; frequency >= 400 && frequency <= 480 {
+				weights[i] = gain
+			}
+		}
+		return weights
+	}
+	bandFunc := func(gain float64) yinfft.WeightFunc {
+		return func(frequency float64) float64 {
+			if frequency >= 400 && frequency <= 480 {
+				return gain
+			}
+			return 1
+		}
+	}
+
+	tests := []struct {
+		name       string
+		binWeights []float64
+		weightFunc yinfft.WeightFunc
+		wantA4     bool
+	}{
+		{"unweighted", nil, nil, false},
+		{"band boosted with bin weights", bandWeights(100), nil, true},
+		{"band boosted with weight function", nil, bandFunc(100), true},
+		{"band notched with bin weights", bandWeights(0), nil, false},
+		{"band notched with weight function", nil, bandFunc(0), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := params
+			params.BinWeights, params.WeightFunc = test.binWeights, test.weightFunc
+			pitchDetector, err := yinfft.New(params)
+			if err != nil {
+				t.Fatalf("error creating pitch detector: %v", err)
+			}
+
+			// A4 buried 10 dB below brown noise, whose low frequencies mislead the unweighted detection.
+			frame, err := signal.AddNoise(
+				signal.Sine(440, params.SampleRate, params.FrameSize), signal.NoiseBrown, -10, rand.New(rand.NewPCG(1, 2)),
+			)
+			if err != nil {
+				t.Fatalf("error generating frame: %v", err)
+			}
+			frequency, _, err := pitchDetector.DetectFromFrame(frame)
+			if err != nil {
+				t.Fatalf("error detecting pitch for a frame: %v", err)
+			}
+
+			if gotA4 := math.Abs(frequency-440) < 2; gotA4 != test.wantA4 {
+				t.Errorf("incorrect frequency, got %.2f Hz, want A4: %v", frequency, test.wantA4)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidWeights(t *testing.T) {
+	t.Parallel()
+
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+		params := yinfft.DefaultParams
+		params.BinWeights = make([]float64, params.FFTSize()/2+1)
+		params.BinWeights[10] = weight
+		if _, err := yinfft.New(params); err == nil {
+			t.Errorf("expected an error for a bin weight of %g", weight)
+		}
+
+		params = yinfft.DefaultParams
+		params.WeightFunc = func(frequency float64) float64 {
+			if frequency > 1000 {
+				return weight
+			}
+			return 1
+		}
+		if _, err := yinfft.New(params); err == nil {
+			t.Errorf("expected an error for a weight function returning %g", weight)
+		}
+	}
 }
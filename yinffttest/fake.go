@@ -0,0 +1,118 @@
+// Package yinffttest provides test doubles for applications embedding the yinfft package, so pitch-handling logic
+// can be unit-tested without audio files.
+package yinffttest
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/FreibergVlad/go-yinfft"
+)
+
+var (
+	_ yinfft.Detector       = (*FakeDetector)(nil)
+	_ yinfft.PitchAlgorithm = (*FakeDetector)(nil)
+)
+
+// ErrScriptExhausted is returned by FakeDetector once all scripted steps have been consumed and looping is disabled.
+var ErrScriptExhausted = errors.New("yinffttest: scripted steps exhausted")
+
+type (
+	// Step is a single scripted detection outcome.
+	Step struct {
+		Frequency  float64        // Frequency to return in Hz.
+		Confidence float64        // Confidence to return.
+		Result     *yinfft.Result // Result to return from Detect instead of Frequency and Confidence, if set.
+		Err        error          // Error to return instead of a result.
+		Latency    time.Duration  // Delay before the call returns, to simulate slow detection.
+	}
+	// FakeDetector implements yinfft.Detector and yinfft.PitchAlgorithm returning a predefined sequence of detection
+	// results, one per call, regardless of the input. It is safe for concurrent use.
+	FakeDetector struct {
+		mu     sync.Mutex
+		steps  []Step
+		next   int
+		loop   bool
+		inputs [][]float64
+	}
+)
+
+// NewFakeDetector creates a FakeDetector returning the given steps in order, then ErrScriptExhausted.
+func NewFakeDetector(steps ...Step) *FakeDetector {
+	return &FakeDetector{steps: steps}
+}
+
+// NewLoopingFakeDetector creates a FakeDetector returning the given steps in order, starting over once exhausted.
+func NewLoopingFakeDetector(steps ...Step) *FakeDetector {
+	return &FakeDetector{steps: steps, loop: true}
+}
+
+// DetectFromFrame records the frame and returns the frequency and confidence of the next scripted step.
+func (f *FakeDetector) DetectFromFrame(frame []float64) (frequency float64, confidence float64, err error) {
+	result, err := f.detect(frame)
+	return result.Frequency, result.Confidence, err
+}
+
+// DetectFromSpectrum records the spectrum and returns the frequency and confidence of the next scripted step.
+func (f *FakeDetector) DetectFromSpectrum(spectrum []float64) (frequency float64, confidence float64, err error) {
+	result, err := f.detect(spectrum)
+	return result.Frequency, result.Confidence, err
+}
+
+// Detect records the frame and returns the next scripted step as a result. Steps without a Result are voiced if their
+// frequency is positive, and have a zero Tau, as the period isn't known without a sample rate.
+func (f *FakeDetector) Detect(frame []float64) (yinfft.Result, error) {
+	return f.detect(frame)
+}
+
+// Calls returns the number of detection calls made so far.
+func (f *FakeDetector) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.inputs)
+}
+
+// Inputs returns copies of the frames or spectra passed to the detector, in call order.
+func (f *FakeDetector) Inputs() [][]float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	inputs := make([][]float64, len(f.inputs))
+	for i, input := range f.inputs {
+		inputs[i] = append([]float64(nil), input...)
+	}
+	return inputs
+}
+
+func (f *FakeDetector) detect(input []float64) (yinfft.Result, error) {
+	step, ok := f.nextStep(input)
+	if !ok {
+		return yinfft.Result{}, ErrScriptExhausted
+	}
+	if step.Latency > 0 {
+		time.Sleep(step.Latency)
+	}
+	if step.Err != nil {
+		return yinfft.Result{}, step.Err
+	}
+	if step.Result != nil {
+		return *step.Result, nil
+	}
+	return yinfft.Result{Frequency: step.Frequency, Confidence: step.Confidence, Voiced: step.Frequency > 0}, nil
+}
+
+func (f *FakeDetector) nextStep(input []float64) (Step, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.inputs = append(f.inputs, append([]float64(nil), input...))
+	if f.next >= len(f.steps) {
+		if !f.loop || len(f.steps) == 0 {
+			return Step{}, false
+		}
+		f.next = 0
+	}
+	step := f.steps[f.next]
+	f.next++
+	return step, true
+}
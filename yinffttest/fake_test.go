@@ -0,0 +1,170 @@
+package yinffttest_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FreibergVlad/go-yinfft"
+	"github.com/FreibergVlad/go-yinfft/yinffttest"
+)
+
+func TestFakeDetector(t *testing.T) {
+	t.Parallel()
+
+	errScripted := errors.New("scripted error")
+	steps := []yinffttest.Step{
+		{Frequency: 440, Confidence: 0.9},
+		{Err: errScripted},
+		{Result: &yinfft.Result{Frequency: 220, Confidence: 0.4, Tau: 200.45, Voiced: false}},
+	}
+
+	type call struct {
+		want    yinfft.Result
+		wantErr error
+	}
+	tests := []struct {
+		name     string
+		detector *yinffttest.FakeDetector
+		calls    []call
+	}{
+		{
+			name:     "steps in order then exhausted",
+			detector: yinffttest.NewFakeDetector(steps...),
+			calls: []call{
+				{want: yinfft.Result{Frequency: 440, Confidence: 0.9, Voiced: true}},
+				{wantErr: errScripted},
+				{want: *steps[2].Result},
+				{wantErr: yinffttest.ErrScriptExhausted},
+				{wantErr: yinffttest.ErrScriptExhausted},
+			},
+		},
+		{
+			name:     "looping",
+			detector: yinffttest.NewLoopingFakeDetector(steps...),
+			calls: []call{
+				{want: yinfft.Result{Frequency: 440, Confidence: 0.9, Voiced: true}},
+				{wantErr: errScripted},
+				{want: *steps[2].Result},
+				{want: yinfft.Result{Frequency: 440, Confidence: 0.9, Voiced: true}},
+				{wantErr: errScripted},
+			},
+		},
+		{
+			name:     "looping without steps",
+			detector: yinffttest.NewLoopingFakeDetector(),
+			calls:    []call{{wantErr: yinffttest.ErrScriptExhausted}},
+		},
+		{
+			name:     "unvoiced step",
+			detector: yinffttest.NewFakeDetector(yinffttest.Step{Confidence: 0.1}),
+			calls:    []call{{want: yinfft.Result{Confidence: 0.1}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, call := range test.calls {
+				result, err := test.detector.Detect([]float64{float64(i)})
+				if !errors.Is(err, call.wantErr) {
+					t.Fatalf("incorrect error of call %d, got %v, want %v", i, err, call.wantErr)
+				}
+				if result != call.want {
+					t.Errorf("incorrect result of call %d, got %+v, want %+v", i, result, call.want)
+				}
+			}
+			if calls := test.detector.Calls(); calls != len(test.calls) {
+				t.Errorf("incorrect number of calls, got %d, want %d", calls, len(test.calls))
+			}
+		})
+	}
+}
+
+func TestFakeDetector_DetectFromFrame(t *testing.T) {
+	t.Parallel()
+
+	detector := yinffttest.NewFakeDetector(
+		yinffttest.Step{Frequency: 440, Confidence: 0.9},
+		yinffttest.Step{Result: &yinfft.Result{Frequency: 220, Confidence: 0.5, Tau: 200.45, Voiced: true}},
+	)
+
+	frequency, confidence, err := detector.DetectFromFrame([]float64{1, 2})
+	if err != nil || frequency != 440 || confidence != 0.9 {
+		t.Errorf("incorrect first detection, got %v Hz, confidence %v, error %v", frequency, confidence, err)
+	}
+	frequency, confidence, err = detector.DetectFromSpectrum([]float64{3})
+	if err != nil || frequency != 220 || confidence != 0.5 {
+		t.Errorf("incorrect second detection, got %v Hz, confidence %v, error %v", frequency, confidence, err)
+	}
+	if _, _, err := detector.DetectFromFrame(nil); !errors.Is(err, yinffttest.ErrScriptExhausted) {
+		t.Errorf("incorrect error after the last step, got %v, want %v", err, yinffttest.ErrScriptExhausted)
+	}
+}
+
+func TestFakeDetector_Inputs(t *testing.T) {
+	t.Parallel()
+
+	detector := yinffttest.NewLoopingFakeDetector(yinffttest.Step{Frequency: 440})
+	frame := []float64{1, 2, 3}
+	if _, _, err := detector.DetectFromFrame(frame); err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+	// The recorded input is a copy, unaffected by the caller reusing its buffer.
+	frame[0] = 4
+	if _, err := detector.Detect(frame[:2]); err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+
+	inputs := detector.Inputs()
+	want := [][]float64{{1, 2, 3}, {4, 2}}
+	if !slices.EqualFunc(inputs, want, slices.Equal) {
+		t.Fatalf("incorrect inputs, got %v, want %v", inputs, want)
+	}
+	inputs[0][0] = 5
+	if detector.Inputs()[0][0] != 1 {
+		t.Error("modifying the returned inputs changed the recorded inputs")
+	}
+}
+
+func TestFakeDetector_Latency(t *testing.T) {
+	t.Parallel()
+
+	const latency = 20 * time.Millisecond
+	detector := yinffttest.NewFakeDetector(yinffttest.Step{Frequency: 440, Latency: latency})
+	start := time.Now()
+	if _, _, err := detector.DetectFromFrame(nil); err != nil {
+		t.Fatalf("error detecting pitch: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < latency {
+		t.Errorf("detection returned too early, after %v, want at least %v", elapsed, latency)
+	}
+}
+
+func TestFakeDetector_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const goroutines, calls = 8, 100
+	detector := yinffttest.NewLoopingFakeDetector(yinffttest.Step{Frequency: 440}, yinffttest.Step{Frequency: 220})
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range calls {
+				if frequency, _, err := detector.DetectFromFrame(nil); err != nil || frequency != 440 && frequency != 220 {
+					t.Errorf("incorrect detection, got %v Hz, error %v", frequency, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := detector.Calls(); got != goroutines*calls {
+		t.Errorf("incorrect number of calls, got %d, want %d", got, goroutines*calls)
+	}
+}